@@ -0,0 +1,284 @@
+package main
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// app runs commands with services bound to one organization
+type app struct {
+	teams   services.TeamService
+	leagues services.LeagueService
+	out     io.Writer
+	format  string
+}
+
+// command is a subcommand of leaguectl, args describes what it expects after its name
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(a *app, args []string) error
+}
+
+// usageError is returned by a command given arguments it cannot use
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string { return e.message }
+
+func usagef(format string, args ...any) error {
+	return usageError{message: fmt.Sprintf(format, args...)}
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"teams", "", "list the teams", listTeams},
+		{"create-team", "-name <name> [-attack n] [-defense n]", "create a team", createTeam},
+		{"leagues", "", "list the leagues", listLeagues},
+		{"create-league", "-name <name> [-result-mode simulated|manual]", "create a league", createLeague},
+		{"add-team", "<league> <team>", "add a team to a league that has not started", addTeam},
+		{"start", "<league>", "draw the fixtures and start a league", leagueAction((*app).startLeague)},
+		{"advance", "<league>", "play the next week of a league", leagueAction((*app).advanceWeek)},
+		{"play-all", "<league>", "play every week left in a league", leagueAction((*app).playAll)},
+		{"standings", "<league>", "print the table of a league", showStandings},
+		{"edit-match", "<match> <home-score> <away-score>", "correct the result of a played match", editMatch},
+		{"predict", "<league>", "predict the champion of a league", predict},
+	}
+}
+
+func findCommand(name string) (*command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return nil, false
+}
+
+// ids parses the IDs a command expects as its arguments
+func ids(args []string, names ...string) ([]uint, error) {
+	if len(args) != len(names) {
+		return nil, usagef("expected %d arguments, got %d", len(names), len(args))
+	}
+	parsed := make([]uint, len(args))
+	for i, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil || id == 0 {
+			return nil, usagef("%s must be a positive ID, got %q", names[i], arg)
+		}
+		parsed[i] = uint(id)
+	}
+	return parsed, nil
+}
+
+// parseFlags parses the flags of a command, which takes no other arguments
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return usageError{message: err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected argument %q", flags.Arg(0))
+	}
+	return nil
+}
+
+func listTeams(a *app, args []string) error {
+	if len(args) > 0 {
+		return usagef("unexpected argument %q", args[0])
+	}
+	teams, err := a.teams.GetAllTeams()
+	if err != nil {
+		return err
+	}
+	return a.printTeams(teams)
+}
+
+func createTeam(a *app, args []string) error {
+	flags := flag.NewFlagSet("create-team", flag.ContinueOnError)
+	name := flags.String("name", "", "")
+	attack := flags.Int("attack", 50, "")
+	defense := flags.Int("defense", 50, "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return usagef("-name is required")
+	}
+
+	team := &models.Team{Name: *name, AttackStrength: *attack, DefenseStrength: *defense}
+	if err := a.teams.CreateTeam(team); err != nil {
+		return err
+	}
+	return a.printTeams([]*models.Team{team})
+}
+
+func listLeagues(a *app, args []string) error {
+	if len(args) > 0 {
+		return usagef("unexpected argument %q", args[0])
+	}
+	leagues, err := a.leagues.GetAllLeagues()
+	if err != nil {
+		return err
+	}
+	return a.printLeagues(leagues)
+}
+
+func createLeague(a *app, args []string) error {
+	flags := flag.NewFlagSet("create-league", flag.ContinueOnError)
+	name := flags.String("name", "", "")
+	mode := flags.String("result-mode", models.ResultModeSimulated, "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return usagef("-name is required")
+	}
+
+	league := &models.League{Name: *name, ResultMode: *mode}
+	if err := a.leagues.CreateLeague(league); err != nil {
+		return err
+	}
+	return a.printLeague(league)
+}
+
+func addTeam(a *app, args []string) error {
+	parsed, err := ids(args, "league", "team")
+	if err != nil {
+		return err
+	}
+	if err := a.leagues.AddTeamToLeague(parsed[0], parsed[1]); err != nil {
+		return err
+	}
+	return a.showLeague(parsed[0])
+}
+
+// leagueAction makes a command of an action on the league given as its only argument, which prints the league after
+func leagueAction(action func(a *app, leagueID uint) error) func(a *app, args []string) error {
+	return func(a *app, args []string) error {
+		parsed, err := ids(args, "league")
+		if err != nil {
+			return err
+		}
+		if err := action(a, parsed[0]); err != nil {
+			return err
+		}
+		return a.showLeague(parsed[0])
+	}
+}
+
+func (a *app) startLeague(leagueID uint) error { return a.leagues.StartLeague(leagueID) }
+func (a *app) advanceWeek(leagueID uint) error { return a.leagues.AdvanceWeek(leagueID) }
+func (a *app) playAll(leagueID uint) error     { return a.leagues.PlayAllMatches(leagueID) }
+
+func (a *app) showLeague(leagueID uint) error {
+	league, err := a.leagues.GetLeagueByID(leagueID)
+	if err != nil {
+		return err
+	}
+	return a.printLeague(league)
+}
+
+// standingRow is a line of the table of a league, with the name of its team
+type standingRow struct {
+	Position int    `json:"position"`
+	TeamName string `json:"team_name"`
+	*models.Standing
+}
+
+func showStandings(a *app, args []string) error {
+	parsed, err := ids(args, "league")
+	if err != nil {
+		return err
+	}
+	league, err := a.leagues.GetLeagueByID(parsed[0])
+	if err != nil {
+		return err
+	}
+	standings, err := a.leagues.GetStandings(league.ID)
+	if err != nil {
+		return err
+	}
+
+	names := teamNames(league)
+	table := make([]standingRow, 0, len(standings))
+	rows := make([][]string, 0, len(standings))
+	for i, standing := range standings {
+		table = append(table, standingRow{Position: i + 1, TeamName: names[standing.TeamID], Standing: standing})
+		rows = append(rows, []string{
+			fmt.Sprint(i + 1), names[standing.TeamID], fmt.Sprint(standing.Played), fmt.Sprint(standing.Wins),
+			fmt.Sprint(standing.Draws), fmt.Sprint(standing.Losses), fmt.Sprintf("%+d", standing.GoalDifference),
+			fmt.Sprint(standing.Points),
+		})
+	}
+	return a.print(table, []string{"POS", "TEAM", "P", "W", "D", "L", "GD", "PTS"}, rows)
+}
+
+func editMatch(a *app, args []string) error {
+	if len(args) != 3 {
+		return usagef("expected 3 arguments, got %d", len(args))
+	}
+	parsed, err := ids(args[:1], "match")
+	if err != nil {
+		return err
+	}
+	goals := make([]int, 2)
+	for i, arg := range args[1:] {
+		if goals[i], err = strconv.Atoi(arg); err != nil {
+			return usagef("scores must be numbers, got %q", arg)
+		}
+	}
+
+	result := &dto.MatchResultRequest{HomeTeamScore: &goals[0], AwayTeamScore: &goals[1]}
+	if err := a.leagues.EditMatchResults(parsed[0], result); err != nil {
+		return err
+	}
+	match, err := a.leagues.GetMatchByID(parsed[0])
+	if err != nil {
+		return err
+	}
+	league, err := a.leagues.GetLeagueByID(match.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	names := teamNames(league)
+	return a.print(match, []string{"ID", "WEEK", "HOME", "AWAY", "SCORE"}, [][]string{{
+		fmt.Sprint(match.ID), fmt.Sprint(match.Week), names[match.HomeTeamID], names[match.AwayTeamID], score(match),
+	}})
+}
+
+func predict(a *app, args []string) error {
+	parsed, err := ids(args, "league")
+	if err != nil {
+		return err
+	}
+	predictions, err := a.leagues.PredictChampion(parsed[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(predictions))
+	for _, prediction := range predictions {
+		rows = append(rows, []string{prediction.TeamName, fmt.Sprintf("%.1f%%", prediction.WinProbability*100)})
+	}
+	return a.print(predictions, []string{"TEAM", "CHANCE"}, rows)
+}
+
+// teamNames maps the IDs of the teams of a league to their names
+func teamNames(league *models.League) map[uint]string {
+	names := make(map[uint]string, len(league.Teams))
+	for _, team := range league.Teams {
+		names[team.ID] = team.Name
+	}
+	return names
+}
@@ -0,0 +1,147 @@
+// Command leaguectl administers leagues from the command line, through the same services as the server and against
+// the database it is configured with. It does not need the server to run.
+//
+//	leaguectl [-org name] [-reason text] [-o table|json] <command> [arguments]
+//
+// Changes are audited as made by leaguectl:<user>, the user running the tool, with the reason given.
+//
+// Run leaguectl -h for the list of commands.
+package main
+
+import (
+	"LeagueManager/internal"
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"LeagueManager/internal/infrastructure/config"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+// Exit codes
+const (
+	exitFailed = 1 // The command failed
+	exitUsage  = 2 // The command line is wrong
+)
+
+func main() {
+	godotenv.Load()
+	config.InitLog()
+	if os.Getenv("LOG_LEVEL") == "" {
+		logrus.SetLevel(logrus.ErrorLevel)
+	}
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command line, writing its output to stdout and its errors to stderr, and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("leaguectl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	organization := flags.String("org", models.DefaultOrganizationName, "organization to act for")
+	format := flags.String("o", formatTable, "output format, table or json")
+	reason := flags.String("reason", "", "why the changes are made, recorded in the audit log")
+	flags.Usage = func() { printUsage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+	if *format != formatTable && *format != formatJSON {
+		fmt.Fprintf(stderr, "leaguectl: unknown output format %q, use table or json\n", *format)
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		printUsage(stderr, flags)
+		return exitUsage
+	}
+	cmd, ok := findCommand(flags.Arg(0))
+	if !ok {
+		fmt.Fprintf(stderr, "leaguectl: unknown command %q, run leaguectl -h for the list\n", flags.Arg(0))
+		return exitUsage
+	}
+
+	initialization, err := internal.InitCLI()
+	if err != nil {
+		fmt.Fprintf(stderr, "leaguectl: failed to initialize: %v\n", err)
+		return exitFailed
+	}
+	a, err := newApp(initialization, *organization, *reason, stdout, *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "leaguectl: %v\n", err)
+		return exitFailed
+	}
+	code := a.execute(cmd, flags.Args()[1:], stderr)
+	initialization.WebhookDispatcher.QueuePending()
+	return code
+}
+
+// newApp binds the services to the organization of the given name, which must exist
+func newApp(initialization *config.CLIInitialization, organization, reason string, out io.Writer, format string) (*app, error) {
+	organizations, err := initialization.OrganizationSvc.GetAllOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range organizations {
+		if candidate.Name == organization {
+			scope := cliScope(candidate.ID, reason)
+			return &app{teams: initialization.TeamSvc.WithScope(scope), leagues: initialization.LeagueSvc.WithScope(scope), out: out, format: format}, nil
+		}
+	}
+	return nil, fmt.Errorf("organization %q does not exist", organization)
+}
+
+// cliScope is the scope the tool acts in. The changes are audited as made by the user running it, which tells them
+// apart from the scheduler's, audited as the system.
+func cliScope(organizationID uint, reason string) repositories.Scope {
+	return repositories.Scope{OrganizationID: organizationID, Actor: "leaguectl:" + username(), Reason: reason}
+}
+
+// username returns the name of the user running the tool
+func username() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// execute runs a command and reports its error, with the fields a validation error names
+func (a *app) execute(cmd *command, args []string, stderr io.Writer) int {
+	err := cmd.run(a, args)
+	if err == nil {
+		return 0
+	}
+
+	var usage usageError
+	if errors.As(err, &usage) {
+		fmt.Fprintf(stderr, "leaguectl %s: %v\nusage: leaguectl %s %s\n", cmd.name, err, cmd.name, cmd.args)
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "leaguectl %s: %v\n", cmd.name, err)
+	for _, field := range apperrors.FieldsOf(err) {
+		fmt.Fprintf(stderr, "  %s: %s\n", field.Field, field.Message)
+	}
+	return exitFailed
+}
+
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: leaguectl [-org name] [-reason text] [-o table|json] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-56s %s\n", cmd.name+" "+cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "flags:")
+	flags.PrintDefaults()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTest(t *testing.T) (*app, *bytes.Buffer) {
+	return setupTestWithScope(t, setupTestDB(t), cliScope(1, ""))
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{}, &models.AuditEntry{}, &models.FixtureConstraint{},
+		&models.AdvancementSchedule{}, &models.WebhookSubscription{}))
+	return db
+}
+
+func setupTestWithScope(t *testing.T, db *gorm.DB, scope repositories.Scope) (*app, *bytes.Buffer) {
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	out := &bytes.Buffer{}
+	return &app{
+		teams:   services.NewTeamService(teamRepo, leagueRepo, uow).WithScope(scope),
+		leagues: services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus()).WithScope(scope),
+		out:     out,
+		format:  formatTable,
+	}, out
+}
+
+func TestCommands(t *testing.T) {
+	a, out := setupTest(t)
+	stderr := &bytes.Buffer{}
+	exec := func(args ...string) int {
+		out.Reset()
+		stderr.Reset()
+		cmd, ok := findCommand(args[0])
+		require.True(t, ok, args[0])
+		return a.execute(cmd, args[1:], stderr)
+	}
+
+	for _, name := range []string{"Lions", "Tigers", "Bears", "Wolves"} {
+		require.Equal(t, 0, exec("create-team", "-name", name, "-attack", "70", "-defense", "60"), stderr.String())
+	}
+	require.Equal(t, 0, exec("teams"))
+	assert.Contains(t, out.String(), "ID  NAME    ATTACK  DEFENSE")
+	assert.Equal(t, 5, strings.Count(out.String(), "\n"))
+
+	require.Equal(t, 0, exec("create-league", "-name", "Premier"), stderr.String())
+	assert.Contains(t, out.String(), "not started")
+	for _, team := range []string{"1", "2", "3", "4"} {
+		require.Equal(t, 0, exec("add-team", "1", team), stderr.String())
+	}
+	require.Equal(t, 0, exec("start", "1"), stderr.String())
+	require.Equal(t, 0, exec("advance", "1"), stderr.String())
+	assert.Contains(t, out.String(), "2/38")
+
+	a.format = formatJSON
+	require.Equal(t, 0, exec("standings", "1"), stderr.String())
+	var standings []struct {
+		Position int    `json:"position"`
+		TeamName string `json:"team_name"`
+		Played   int    `json:"played"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &standings))
+	if assert.Len(t, standings, 4) {
+		assert.Equal(t, 1, standings[0].Position)
+		assert.NotEmpty(t, standings[0].TeamName)
+		assert.Equal(t, 1, standings[0].Played)
+	}
+
+	require.Equal(t, 0, exec("play-all", "1"), stderr.String())
+	var league models.League
+	require.NoError(t, json.Unmarshal(out.Bytes(), &league))
+	assert.Equal(t, models.TotalWeeks, league.CurrentWeek)
+
+	a.format = formatTable
+	require.Equal(t, 0, exec("edit-match", "1", "4", "0"), stderr.String())
+	assert.Contains(t, out.String(), "4-0")
+	require.Equal(t, 0, exec("predict", "1"), stderr.String())
+	assert.Contains(t, out.String(), "TEAM    CHANCE")
+	assert.Equal(t, 4, strings.Count(out.String(), "%"))
+
+	// Errors go to stderr with the fields they name, wrong command lines are told apart by their exit code
+	assert.Equal(t, exitFailed, exec("create-team", "-name", "Lions"))
+	assert.Contains(t, stderr.String(), "leaguectl create-team:")
+	assert.Empty(t, out.String())
+	assert.Equal(t, exitFailed, exec("start", "99"))
+	assert.Equal(t, exitUsage, exec("start", "first"))
+	assert.Contains(t, stderr.String(), "usage: leaguectl start <league>")
+	assert.Equal(t, exitUsage, exec("create-league"))
+	assert.Equal(t, exitUsage, exec("edit-match", "1", "two", "0"))
+}
+
+func TestChangesAreAuditedAsTheUser(t *testing.T) {
+	db := setupTestDB(t)
+	scope := cliScope(1, "pre-season setup")
+	assert.Equal(t, "leaguectl:"+username(), scope.Actor)
+	a, _ := setupTestWithScope(t, db, scope)
+
+	cmd, _ := findCommand("create-league")
+	require.Equal(t, 0, a.execute(cmd, []string{"-name", "Premier"}, &bytes.Buffer{}))
+
+	var entries []models.AuditEntry
+	require.NoError(t, db.Find(&entries).Error)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, scope.Actor, entries[0].Actor, "the change is not taken for one made by the scheduler")
+		assert.Equal(t, "pre-season setup", entries[0].Reason)
+	}
+}
+
+func TestRunNeedsNoCredentials(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_DSN", filepath.Join(t.TempDir(), "league.db"))
+	for _, name := range []string{"AUTH_DISABLED", "AUTH_API_KEYS", "AUTH_TOKEN_SECRET"} {
+		t.Setenv(name, "")
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	require.Equal(t, 0, run([]string{"create-team", "-name", "Lions"}, stdout, stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Lions")
+	assert.Empty(t, os.Getenv("AUTH_DISABLED"), "the environment is left as it was")
+}
+
+func TestRunRejectsBadCommandLines(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	assert.Equal(t, exitUsage, run([]string{"dance"}, stdout, stderr))
+	assert.Contains(t, stderr.String(), `unknown command "dance"`)
+	assert.Equal(t, exitUsage, run([]string{"-o", "yaml", "teams"}, stdout, stderr))
+	assert.Equal(t, exitUsage, run(nil, stdout, stderr))
+	assert.Equal(t, 0, run([]string{"-h"}, stdout, stderr))
+	assert.Contains(t, stderr.String(), "play-all <league>")
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"LeagueManager/internal/domain/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table" // Aligned columns for reading
+	formatJSON  = "json"  // The records as the API returns them, for scripts
+)
+
+// print writes v as JSON, or the rows of its table aligned under their header
+func (a *app) print(v any, header []string, rows [][]string) error {
+	if a.format == formatJSON {
+		encoder := json.NewEncoder(a.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	writeRow(w, header)
+	for _, row := range rows {
+		writeRow(w, row)
+	}
+	return w.Flush()
+}
+
+func writeRow(w io.Writer, cells []string) {
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
+
+func (a *app) printTeams(teams []*models.Team) error {
+	rows := make([][]string, 0, len(teams))
+	for _, team := range teams {
+		rows = append(rows, []string{fmt.Sprint(team.ID), team.Name, fmt.Sprint(team.AttackStrength), fmt.Sprint(team.DefenseStrength)})
+	}
+	return a.print(teams, []string{"ID", "NAME", "ATTACK", "DEFENSE"}, rows)
+}
+
+func (a *app) printLeagues(leagues []*models.League) error {
+	rows := make([][]string, 0, len(leagues))
+	for _, league := range leagues {
+		rows = append(rows, []string{fmt.Sprint(league.ID), league.Name, weekOf(league), league.ResultMode, fmt.Sprint(len(league.Teams))})
+	}
+	return a.print(leagues, []string{"ID", "NAME", "WEEK", "MODE", "TEAMS"}, rows)
+}
+
+// printLeague prints a single league as an object rather than a list of one
+func (a *app) printLeague(league *models.League) error {
+	if a.format == formatJSON {
+		return a.print(league, nil, nil)
+	}
+	return a.printLeagues([]*models.League{league})
+}
+
+// weekOf describes how far a league has got
+func weekOf(league *models.League) string {
+	switch {
+	case league.CurrentWeek == 0:
+		return "not started"
+	case league.IsActive():
+		return fmt.Sprintf("%d/%d", league.CurrentWeek, models.TotalWeeks)
+	default:
+		return "finished"
+	}
+}
+
+// score shows the result of a match, or that it is still to be played
+func score(match *models.Match) string {
+	if match.Status != models.MatchStatusPlayed {
+		return match.Status
+	}
+	return fmt.Sprintf("%d-%d", match.HomeTeamScore, match.AwayTeamScore)
+}
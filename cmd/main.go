@@ -4,10 +4,20 @@ import (
 	"LeagueManager/internal"
 	"LeagueManager/internal/infrastructure/config"
 	"LeagueManager/internal/infrastructure/router"
+	"context"
+	"errors"
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout is how long the requests in progress have to finish once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
 func init() {
 	godotenv.Load()
 	config.InitLog()
@@ -19,8 +29,30 @@ func main() {
 		port = "8080"
 	}
 
-	init, _ := internal.Init()
-	app := router.Init(init)
+	init, err := internal.Init()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize the application: %v", err)
+	}
+
+	// The background work stops with the server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	init.WebhookDispatcher.Start(ctx)
+	init.Scheduler.Start(ctx)
+	init.LiveSvc.Start(ctx)
 
-	app.Run(":" + port)
+	server := &http.Server{Addr: ":" + port, Handler: router.Init(init)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("Failed to serve: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logrus.Info("Shutting down")
+	shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		logrus.WithError(err).Warn("Failed to finish the requests in progress")
+	}
 }
@@ -0,0 +1,20 @@
+// Command openapigen writes the OpenAPI document of the API, generated from the handler annotations.
+//
+//	go run ./cmd/openapigen -root . -out internal/presentation/openapi/openapi.json
+package main
+
+import (
+	"LeagueManager/internal/presentation/openapi/generator"
+	"flag"
+	"log"
+)
+
+func main() {
+	root := flag.String("root", ".", "module root directory")
+	out := flag.String("out", "openapi.json", "file to write the document to")
+	flag.Parse()
+
+	if err := generator.WriteFile(*root, *out); err != nil {
+		log.Fatalf("failed to generate the OpenAPI document: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Defaults of the broker
+const (
+	DefaultReplayLength   = 256 // Events kept per league for resuming subscribers
+	DefaultSubscriberSize = 64  // Events a subscriber may fall behind before it is dropped
+)
+
+// Message is an event as seen by the subscribers of a broker. ID orders the messages of a broker,
+// subscribers hand the ID of the last message they saw back to resume after it.
+type Message struct {
+	ID    string
+	Event Event
+}
+
+// Subscription receives the events of one league. Messages is closed when the subscription is cancelled, or when
+// the subscriber fell too far behind, in which case it should subscribe again from the last message it saw.
+type Subscription struct {
+	// Backlog holds the messages published since the requested ID, they precede those sent on Messages
+	Backlog []Message
+	// Reset is true when the requested ID is unknown to the broker, because it is too old or from before a restart,
+	// so events may have been missed and the subscriber should reload the league
+	Reset    bool
+	Messages <-chan Message
+	Cancel   func()
+}
+
+// Broker keeps the recent events of every league so subscribers can follow a league and resume
+// where they left off after a reconnection. It only holds events published since it was created.
+type Broker struct {
+	mu          sync.Mutex
+	epoch       string
+	sequence    uint64
+	leagues     map[feedKey]*leagueFeed
+	replay      int
+	subscribers int
+}
+
+type feedKey struct {
+	organizationID uint
+	leagueID       uint
+}
+
+type leagueFeed struct {
+	recent      []Message
+	trimmed     uint64 // Sequence of the newest message dropped from recent
+	subscribers map[chan Message]struct{}
+}
+
+// NewBroker creates a broker fed by the bus
+func NewBroker(bus Bus) *Broker {
+	epoch := make([]byte, 4)
+	if _, err := rand.Read(epoch); err != nil {
+		panic(err)
+	}
+	b := &Broker{
+		epoch:       hex.EncodeToString(epoch),
+		leagues:     map[feedKey]*leagueFeed{},
+		replay:      DefaultReplayLength,
+		subscribers: DefaultSubscriberSize,
+	}
+	bus.Subscribe(b.publish)
+	return b
+}
+
+// Subscribe follows the events of a league of the organization. lastID is the ID of the last message the
+// subscriber saw, empty for a new subscriber that only wants the events to come.
+func (b *Broker) Subscribe(organizationID, leagueID uint, lastID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	feed := b.feed(organizationID, leagueID)
+	subscription := &Subscription{}
+	if lastID != "" {
+		subscription.Backlog, subscription.Reset = b.since(feed, lastID)
+	}
+
+	messages := make(chan Message, b.subscribers)
+	feed.subscribers[messages] = struct{}{}
+	subscription.Messages = messages
+
+	var once sync.Once
+	subscription.Cancel = func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := feed.subscribers[messages]; ok {
+				delete(feed.subscribers, messages)
+				close(messages)
+			}
+		})
+	}
+	return subscription
+}
+
+func (b *Broker) feed(organizationID, leagueID uint) *leagueFeed {
+	key := feedKey{organizationID: organizationID, leagueID: leagueID}
+	feed, ok := b.leagues[key]
+	if !ok {
+		feed = &leagueFeed{subscribers: map[chan Message]struct{}{}}
+		b.leagues[key] = feed
+	}
+	return feed
+}
+
+// since returns the recent messages after lastID, or reports a reset if some of them are no longer kept
+// or lastID was not issued by this broker
+func (b *Broker) since(feed *leagueFeed, lastID string) ([]Message, bool) {
+	epoch, sequence, ok := parseMessageID(lastID)
+	if !ok || epoch != b.epoch || sequence > b.sequence || sequence < feed.trimmed {
+		return nil, true
+	}
+
+	for i, message := range feed.recent {
+		if _, current, _ := parseMessageID(message.ID); current > sequence {
+			return append([]Message(nil), feed.recent[i:]...), false
+		}
+	}
+	return nil, false
+}
+
+func (b *Broker) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	feed := b.feed(event.OrganizationID, event.LeagueID)
+	b.sequence++
+	message := Message{ID: fmt.Sprintf("%s-%d", b.epoch, b.sequence), Event: event}
+	feed.recent = append(feed.recent, message)
+	if len(feed.recent) > b.replay {
+		dropped := feed.recent[len(feed.recent)-b.replay-1]
+		_, feed.trimmed, _ = parseMessageID(dropped.ID)
+		feed.recent = append([]Message(nil), feed.recent[len(feed.recent)-b.replay:]...)
+	}
+
+	for subscriber := range feed.subscribers {
+		select {
+		case subscriber <- message:
+		default:
+			// A subscriber that cannot keep up is dropped, it resumes from its last message when it reconnects
+			delete(feed.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// parseMessageID splits a message ID into the epoch of its broker and its sequence number
+func parseMessageID(id string) (string, uint64, bool) {
+	epoch, raw, found := strings.Cut(id, "-")
+	if !found {
+		return "", 0, false
+	}
+	sequence, err := strconv.ParseUint(raw, 10, 64)
+	return epoch, sequence, err == nil
+}
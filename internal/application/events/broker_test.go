@@ -0,0 +1,73 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerResumesFromLastID(t *testing.T) {
+	bus := NewBus()
+	broker := NewBroker(bus)
+
+	live := broker.Subscribe(1, 10, "")
+	defer live.Cancel()
+	assert.False(t, live.Reset)
+	assert.Empty(t, live.Backlog)
+
+	bus.Publish(New(LeagueStarted, 1, 10, nil), New(WeekAdvanced, 1, 20, nil), New(WeekAdvanced, 1, 10, nil), New(WeekAdvanced, 2, 10, nil))
+
+	first, second := <-live.Messages, <-live.Messages
+	assert.Equal(t, LeagueStarted, first.Event.Type)
+	assert.Equal(t, WeekAdvanced, second.Event.Type)
+	assert.Len(t, live.Messages, 0, "events of other leagues and organizations are not sent")
+
+	resumed := broker.Subscribe(1, 10, first.ID)
+	defer resumed.Cancel()
+	assert.False(t, resumed.Reset)
+	if assert.Len(t, resumed.Backlog, 1) {
+		assert.Equal(t, second.ID, resumed.Backlog[0].ID)
+	}
+
+	upToDate := broker.Subscribe(1, 10, second.ID)
+	defer upToDate.Cancel()
+	assert.False(t, upToDate.Reset)
+	assert.Empty(t, upToDate.Backlog)
+
+	for _, lastID := range []string{"garbage", "00000000-1", first.ID[:8] + "-999"} {
+		subscription := broker.Subscribe(1, 10, lastID)
+		assert.True(t, subscription.Reset, lastID)
+		subscription.Cancel()
+	}
+}
+
+func TestBrokerTrimsAndDropsSlowSubscribers(t *testing.T) {
+	bus := NewBus()
+	broker := NewBroker(bus)
+	broker.replay, broker.subscribers = 3, 2
+
+	slow := broker.Subscribe(1, 10, "")
+	var ids []string
+	for i := 0; i < 5; i++ {
+		bus.Publish(New(WeekAdvanced, 1, 10, i))
+	}
+
+	// The slow subscriber got the first two events, then its channel was closed
+	for range [2]struct{}{} {
+		message, open := <-slow.Messages
+		require.True(t, open)
+		ids = append(ids, message.ID)
+	}
+	_, open := <-slow.Messages
+	assert.False(t, open)
+	slow.Cancel()
+
+	// Three events are kept: resuming after the second one works, after the first one does not
+	resumed := broker.Subscribe(1, 10, ids[1])
+	assert.False(t, resumed.Reset)
+	assert.Len(t, resumed.Backlog, 3)
+	resumed.Cancel()
+
+	assert.True(t, broker.Subscribe(1, 10, ids[0]).Reset)
+}
@@ -0,0 +1,124 @@
+// Package events carries the domain events of leagues from the services that cause them to the
+// components that react to them, such as webhook deliveries.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Types of the events published by the services
+const (
+	LeagueStarted     = "league.started"
+	WeekAdvanced      = "league.week_advanced"
+	SeasonFinished    = "league.season_finished"
+	TeamAdded         = "league.team_added"
+	TeamRemoved       = "league.team_removed"
+	MatchResultEdited = "match.result_edited"
+	LiveWeekStarted   = "league.live_week_started"
+	GoalScored        = "match.goal"
+	MatchPostponed    = "match.postponed"
+	MatchRescheduled  = "match.rescheduled"
+	ResultSubmitted   = "match.result_submitted"
+)
+
+// Types lists every event type, in the order they are documented
+var Types = []string{LeagueStarted, WeekAdvanced, SeasonFinished, TeamAdded, TeamRemoved, MatchResultEdited, LiveWeekStarted, GoalScored, MatchPostponed, MatchRescheduled, ResultSubmitted}
+
+// IsType reports whether name is a known event type
+func IsType(name string) bool {
+	for _, t := range Types {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something that happened to a league, Data is the JSON-encodable detail of the event
+type Event struct {
+	ID             string      `json:"id"`
+	Type           string      `json:"type"`
+	OrganizationID uint        `json:"organization_id"`
+	LeagueID       uint        `json:"league_id"`
+	OccurredAt     time.Time   `json:"occurred_at"`
+	Data           interface{} `json:"data"`
+}
+
+// New creates an event with a fresh ID that occurred now
+func New(eventType string, organizationID, leagueID uint, data interface{}) Event {
+	return Event{
+		ID:             newID(),
+		Type:           eventType,
+		OrganizationID: organizationID,
+		LeagueID:       leagueID,
+		OccurredAt:     time.Now().UTC(),
+		Data:           data,
+	}
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// Handler reacts to a published event. Handlers run on the publisher's goroutine and must not block.
+type Handler func(Event)
+
+// Bus delivers every published event to every subscribed handler
+type Bus interface {
+	Publish(events ...Event)
+	// Subscribe registers handler and returns a function that removes it again
+	Subscribe(handler Handler) (unsubscribe func())
+}
+
+type memoryBus struct {
+	mu       sync.RWMutex
+	handlers map[int]Handler
+	next     int
+}
+
+// NewBus creates an in-process bus
+func NewBus() Bus {
+	return &memoryBus{handlers: map[int]Handler{}}
+}
+
+func (b *memoryBus) Publish(events ...Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for i := 0; i < b.next; i++ {
+		if handler, ok := b.handlers[i]; ok {
+			handlers = append(handlers, handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+func (b *memoryBus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.handlers, id)
+		})
+	}
+}
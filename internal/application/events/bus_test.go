@@ -0,0 +1,34 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusFansOutToSubscribers(t *testing.T) {
+	bus := NewBus()
+
+	var first, second []string
+	unsubscribe := bus.Subscribe(func(event Event) { first = append(first, event.Type) })
+	bus.Subscribe(func(event Event) { second = append(second, event.Type) })
+
+	bus.Publish(New(LeagueStarted, 1, 2, nil), New(WeekAdvanced, 1, 2, nil))
+	assert.Equal(t, []string{LeagueStarted, WeekAdvanced}, first)
+	assert.Equal(t, []string{LeagueStarted, WeekAdvanced}, second)
+
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(New(SeasonFinished, 1, 2, nil))
+	assert.Len(t, first, 2, "an unsubscribed handler receives nothing")
+	assert.Len(t, second, 3)
+}
+
+func TestNewEvent(t *testing.T) {
+	a, b := New(TeamAdded, 1, 2, nil), New(TeamAdded, 1, 2, nil)
+	assert.Len(t, a.ID, 32)
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.False(t, a.OccurredAt.IsZero())
+	assert.True(t, IsType(TeamAdded))
+	assert.False(t, IsType("webhook.ping"))
+}
@@ -0,0 +1,109 @@
+package fixtures
+
+import (
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"fmt"
+	"sort"
+)
+
+// Evaluate reports the breaks of the matches between the teams and the constraints the matches do not satisfy,
+// including those about teams that are not among them. Only LeagueID and Drawn are left to the caller.
+func Evaluate(matches []models.Match, teamIDs []uint, constraints []models.FixtureConstraint) *dto.FixtureReport {
+	members := make(map[uint]bool, len(teamIDs))
+	for _, id := range teamIDs {
+		members[id] = true
+	}
+	status := map[uint]map[int]int8{}
+	meetings := map[[2]uint]map[int]bool{}
+	for _, match := range matches {
+		for _, side := range []struct {
+			team  uint
+			where int8
+		}{{match.HomeTeamID, home}, {match.AwayTeamID, away}} {
+			if status[side.team] == nil {
+				status[side.team] = map[int]int8{}
+			}
+			// A team with a rescheduled match may play twice in a week, it counts as at home if it is once
+			if status[side.team][match.Week] != home {
+				status[side.team][match.Week] = side.where
+			}
+		}
+		pair := unordered(match.HomeTeamID, match.AwayTeamID)
+		if meetings[pair] == nil {
+			meetings[pair] = map[int]bool{}
+		}
+		meetings[pair][match.Week] = true
+	}
+
+	report := &dto.FixtureReport{Constraints: len(constraints), Unsatisfied: []dto.UnsatisfiedConstraint{}}
+	for _, id := range teamIDs {
+		report.Breaks += breaks(status[id])
+	}
+
+	for _, constraint := range constraints {
+		unsatisfied := func(weeks []int, reason string, args ...interface{}) {
+			report.Unsatisfied = append(report.Unsatisfied, dto.UnsatisfiedConstraint{
+				Constraint: constraint,
+				Weeks:      weeks,
+				Reason:     fmt.Sprintf(reason, args...),
+			})
+		}
+		teams := []uint{constraint.TeamID}
+		if constraint.Kind != models.ConstraintBlackout {
+			teams = append(teams, constraint.OtherTeamID)
+		}
+		missing := false
+		for _, team := range teams {
+			if !members[team] {
+				unsatisfied([]int{}, "team %d is not in the league", team)
+				missing = true
+				break
+			}
+		}
+		if missing {
+			continue
+		}
+
+		switch constraint.Kind {
+		case models.ConstraintSharedVenue:
+			var weeks []int
+			for week, where := range status[constraint.TeamID] {
+				if where == home && status[constraint.OtherTeamID][week] == home {
+					weeks = append(weeks, week)
+				}
+			}
+			if len(weeks) > 0 {
+				sort.Ints(weeks)
+				unsatisfied(weeks, "both teams play at home")
+			}
+		case models.ConstraintBlackout:
+			if status[constraint.TeamID][constraint.Week] == home {
+				unsatisfied([]int{constraint.Week}, "the team plays at home while its ground is unavailable")
+			}
+		case models.ConstraintDerby:
+			if !meetings[unordered(constraint.TeamID, constraint.OtherTeamID)][constraint.Week] {
+				unsatisfied([]int{constraint.Week}, "the teams do not meet that week")
+			}
+		}
+	}
+	return report
+}
+
+// breaks counts the weeks in which a team plays where it played the week before
+func breaks(weeks map[int]int8) int {
+	count := 0
+	for week, where := range weeks {
+		if previous, played := weeks[week-1]; played && previous == where {
+			count++
+		}
+	}
+	return count
+}
+
+func unordered(a, b uint) [2]uint {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]uint{a, b}
+}
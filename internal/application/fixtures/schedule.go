@@ -0,0 +1,291 @@
+// Package fixtures draws the fixtures of a season from a list of constraints. A season repeats cycles of
+// 2(n-1) weeks for n teams, in which every pair of teams meets once at each of their grounds. Cycle after cycle, a
+// branch-and-bound search breaks as few constraints as it can and, among the cycles that do, makes as few breaks as
+// it can: weeks in which a team plays at home, or away, for the second time in a row.
+package fixtures
+
+import (
+	"LeagueManager/internal/domain/models"
+	"math"
+	"sort"
+)
+
+const (
+	// violationCost weighs a broken constraint against breaks, a season with fewer broken constraints always wins
+	violationCost = 1000
+	// searchBudget is the number of decisions tried per cycle, after which the best cycle found so far is kept
+	searchBudget = 20000
+)
+
+// Where a team plays in a week
+const (
+	away  int8 = -1
+	rests int8 = 0
+	home  int8 = 1
+)
+
+// pairing is a match between two teams given by their index, the home team first
+type pairing [2]int
+
+// Schedule draws weeks weeks of fixtures between the teams. Only the week and the teams of the matches are set.
+// With an odd number of teams, one team rests every week. Constraints about other teams are ignored, Evaluate
+// reports them.
+func Schedule(teamIDs []uint, weeks int, constraints []models.FixtureConstraint) []models.Match {
+	if len(teamIDs) < 2 || weeks < 1 {
+		return nil
+	}
+	s := newSearch(teamIDs, weeks, constraints)
+	for start := 0; start < weeks; start += s.cycle {
+		s.solveCycle(start, min(start+s.cycle, weeks))
+	}
+	return s.matches()
+}
+
+type search struct {
+	teams  []uint // A zero ID stands for the rest of a week, with an odd number of teams
+	rounds [][]pairing
+	weeks  int
+	cycle  int
+
+	blackouts map[[2]int]bool // Team and week
+	partners  [][]int         // The teams sharing a ground with each team
+	derbies   map[int][][2]int
+
+	status   [][]int8           // Where each team plays, per week
+	uses     [][]int            // How often each round was played, per cycle
+	directed []map[pairing]bool // The matches played, per cycle
+	chosen   [][]pairing        // The matches of each week
+
+	// The cycle being searched, from week start to week end excluded, and the best matches found for its weeks
+	start, end int
+	cost       int
+	best       [][]pairing
+	bestCost   int
+	nodes      int
+}
+
+func newSearch(teamIDs []uint, weeks int, constraints []models.FixtureConstraint) *search {
+	teams := append([]uint(nil), teamIDs...)
+	if len(teams)%2 == 1 {
+		teams = append(teams, 0)
+	}
+	s := &search{
+		teams:     teams,
+		rounds:    roundRobin(len(teams)),
+		weeks:     weeks,
+		cycle:     2 * (len(teams) - 1),
+		blackouts: map[[2]int]bool{},
+		partners:  make([][]int, len(teams)),
+		derbies:   map[int][][2]int{},
+		status:    make([][]int8, weeks),
+		chosen:    make([][]pairing, weeks),
+	}
+	for w := range s.status {
+		s.status[w] = make([]int8, len(teams))
+	}
+	cycles := (weeks + s.cycle - 1) / s.cycle
+	s.uses = make([][]int, cycles)
+	s.directed = make([]map[pairing]bool, cycles)
+	for c := 0; c < cycles; c++ {
+		s.uses[c] = make([]int, len(s.rounds))
+		s.directed[c] = map[pairing]bool{}
+	}
+
+	index := make(map[uint]int, len(teamIDs))
+	for i, id := range teamIDs {
+		index[id] = i
+	}
+	for _, constraint := range constraints {
+		team, found := index[constraint.TeamID]
+		if !found {
+			continue
+		}
+		other, otherFound := index[constraint.OtherTeamID]
+		week := constraint.Week - 1
+		switch constraint.Kind {
+		case models.ConstraintSharedVenue:
+			if otherFound && other != team {
+				s.partners[team] = append(s.partners[team], other)
+				s.partners[other] = append(s.partners[other], team)
+			}
+		case models.ConstraintBlackout:
+			s.blackouts[[2]int{team, week}] = true
+		case models.ConstraintDerby:
+			if otherFound && other != team && week >= 0 && week < weeks {
+				s.derbies[week] = append(s.derbies[week], [2]int{team, other})
+			}
+		}
+	}
+	return s
+}
+
+// roundRobin returns the n-1 rounds in which n teams, n even, each meet every other team once, by the circle
+// method: the first team stays put while the others rotate around it
+func roundRobin(n int) [][]pairing {
+	others := make([]int, n-1)
+	for i := range others {
+		others[i] = i + 1
+	}
+	rounds := make([][]pairing, 0, n-1)
+	for r := 0; r < n-1; r++ {
+		round := []pairing{{0, others[0]}}
+		for i := 1; i < n/2; i++ {
+			round = append(round, pairing{others[i], others[n-1-i]})
+		}
+		rounds = append(rounds, round)
+		others = append(others[1:len(others):len(others)], others[0])
+	}
+	return rounds
+}
+
+// solveCycle searches the weeks of a cycle and keeps the best matches found, the weeks before it are settled
+func (s *search) solveCycle(start, end int) {
+	s.start, s.end = start, end
+	s.cost, s.best, s.bestCost, s.nodes = 0, nil, math.MaxInt, 0
+	s.week(start)
+
+	c := start / s.cycle
+	for i, pairings := range s.best {
+		w := start + i
+		s.chosen[w] = pairings
+		for _, p := range pairings {
+			s.status[w][p[0]], s.status[w][p[1]] = home, away
+			s.directed[c][p] = true
+		}
+	}
+}
+
+// exhausted reports whether the search should stop and keep the best season found so far
+func (s *search) exhausted() bool {
+	return s.best != nil && (s.nodes > searchBudget || s.bestCost == 0)
+}
+
+// week picks the round played in week w, trying first the rounds that hold the derbies of the week
+func (s *search) week(w int) {
+	if s.exhausted() {
+		return
+	}
+	if w == s.end {
+		if s.cost < s.bestCost {
+			s.bestCost = s.cost
+			s.best = make([][]pairing, 0, s.end-s.start)
+			for _, matches := range s.chosen[s.start:s.end] {
+				s.best = append(s.best, append([]pairing(nil), matches...))
+			}
+		}
+		return
+	}
+
+	c := w / s.cycle
+	order := make([]int, len(s.rounds))
+	penalties := make([]int, len(s.rounds))
+	for r := range s.rounds {
+		order[r] = r
+		penalties[r] = s.derbyPenalty(w, r)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return penalties[order[i]] < penalties[order[j]] })
+
+	for _, r := range order {
+		if s.uses[c][r] >= 2 || s.cost+penalties[r] >= s.bestCost {
+			continue
+		}
+		s.uses[c][r]++
+		s.cost += penalties[r]
+		s.orient(w, c, r, 0)
+		s.cost -= penalties[r]
+		s.uses[c][r]--
+	}
+}
+
+// orient picks the home team of match m of round r in week w, then moves on to the next match
+func (s *search) orient(w, c, r, m int) {
+	if m == len(s.rounds[r]) {
+		s.week(w + 1)
+		return
+	}
+	s.nodes++
+	if s.exhausted() {
+		return
+	}
+
+	p := s.rounds[r][m]
+	if s.teams[p[0]] == 0 || s.teams[p[1]] == 0 {
+		s.orient(w, c, r, m+1)
+		return
+	}
+	options := []pairing{p, {p[1], p[0]}}
+	costs := []int{s.orientationCost(w, options[0]), s.orientationCost(w, options[1])}
+	if costs[1] < costs[0] {
+		options[0], options[1] = options[1], options[0]
+		costs[0], costs[1] = costs[1], costs[0]
+	}
+
+	for i, o := range options {
+		if s.directed[c][o] || s.cost+costs[i] >= s.bestCost {
+			continue
+		}
+		s.directed[c][o] = true
+		s.status[w][o[0]], s.status[w][o[1]] = home, away
+		s.chosen[w] = append(s.chosen[w], o)
+		s.cost += costs[i]
+
+		s.orient(w, c, r, m+1)
+
+		s.cost -= costs[i]
+		s.chosen[w] = s.chosen[w][:len(s.chosen[w])-1]
+		s.status[w][o[0]], s.status[w][o[1]] = rests, rests
+		delete(s.directed[c], o)
+	}
+}
+
+// derbyPenalty is the cost of the derbies of week w that round r does not hold
+func (s *search) derbyPenalty(w, r int) int {
+	penalty := 0
+	for _, derby := range s.derbies[w] {
+		held := false
+		for _, p := range s.rounds[r] {
+			if (p[0] == derby[0] && p[1] == derby[1]) || (p[0] == derby[1] && p[1] == derby[0]) {
+				held = true
+				break
+			}
+		}
+		if !held {
+			penalty += violationCost
+		}
+	}
+	return penalty
+}
+
+// orientationCost is the cost of playing o in week w: the breaks it makes and the constraints it breaks
+func (s *search) orientationCost(w int, o pairing) int {
+	homeTeam, awayTeam := o[0], o[1]
+	cost := 0
+	if w > 0 {
+		if s.status[w-1][homeTeam] == home {
+			cost++
+		}
+		if s.status[w-1][awayTeam] == away {
+			cost++
+		}
+	}
+	if s.blackouts[[2]int{homeTeam, w}] {
+		cost += violationCost
+	}
+	for _, partner := range s.partners[homeTeam] {
+		if s.status[w][partner] == home {
+			cost += violationCost
+		}
+	}
+	return cost
+}
+
+// matches turns the settled weeks into matches
+func (s *search) matches() []models.Match {
+	var matches []models.Match
+	for w, pairings := range s.chosen {
+		for _, p := range pairings {
+			matches = append(matches, models.Match{Week: w + 1, HomeTeamID: s.teams[p[0]], AwayTeamID: s.teams[p[1]]})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,98 @@
+package fixtures
+
+import (
+	"LeagueManager/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleDrawsBalancedCycles(t *testing.T) {
+	teams := []uint{11, 12, 13, 14}
+	matches := Schedule(teams, models.TotalWeeks, nil)
+	require.Len(t, matches, 2*models.TotalWeeks)
+
+	perWeek := map[int]map[uint]bool{}
+	perCycle := map[int]map[[2]uint]int{}
+	for _, match := range matches {
+		if perWeek[match.Week] == nil {
+			perWeek[match.Week] = map[uint]bool{}
+		}
+		assert.False(t, perWeek[match.Week][match.HomeTeamID], "a team plays once a week")
+		assert.False(t, perWeek[match.Week][match.AwayTeamID], "a team plays once a week")
+		perWeek[match.Week][match.HomeTeamID] = true
+		perWeek[match.Week][match.AwayTeamID] = true
+
+		cycle := (match.Week - 1) / 6
+		if perCycle[cycle] == nil {
+			perCycle[cycle] = map[[2]uint]int{}
+		}
+		perCycle[cycle][[2]uint{match.HomeTeamID, match.AwayTeamID}]++
+	}
+	assert.Len(t, perWeek, models.TotalWeeks)
+	for cycle := 0; cycle < 6; cycle++ {
+		assert.Len(t, perCycle[cycle], 12, "every pair meets once at each ground in cycle %d", cycle)
+	}
+
+	report := Evaluate(matches, teams, nil)
+	assert.Empty(t, report.Unsatisfied)
+	assert.Less(t, report.Breaks, 4*models.TotalWeeks/2, "most weeks alternate home and away")
+}
+
+func TestScheduleHonoursConstraints(t *testing.T) {
+	teams := []uint{1, 2, 3, 4}
+	constraints := []models.FixtureConstraint{
+		{Kind: models.ConstraintSharedVenue, TeamID: 1, OtherTeamID: 2},
+		{Kind: models.ConstraintBlackout, TeamID: 1, Week: 5},
+		{Kind: models.ConstraintBlackout, TeamID: 3, Week: 5},
+		{Kind: models.ConstraintDerby, TeamID: 1, OtherTeamID: 2, Week: 4},
+		{Kind: models.ConstraintDerby, TeamID: 3, OtherTeamID: 4, Week: 20},
+	}
+	matches := Schedule(teams, models.TotalWeeks, constraints)
+
+	report := Evaluate(matches, teams, constraints)
+	assert.Empty(t, report.Unsatisfied)
+	assert.Equal(t, len(constraints), report.Constraints)
+	for _, match := range matches {
+		if match.Week == 5 {
+			assert.NotContains(t, []uint{1, 3}, match.HomeTeamID)
+		}
+	}
+}
+
+func TestScheduleReportsUnsatisfiableConstraints(t *testing.T) {
+	teams := []uint{1, 2, 3, 4}
+	constraints := []models.FixtureConstraint{
+		{Kind: models.ConstraintDerby, TeamID: 1, OtherTeamID: 2, Week: 3},
+		{Kind: models.ConstraintDerby, TeamID: 1, OtherTeamID: 3, Week: 3},
+		{Kind: models.ConstraintBlackout, TeamID: 9, Week: 1},
+	}
+	matches := Schedule(teams, models.TotalWeeks, constraints)
+
+	report := Evaluate(matches, teams, constraints)
+	require.Len(t, report.Unsatisfied, 2)
+	assert.Equal(t, models.ConstraintDerby, report.Unsatisfied[0].Constraint.Kind)
+	assert.Equal(t, []int{3}, report.Unsatisfied[0].Weeks)
+	assert.Equal(t, "the teams do not meet that week", report.Unsatisfied[0].Reason)
+	assert.Equal(t, "team 9 is not in the league", report.Unsatisfied[1].Reason)
+}
+
+func TestScheduleRestsOneTeamOfAnOddNumber(t *testing.T) {
+	matches := Schedule([]uint{1, 2, 3}, 6, nil)
+	require.Len(t, matches, 6)
+
+	meetings := map[[2]uint]int{}
+	for _, match := range matches {
+		meetings[[2]uint{match.HomeTeamID, match.AwayTeamID}]++
+	}
+	assert.Len(t, meetings, 6, "every pair meets once at each ground")
+}
+
+func TestRoundRobin(t *testing.T) {
+	assert.Equal(t, [][]pairing{
+		{{0, 1}, {2, 3}},
+		{{0, 2}, {3, 1}},
+		{{0, 3}, {1, 2}},
+	}, roundRobin(4))
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxArchiveEntries bounds the teams, venues, matches, standings and constraints of an archive, like MaxImportEntries
+// does for an import. A league of 20 teams playing a full season is well below it.
+const MaxArchiveEntries = MaxImportEntries
+
+type ArchiveService interface {
+	ExportLeague(leagueID uint) (*dto.LeagueArchive, error)
+	RestoreLeague(archive *dto.LeagueArchive) (*dto.RestoreReport, error)
+	WithScope(scope repositories.Scope) ArchiveService
+}
+
+// ArchiveServiceImpl moves complete leagues between instances as portable archives
+type ArchiveServiceImpl struct {
+	uow repositories.UnitOfWork
+}
+
+func NewArchiveService(uow repositories.UnitOfWork) ArchiveService {
+	return &ArchiveServiceImpl{uow: uow}
+}
+
+// WithScope returns a service that archives and restores leagues of the scope's organization
+func (s *ArchiveServiceImpl) WithScope(scope repositories.Scope) ArchiveService {
+	return &ArchiveServiceImpl{uow: s.uow.WithScope(scope)}
+}
+
+// ExportLeague archives a league with its teams, the venues they and its matches refer to, its matches, standings and
+// fixture constraints
+func (s *ArchiveServiceImpl) ExportLeague(leagueID uint) (*dto.LeagueArchive, error) {
+	league, err := s.uow.Leagues().GetLeagueByID(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.uow.Matches().GetMatchesByLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := s.uow.Constraints().GetConstraintsByLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &dto.LeagueArchive{
+		Format:     dto.ArchiveFormat,
+		Version:    dto.ArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		League: dto.ArchivedLeague{
+			Name:        league.Name,
+			CurrentWeek: league.CurrentWeek,
+			ResultMode:  league.ResultMode,
+			Calendar:    league.Calendar,
+			TeamIDs:     []uint{},
+		},
+		Teams:       []dto.ArchivedTeam{},
+		Venues:      []dto.ArchivedVenue{},
+		Matches:     []dto.ArchivedMatch{},
+		Standings:   []dto.ArchivedStanding{},
+		Constraints: []dto.ArchivedConstraint{},
+	}
+
+	// Venues deleted since are left out along with the references to them
+	venues := map[uint]bool{}
+	venue := func(venueID *uint) (*uint, error) {
+		if venueID == nil {
+			return nil, nil
+		}
+		if archived, ok := venues[*venueID]; ok {
+			if !archived {
+				return nil, nil
+			}
+			return venueID, nil
+		}
+		found, err := s.uow.Venues().GetVenueByID(*venueID)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			venues[*venueID] = false
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		venues[*venueID] = true
+		archive.Venues = append(archive.Venues, dto.ArchivedVenue{
+			ID: found.ID, Name: found.Name, City: found.City, Capacity: found.Capacity, Latitude: found.Latitude, Longitude: found.Longitude,
+		})
+		return venueID, nil
+	}
+
+	for _, team := range league.Teams {
+		homeVenueID, err := venue(team.HomeVenueID)
+		if err != nil {
+			return nil, err
+		}
+		archive.League.TeamIDs = append(archive.League.TeamIDs, team.ID)
+		archive.Teams = append(archive.Teams, dto.ArchivedTeam{
+			ID: team.ID, Name: team.Name, AttackStrength: team.AttackStrength, DefenseStrength: team.DefenseStrength, HomeVenueID: homeVenueID,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Week != matches[j].Week {
+			return matches[i].Week < matches[j].Week
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	for _, match := range matches {
+		venueID, err := venue(match.VenueID)
+		if err != nil {
+			return nil, err
+		}
+		archive.Matches = append(archive.Matches, dto.ArchivedMatch{
+			HomeTeamID:    match.HomeTeamID,
+			AwayTeamID:    match.AwayTeamID,
+			HomeTeamScore: match.HomeTeamScore,
+			AwayTeamScore: match.AwayTeamScore,
+			Week:          match.Week,
+			OriginalWeek:  match.OriginalWeek,
+			Status:        match.Status,
+			KickoffAt:     match.KickoffAt,
+			KickoffFixed:  match.KickoffFixed,
+			VenueID:       venueID,
+			VenueFixed:    match.VenueFixed,
+			Attendance:    match.Attendance,
+		})
+	}
+	for _, standing := range league.Standings {
+		archive.Standings = append(archive.Standings, dto.ArchivedStanding{
+			TeamID:         standing.TeamID,
+			Points:         standing.Points,
+			Played:         standing.Played,
+			Wins:           standing.Wins,
+			Draws:          standing.Draws,
+			Losses:         standing.Losses,
+			GoalDifference: standing.GoalDifference,
+		})
+	}
+	for _, constraint := range constraints {
+		archive.Constraints = append(archive.Constraints, dto.ArchivedConstraint{
+			Kind: constraint.Kind, TeamID: constraint.TeamID, OtherTeamID: constraint.OtherTeamID, Week: constraint.Week,
+		})
+	}
+
+	if archive.Checksum, err = archiveChecksum(archive); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// archiveChecksum returns the hex SHA-256 of the archive encoded as JSON without its checksum
+func archiveChecksum(archive *dto.LeagueArchive) (string, error) {
+	unsigned := *archive
+	unsigned.Checksum = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RestoreLeague creates the league of an archive as a new league with new IDs. Teams and venues that exist under the
+// same name, venues in the same city, are used as they are instead of being created. The archive is checked before
+// anything is written, and the restored standings are checked against the restored results before the restore
+// commits, so an archive that does not hold together fails with every error found and leaves nothing behind.
+func (s *ArchiveServiceImpl) RestoreLeague(archive *dto.LeagueArchive) (*dto.RestoreReport, error) {
+	if archive.Format != dto.ArchiveFormat {
+		return nil, apperrors.Validation("unsupported_archive", "not a league archive, format is %q instead of %q", archive.Format, dto.ArchiveFormat)
+	}
+	if archive.Version < 1 || archive.Version > dto.ArchiveVersion {
+		return nil, apperrors.Validation("unsupported_archive_version", "archive version %d is not supported, this instance reads versions 1 to %d", archive.Version, dto.ArchiveVersion)
+	}
+	entries := len(archive.League.TeamIDs) + len(archive.Teams) + len(archive.Venues) + len(archive.Matches) +
+		len(archive.Standings) + len(archive.Constraints)
+	if entries > MaxArchiveEntries {
+		return nil, apperrors.Validation("validation_failed", "an archive holds at most %d entries, this one has %d", MaxArchiveEntries, entries)
+	}
+	if archive.Checksum != "" {
+		checksum, err := archiveChecksum(archive)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != archive.Checksum {
+			return nil, apperrors.Validation("archive_checksum_mismatch", "the archive has been altered or damaged, its checksum does not match its content")
+		}
+	}
+	if err := validateArchive(archive); err != nil {
+		return nil, err
+	}
+
+	report := &dto.RestoreReport{Teams: []dto.RestoredRecord{}, Venues: []dto.RestoredRecord{}, Matches: len(archive.Matches)}
+	err := s.uow.Transaction(func(tx repositories.UnitOfWork) error {
+		venueIDs := map[uint]*uint{}
+		for _, archived := range archive.Venues {
+			venue, matched, err := restoreVenue(tx.Venues(), archived)
+			if err != nil {
+				return err
+			}
+			venueIDs[archived.ID] = &venue.ID
+			report.Venues = append(report.Venues, dto.RestoredRecord{ArchiveID: archived.ID, ID: venue.ID, Name: venue.Name, Matched: matched})
+		}
+
+		teams := map[uint]*models.Team{}
+		for _, archived := range archive.Teams {
+			team, err := tx.Teams().GetTeamByName(strings.TrimSpace(archived.Name))
+			matched := err == nil
+			if errors.Is(err, apperrors.ErrNotFound) {
+				team = &models.Team{
+					Name:            strings.TrimSpace(archived.Name),
+					AttackStrength:  archived.AttackStrength,
+					DefenseStrength: archived.DefenseStrength,
+				}
+				if archived.HomeVenueID != nil {
+					team.HomeVenueID = venueIDs[*archived.HomeVenueID]
+				}
+				if err = tx.Teams().CreateTeam(team); err != nil {
+					return err
+				}
+				if err := recordChange(tx.Audit(), AuditTeamCreated, models.AuditEntityTeam, team.ID, 0, nil, snapshot(team)); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+			teams[archived.ID] = team
+			report.Teams = append(report.Teams, dto.RestoredRecord{ArchiveID: archived.ID, ID: team.ID, Name: team.Name, Matched: matched})
+		}
+
+		league := &models.League{
+			Name:        strings.TrimSpace(archive.League.Name),
+			CurrentWeek: archive.League.CurrentWeek,
+			ResultMode:  archive.League.ResultMode,
+			Calendar:    archive.League.Calendar,
+		}
+		if league.ResultMode == "" {
+			league.ResultMode = models.ResultModeSimulated
+		}
+		for _, teamID := range archive.League.TeamIDs {
+			league.Teams = append(league.Teams, *teams[teamID])
+		}
+		if err := tx.Leagues().CreateLeague(league); err != nil {
+			return err
+		}
+
+		for _, archived := range archive.Matches {
+			match := &models.Match{
+				LeagueID:      league.ID,
+				HomeTeamID:    teams[archived.HomeTeamID].ID,
+				AwayTeamID:    teams[archived.AwayTeamID].ID,
+				HomeTeamScore: archived.HomeTeamScore,
+				AwayTeamScore: archived.AwayTeamScore,
+				Week:          archived.Week,
+				OriginalWeek:  archived.OriginalWeek,
+				Status:        archived.Status,
+				KickoffAt:     archived.KickoffAt,
+				KickoffFixed:  archived.KickoffFixed,
+				VenueFixed:    archived.VenueFixed,
+				Attendance:    archived.Attendance,
+			}
+			if archived.VenueID != nil {
+				match.VenueID = venueIDs[*archived.VenueID]
+			}
+			if err := tx.Matches().CreateMatch(match); err != nil {
+				return err
+			}
+		}
+		for _, archived := range archive.Standings {
+			standing := &models.Standing{
+				LeagueID:       league.ID,
+				TeamID:         teams[archived.TeamID].ID,
+				Points:         archived.Points,
+				Played:         archived.Played,
+				Wins:           archived.Wins,
+				Draws:          archived.Draws,
+				Losses:         archived.Losses,
+				GoalDifference: archived.GoalDifference,
+			}
+			if err := tx.Standings().CreateStanding(standing); err != nil {
+				return err
+			}
+		}
+		constraints := make([]*models.FixtureConstraint, len(archive.Constraints))
+		for i, archived := range archive.Constraints {
+			constraints[i] = &models.FixtureConstraint{Kind: archived.Kind, TeamID: teams[archived.TeamID].ID, Week: archived.Week}
+			if archived.OtherTeamID != 0 {
+				constraints[i].OtherTeamID = teams[archived.OtherTeamID].ID
+			}
+		}
+		if err := tx.Constraints().ReplaceConstraints(league.ID, constraints); err != nil {
+			return err
+		}
+
+		leagues := NewLeagueService(tx.Leagues(), tx.Teams(), tx.Matches(), tx.Standings(), tx, nil)
+		if err := checkRestoredStandings(leagues, league.ID, teams); err != nil {
+			return err
+		}
+		if err := recordChange(tx.Audit(), AuditLeagueRestored, models.AuditEntityLeague, league.ID, league.ID, nil, leagueSnapshot(league)); err != nil {
+			return err
+		}
+
+		var err error
+		report.League, err = tx.Leagues().GetLeagueByID(league.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// restoreVenue finds the venue of the same name in the same city or creates it, reporting whether it was found
+func restoreVenue(venues repositories.VenueRepository, archived dto.ArchivedVenue) (*models.Venue, bool, error) {
+	name, city := strings.TrimSpace(archived.Name), strings.TrimSpace(archived.City)
+	candidates, _, err := venues.FindVenues(repositories.VenueFilter{NameContains: name, City: city}, repositories.Page{})
+	if err != nil {
+		return nil, false, err
+	}
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate.Name, name) {
+			return candidate, true, nil
+		}
+	}
+
+	venue := &models.Venue{Name: name, City: city, Capacity: archived.Capacity, Latitude: archived.Latitude, Longitude: archived.Longitude}
+	if err := venues.CreateVenue(venue); err != nil {
+		return nil, false, err
+	}
+	return venue, false, nil
+}
+
+// checkRestoredStandings compares the restored standings with those the restored results add up to
+func checkRestoredStandings(leagues LeagueService, leagueID uint, teams map[uint]*models.Team) error {
+	report, err := leagues.CheckStandings(leagueID, false)
+	if err != nil || report.Consistent {
+		return err
+	}
+
+	archiveIDs := map[uint]uint{}
+	for archiveID, team := range teams {
+		archiveIDs[team.ID] = archiveID
+	}
+	fields := make([]apperrors.FieldError, 0, len(report.Discrepancies))
+	for _, discrepancy := range report.Discrepancies {
+		fields = append(fields, apperrors.FieldError{
+			Field:   "standings",
+			Message: fmt.Sprintf("team %d does not add up to its results: %s", archiveIDs[discrepancy.TeamID], strings.Join(discrepancy.Fields, ", ")),
+		})
+	}
+	return apperrors.Validation("archive_inconsistent", "the standings of the archive do not match its results").WithFields(fields...)
+}
+
+// validateArchive checks that the records of an archive are valid and refer to each other correctly
+func validateArchive(archive *dto.LeagueArchive) error {
+	var fields []apperrors.FieldError
+	fail := func(field, message string, args ...interface{}) {
+		fields = append(fields, apperrors.FieldError{Field: field, Message: fmt.Sprintf(message, args...)})
+	}
+	prefixed := func(prefix string, err error) {
+		for _, field := range apperrors.FieldsOf(err) {
+			fail(prefix+field.Field, "%s", field.Message)
+		}
+	}
+
+	venues := map[uint]bool{}
+	for i, archived := range archive.Venues {
+		if venues[archived.ID] {
+			fail(fmt.Sprintf("venues[%d].id", i), "%d is used twice", archived.ID)
+		}
+		venues[archived.ID] = true
+		prefixed(fmt.Sprintf("venues[%d].", i), validateVenue(&models.Venue{
+			Name: archived.Name, City: archived.City, Capacity: archived.Capacity, Latitude: archived.Latitude, Longitude: archived.Longitude,
+		}))
+	}
+	venueOf := func(field string, venueID *uint) {
+		if venueID != nil && !venues[*venueID] {
+			fail(field, "must be a venue of the archive")
+		}
+	}
+
+	teams, names := map[uint]bool{}, map[string]bool{}
+	strengthMessage := fmt.Sprintf("must be between %d and %d", models.MinStrength, models.MaxStrength)
+	for i, archived := range archive.Teams {
+		field := fmt.Sprintf("teams[%d].", i)
+		if teams[archived.ID] {
+			fail(field+"id", "%d is used twice", archived.ID)
+		}
+		teams[archived.ID] = true
+		name := strings.ToLower(strings.TrimSpace(archived.Name))
+		switch {
+		case name == "":
+			fail(field+"name", "is required")
+		case names[name]:
+			fail(field+"name", "%q is used twice", archived.Name)
+		}
+		names[name] = true
+		if archived.AttackStrength < models.MinStrength || archived.AttackStrength > models.MaxStrength {
+			fail(field+"attack_strength", strengthMessage)
+		}
+		if archived.DefenseStrength < models.MinStrength || archived.DefenseStrength > models.MaxStrength {
+			fail(field+"defense_strength", strengthMessage)
+		}
+		venueOf(field+"home_venue_id", archived.HomeVenueID)
+	}
+
+	league := archive.League
+	if name := strings.TrimSpace(league.Name); name == "" {
+		fail("league.name", "is required")
+	} else if len(name) > 100 {
+		fail("league.name", "must be at most 100 characters long")
+	}
+	if league.ResultMode != "" && league.ResultMode != models.ResultModeSimulated && league.ResultMode != models.ResultModeManual {
+		fail("league.result_mode", "must be one of simulated, manual")
+	}
+	if league.CurrentWeek < 0 || league.CurrentWeek > models.TotalWeeks+1 {
+		fail("league.current_week", "must be between 0 and %d", models.TotalWeeks+1)
+	}
+	if league.Calendar.IsSet() {
+		if _, err := league.Calendar.KickoffOf(1); err != nil {
+			fail("league.calendar", "%s", err.Error())
+		}
+	}
+	members := map[uint]bool{}
+	for i, teamID := range league.TeamIDs {
+		field := fmt.Sprintf("league.team_ids[%d]", i)
+		switch {
+		case !teams[teamID]:
+			fail(field, "must be a team of the archive")
+		case members[teamID]:
+			fail(field, "team %d is listed twice", teamID)
+		case len(members) == 4:
+			fail(field, "a league has at most 4 teams")
+		default:
+			members[teamID] = true
+		}
+	}
+
+	for i, archived := range archive.Matches {
+		field := fmt.Sprintf("matches[%d].", i)
+		if !members[archived.HomeTeamID] {
+			fail(field+"home_team_id", "must be a team of the league")
+		}
+		if !members[archived.AwayTeamID] || archived.AwayTeamID == archived.HomeTeamID {
+			fail(field+"away_team_id", "must be another team of the league")
+		}
+		if archived.Week < 1 || archived.Week > models.TotalWeeks {
+			fail(field+"week", "must be between 1 and %d", models.TotalWeeks)
+		}
+		switch archived.Status {
+		case models.MatchStatusPlayed:
+			if archived.Week > league.CurrentWeek {
+				fail(field+"week", "a played match must not be after the current week %d", league.CurrentWeek)
+			}
+		case models.MatchStatusScheduled, models.MatchStatusPostponed:
+		default:
+			fail(field+"status", "must be one of scheduled, postponed, played")
+		}
+		if archived.HomeTeamScore < 0 || archived.AwayTeamScore < 0 {
+			fail(field+"home_team_score", "scores must not be negative")
+		}
+		venueOf(field+"venue_id", archived.VenueID)
+	}
+
+	standings := map[uint]bool{}
+	for i, archived := range archive.Standings {
+		field := fmt.Sprintf("standings[%d].team_id", i)
+		switch {
+		case !members[archived.TeamID]:
+			fail(field, "must be a team of the league")
+		case standings[archived.TeamID]:
+			fail(field, "team %d has two standings", archived.TeamID)
+		}
+		standings[archived.TeamID] = true
+	}
+
+	constraints := make([]models.FixtureConstraint, len(archive.Constraints))
+	for i, archived := range archive.Constraints {
+		constraints[i] = models.FixtureConstraint{Kind: archived.Kind, TeamID: archived.TeamID, OtherTeamID: archived.OtherTeamID, Week: archived.Week}
+	}
+	leagueTeams := &models.League{}
+	for teamID := range members {
+		leagueTeams.Teams = append(leagueTeams.Teams, models.Team{Model: gorm.Model{ID: teamID}})
+	}
+	prefixed("", validateConstraints(leagueTeams, constraints))
+
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "the archive has %d errors", len(fields)).WithFields(fields...)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	require.NoError(t, db.AutoMigrate(&models.Venue{}))
+	uow := repositories.NewUnitOfWork(db)
+	source, target := repositories.Scope{OrganizationID: 1}, repositories.Scope{OrganizationID: 2}
+
+	ground := &models.Venue{Name: "Riverside", City: "Leeds", Capacity: 20000}
+	require.NoError(t, services.NewVenueService(repositories.NewVenueRepository(db), uow).WithScope(source).CreateVenue(ground))
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 60}
+		if name == "Team A" {
+			team.HomeVenueID = &ground.ID
+		}
+		require.NoError(t, teamService.WithScope(source).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	leagues := leagueService.WithScope(source)
+	league := &models.League{Name: "Archived League", ResultMode: models.ResultModeSimulated, Teams: teams}
+	require.NoError(t, leagues.CreateLeague(league))
+	_, err := leagues.SetFixtureConstraints(league.ID, []models.FixtureConstraint{{Kind: models.ConstraintBlackout, TeamID: teams[1].ID, Week: 4}})
+	require.NoError(t, err)
+	require.NoError(t, leagues.StartLeague(league.ID))
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+
+	archives := services.NewArchiveService(uow)
+	archive, err := archives.WithScope(source).ExportLeague(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ArchiveFormat, archive.Format)
+	assert.Equal(t, dto.ArchiveVersion, archive.Version)
+	assert.NotEmpty(t, archive.Checksum)
+	assert.Len(t, archive.Teams, 4)
+	assert.Len(t, archive.Venues, 1)
+	assert.Len(t, archive.Matches, 2*models.TotalWeeks)
+	assert.Len(t, archive.Standings, 4)
+	assert.Len(t, archive.Constraints, 1)
+
+	// The archive survives being written out and read back
+	data, err := json.Marshal(archive)
+	require.NoError(t, err)
+	read := func() *dto.LeagueArchive {
+		var copied dto.LeagueArchive
+		require.NoError(t, json.Unmarshal(data, &copied))
+		return &copied
+	}
+
+	// Teams that exist in the target are matched by name and left alone
+	existing := &models.Team{Name: "team b", AttackStrength: 10, DefenseStrength: 10}
+	require.NoError(t, teamService.WithScope(target).CreateTeam(existing))
+
+	report, err := archives.WithScope(target).RestoreLeague(read())
+	require.NoError(t, err)
+	restored := report.League
+	assert.NotEqual(t, league.ID, restored.ID)
+	assert.Equal(t, uint(2), restored.OrganizationID)
+	assert.Equal(t, "Archived League", restored.Name)
+	assert.Equal(t, 3, restored.CurrentWeek)
+	assert.Len(t, restored.Teams, 4)
+	assert.Equal(t, 2*models.TotalWeeks, report.Matches)
+	matched := 0
+	for _, team := range report.Teams {
+		if team.Matched {
+			matched++
+			assert.Equal(t, existing.ID, team.ID)
+		}
+	}
+	assert.Equal(t, 1, matched)
+	if assert.Len(t, report.Venues, 1) {
+		assert.False(t, report.Venues[0].Matched)
+		assert.NotEqual(t, ground.ID, report.Venues[0].ID)
+	}
+	kept, err := teamService.WithScope(target).GetTeamByID(existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, kept.AttackStrength)
+
+	restoredLeagues := leagueService.WithScope(target)
+	consistency, err := restoredLeagues.CheckStandings(restored.ID, false)
+	require.NoError(t, err)
+	assert.True(t, consistency.Consistent)
+	constraints, err := restoredLeagues.GetFixtureConstraints(restored.ID)
+	require.NoError(t, err)
+	if assert.Len(t, constraints, 1) {
+		assert.Equal(t, existing.ID, constraints[0].TeamID)
+	}
+	require.NoError(t, restoredLeagues.AdvanceWeek(restored.ID), "the restored league carries on")
+
+	// Restoring again reuses the teams and the venue restored before
+	report, err = archives.WithScope(target).RestoreLeague(read())
+	require.NoError(t, err)
+	for _, team := range report.Teams {
+		assert.True(t, team.Matched)
+	}
+	assert.True(t, report.Venues[0].Matched)
+
+	countLeagues := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.League{}).Count(&count).Error)
+		return count
+	}
+	before := countLeagues()
+
+	tampered := read()
+	tampered.Matches[0].HomeTeamScore += 5
+	_, err = archives.WithScope(target).RestoreLeague(tampered)
+	assert.Equal(t, "archive_checksum_mismatch", apperrors.CodeOf(err))
+
+	// Without a checksum the results no longer add up to the standings, which is found before the restore commits
+	tampered.Checksum = ""
+	_, err = archives.WithScope(target).RestoreLeague(tampered)
+	assert.Equal(t, "archive_inconsistent", apperrors.CodeOf(err))
+	assert.NotEmpty(t, apperrors.FieldsOf(err))
+	assert.Equal(t, before, countLeagues(), "nothing is left behind")
+
+	broken := read()
+	broken.Checksum = ""
+	broken.Matches[0].HomeTeamID = 999
+	broken.Teams[1].Name = broken.Teams[0].Name
+	_, err = archives.WithScope(target).RestoreLeague(broken)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	fields := map[string]bool{}
+	for _, field := range apperrors.FieldsOf(err) {
+		fields[field.Field] = true
+	}
+	assert.True(t, fields["matches[0].home_team_id"])
+	assert.True(t, fields["teams[1].name"])
+
+	newer := read()
+	newer.Version = dto.ArchiveVersion + 1
+	_, err = archives.WithScope(target).RestoreLeague(newer)
+	assert.Equal(t, "unsupported_archive_version", apperrors.CodeOf(err))
+	_, err = archives.WithScope(target).RestoreLeague(&dto.LeagueArchive{Format: "spreadsheet", Version: 1})
+	assert.Equal(t, "unsupported_archive", apperrors.CodeOf(err))
+	assert.Equal(t, before, countLeagues())
+}
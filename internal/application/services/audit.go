@@ -0,0 +1,77 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"encoding/json"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditTeamCreated       = "team.created"
+	AuditTeamUpdated       = "team.updated"
+	AuditTeamDeleted       = "team.deleted"
+	AuditLeagueCreated     = "league.created"
+	AuditLeagueUpdated     = "league.updated"
+	AuditLeagueRenamed     = "league.renamed"
+	AuditLeagueDeleted     = "league.deleted"
+	AuditTeamAdded         = "league.team_added"
+	AuditTeamRemoved       = "league.team_removed"
+	AuditLeagueStarted     = "league.started"
+	AuditWeekAdvanced      = "league.week_advanced"
+	AuditAllMatchesPlayed  = "league.all_matches_played"
+	AuditStandingsRepaired = "league.standings_repaired"
+	AuditMatchResultEdited = "match.result_edited"
+	AuditCalendarSet       = "league.calendar_set"
+	AuditMatchKickoffSet   = "match.kickoff_set"
+	AuditVenueCreated      = "venue.created"
+	AuditVenueUpdated      = "venue.updated"
+	AuditVenueDeleted      = "venue.deleted"
+	AuditMatchVenueSet     = "match.venue_set"
+	AuditConstraintsSet    = "league.constraints_set"
+	AuditMatchPostponed    = "match.postponed"
+	AuditMatchRescheduled  = "match.rescheduled"
+	AuditResultModeSet     = "league.result_mode_set"
+	AuditResultSubmitted   = "match.result_submitted"
+	AuditResultsImported   = "league.results_imported"
+	AuditLeagueRestored    = "league.restored"
+)
+
+// leagueState is the audited state of a league, its matches and standings are audited on their own
+type leagueState struct {
+	Name        string `json:"name"`
+	CurrentWeek int    `json:"current_week"`
+	ResultMode  string `json:"result_mode"`
+	Version     uint   `json:"version"`
+	TeamIDs     []uint `json:"team_ids"`
+}
+
+// snapshot captures the JSON state of an entity at the time of the call, so later changes to it are not recorded
+func snapshot(entity interface{}) json.RawMessage {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		// Every audited entity is a plain struct, a failure here is a programming error
+		panic(err)
+	}
+	return data
+}
+
+func leagueSnapshot(league *models.League) json.RawMessage {
+	state := leagueState{Name: league.Name, CurrentWeek: league.CurrentWeek, ResultMode: league.ResultMode, Version: league.Version, TeamIDs: []uint{}}
+	for _, team := range league.Teams {
+		state.TeamIDs = append(state.TeamIDs, team.ID)
+	}
+	return snapshot(state)
+}
+
+// recordChange appends an audit entry, before is nil for created entities and after for deleted ones
+func recordChange(audit repositories.AuditRepository, action, entityType string, entityID, leagueID uint, before, after json.RawMessage) error {
+	return audit.RecordEntry(&models.AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		LeagueID:   leagueID,
+		Before:     before,
+		After:      after,
+	})
+}
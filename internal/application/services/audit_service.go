@@ -0,0 +1,29 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+)
+
+// AuditService reads the audit log, entries are written by the services that make the changes
+type AuditService interface {
+	FindEntries(filter repositories.AuditFilter, page repositories.Page) ([]*models.AuditEntry, int64, error)
+	WithScope(scope repositories.Scope) AuditService
+}
+
+type AuditServiceImpl struct {
+	auditRepo repositories.AuditRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &AuditServiceImpl{auditRepo: auditRepo}
+}
+
+// WithScope returns a service that only reads the audit log of the scope's organization
+func (s *AuditServiceImpl) WithScope(scope repositories.Scope) AuditService {
+	return &AuditServiceImpl{auditRepo: s.auditRepo.WithScope(scope)}
+}
+
+func (s *AuditServiceImpl) FindEntries(filter repositories.AuditFilter, page repositories.Page) ([]*models.AuditEntry, int64, error) {
+	return s.auditRepo.FindEntries(filter, page)
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxImportEntries bounds the teams, league members and results of a single import
+const MaxImportEntries = 5000
+
+type ImportService interface {
+	Import(request *dto.ImportRequest) (*dto.ImportReport, error)
+	WithScope(scope repositories.Scope) ImportService
+}
+
+// ImportServiceImpl creates teams and leagues in bulk through the team and league services, bound to one transaction
+type ImportServiceImpl struct {
+	uow repositories.UnitOfWork
+	bus events.Bus
+}
+
+func NewImportService(uow repositories.UnitOfWork, bus events.Bus) ImportService {
+	return &ImportServiceImpl{uow: uow, bus: bus}
+}
+
+// WithScope returns a service that imports into the scope's organization
+func (s *ImportServiceImpl) WithScope(scope repositories.Scope) ImportService {
+	return &ImportServiceImpl{uow: s.uow.WithScope(scope), bus: s.bus}
+}
+
+// outboxBus keeps the events of an import until it has committed
+type outboxBus struct {
+	events []events.Event
+}
+
+func (b *outboxBus) Publish(published ...events.Event) {
+	b.events = append(b.events, published...)
+}
+
+func (b *outboxBus) Subscribe(events.Handler) func() {
+	return func() {}
+}
+
+// Import validates the whole import first and fails with every error it finds, each naming its entry. A valid
+// import is applied all-or-nothing: the teams are created, then each league with its teams and results.
+func (s *ImportServiceImpl) Import(request *dto.ImportRequest) (*dto.ImportReport, error) {
+	if err := s.validate(request); err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImportReport{Teams: []*models.Team{}, Leagues: []*models.League{}}
+	outbox := &outboxBus{}
+	err := s.uow.Transaction(func(tx repositories.UnitOfWork) error {
+		teams := NewTeamService(tx.Teams(), tx.Leagues(), tx)
+		leagues := NewLeagueService(tx.Leagues(), tx.Teams(), tx.Matches(), tx.Standings(), tx, outbox)
+
+		teamIDs := map[string]uint{}
+		for _, imported := range request.Teams {
+			team := &models.Team{
+				Name:            strings.TrimSpace(imported.Name),
+				AttackStrength:  *imported.AttackStrength,
+				DefenseStrength: *imported.DefenseStrength,
+				HomeVenueID:     imported.HomeVenueID,
+			}
+			if err := teams.CreateTeam(team); err != nil {
+				return err
+			}
+			teamIDs[strings.ToLower(team.Name)] = team.ID
+			report.Teams = append(report.Teams, team)
+		}
+		teamID := func(name string) (uint, error) {
+			if id, ok := teamIDs[strings.ToLower(strings.TrimSpace(name))]; ok {
+				return id, nil
+			}
+			team, err := teams.GetTeamByName(name)
+			if err != nil {
+				return 0, err
+			}
+			teamIDs[strings.ToLower(team.Name)] = team.ID
+			return team.ID, nil
+		}
+
+		for _, imported := range request.Leagues {
+			league := &models.League{Name: strings.TrimSpace(imported.Name), ResultMode: imported.ResultMode}
+			if err := leagues.CreateLeague(league); err != nil {
+				return err
+			}
+			for _, name := range imported.Teams {
+				id, err := teamID(name)
+				if err != nil {
+					return err
+				}
+				if err := leagues.AddTeamToLeague(league.ID, id); err != nil {
+					return err
+				}
+			}
+
+			if len(imported.Results) > 0 {
+				results := make([]models.Match, len(imported.Results))
+				for i, result := range imported.Results {
+					home, err := teamID(result.HomeTeam)
+					if err != nil {
+						return err
+					}
+					away, err := teamID(result.AwayTeam)
+					if err != nil {
+						return err
+					}
+					results[i] = models.Match{
+						HomeTeamID:    home,
+						AwayTeamID:    away,
+						HomeTeamScore: result.HomeTeamScore,
+						AwayTeamScore: result.AwayTeamScore,
+						Week:          result.Week,
+					}
+				}
+				if err := leagues.ImportResults(league.ID, results); err != nil {
+					return err
+				}
+				report.Results += len(results)
+			}
+
+			created, err := leagues.GetLeagueByID(league.ID)
+			if err != nil {
+				return err
+			}
+			report.Leagues = append(report.Leagues, created)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.bus != nil {
+		s.bus.Publish(outbox.events...)
+	}
+	return report, nil
+}
+
+// validate checks the whole import against the data already stored, the teams as the team service would
+func (s *ImportServiceImpl) validate(request *dto.ImportRequest) error {
+	var fields []apperrors.FieldError
+	fail := func(entry, message string) {
+		fields = append(fields, apperrors.FieldError{Field: entry, Message: message})
+	}
+
+	entries := len(request.Teams)
+	for _, league := range request.Leagues {
+		entries += 1 + len(league.Teams) + len(league.Results)
+	}
+	if entries > MaxImportEntries {
+		return apperrors.Validation("validation_failed", "an import holds at most %d entries, this one has %d", MaxImportEntries, entries)
+	}
+
+	teams := &TeamServiceImpl{teamRepo: s.uow.Teams(), leagueRepo: s.uow.Leagues(), auditRepo: s.uow.Audit(), uow: s.uow}
+	imported := map[string]bool{}
+	for i, team := range request.Teams {
+		entry := sourceOf(team.Source, "teams[%d]", i)
+		if team.AttackStrength == nil {
+			fail(entry+".attack_strength", "is required")
+		}
+		if team.DefenseStrength == nil {
+			fail(entry+".defense_strength", "is required")
+		}
+		if team.AttackStrength == nil || team.DefenseStrength == nil {
+			continue
+		}
+
+		candidate := &models.Team{Name: team.Name, AttackStrength: *team.AttackStrength, DefenseStrength: *team.DefenseStrength, HomeVenueID: team.HomeVenueID}
+		err := teams.validateTeam(candidate)
+		if err != nil && !errors.Is(err, apperrors.ErrValidation) && !errors.Is(err, apperrors.ErrConflict) {
+			return err
+		}
+		for _, field := range apperrors.FieldsOf(err) {
+			fail(entry+"."+field.Field, field.Message)
+		}
+		if candidate.Name == "" {
+			continue
+		}
+		if imported[strings.ToLower(candidate.Name)] {
+			fail(entry+".name", fmt.Sprintf("%q is imported twice", candidate.Name))
+		}
+		imported[strings.ToLower(candidate.Name)] = true
+	}
+
+	// Teams that are not imported must exist already
+	known := func(name string) (bool, error) {
+		name = strings.TrimSpace(name)
+		if imported[strings.ToLower(name)] {
+			return true, nil
+		}
+		_, err := teams.GetTeamByName(name)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	for i, league := range request.Leagues {
+		entry := sourceOf(league.Source, "leagues[%d]", i)
+		if name := strings.TrimSpace(league.Name); name == "" {
+			fail(entry+".name", "is required")
+		} else if len(name) > 100 {
+			fail(entry+".name", "must be at most 100 characters long")
+		}
+		if league.ResultMode != "" && league.ResultMode != models.ResultModeSimulated && league.ResultMode != models.ResultModeManual {
+			fail(entry+".result_mode", "must be one of simulated, manual")
+		}
+
+		members := map[string]bool{}
+		for j, name := range league.Teams {
+			member := entry + fmt.Sprintf(".teams[%d]", j)
+			if j < len(league.TeamSources) && league.TeamSources[j] != "" {
+				member = league.TeamSources[j]
+			}
+			ok, err := known(name)
+			if err != nil {
+				return err
+			}
+			switch key := strings.ToLower(strings.TrimSpace(name)); {
+			case !ok:
+				fail(member, fmt.Sprintf("team %q is neither imported nor existing", name))
+			case members[key]:
+				fail(member, fmt.Sprintf("team %q is listed twice", name))
+			case len(members) == 4:
+				fail(member, "a league has at most 4 teams")
+			default:
+				members[key] = true
+			}
+		}
+
+		if len(league.Results) > 0 && len(league.Teams) != 4 {
+			fail(entry+".results", "a league needs exactly 4 teams to have results")
+		}
+		playing := map[string]bool{}
+		for j, result := range league.Results {
+			resultEntry := sourceOf(result.Source, entry+".results[%d]", j)
+			if result.Week < 1 || result.Week > models.TotalWeeks {
+				fail(resultEntry+".week", fmt.Sprintf("must be between 1 and %d", models.TotalWeeks))
+			}
+			home, away := strings.ToLower(strings.TrimSpace(result.HomeTeam)), strings.ToLower(strings.TrimSpace(result.AwayTeam))
+			if !members[home] {
+				fail(resultEntry+".home_team", fmt.Sprintf("team %q is not in the league", result.HomeTeam))
+			}
+			if !members[away] {
+				fail(resultEntry+".away_team", fmt.Sprintf("team %q is not in the league", result.AwayTeam))
+			} else if away == home {
+				fail(resultEntry+".away_team", "must be another team than the home team")
+			}
+			for _, score := range []struct {
+				field string
+				value int
+			}{{"home_team_score", result.HomeTeamScore}, {"away_team_score", result.AwayTeamScore}} {
+				if score.value < 0 || score.value > 99 {
+					fail(resultEntry+"."+score.field, "must be between 0 and 99")
+				}
+			}
+			for _, team := range []string{home, away} {
+				key := fmt.Sprintf("%s@%d", team, result.Week)
+				if playing[key] {
+					fail(resultEntry+".week", fmt.Sprintf("team %q already plays in week %d", team, result.Week))
+				}
+				playing[key] = true
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "the import has %d errors, nothing was imported", len(fields)).WithFields(fields...)
+	}
+	return nil
+}
+
+// sourceOf names an entry of an import by its source, or by its path in the request when it has none
+func sourceOf(source, path string, index int) string {
+	if source != "" {
+		return source
+	}
+	return fmt.Sprintf(path, index)
+}
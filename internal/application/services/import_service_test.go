@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	existing := &models.Team{Name: "Old Town", AttackStrength: 60, DefenseStrength: 60}
+	require.NoError(t, teamService.CreateTeam(existing))
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) { published = append(published, event) })
+	importService := services.NewImportService(repositories.NewUnitOfWork(db), bus)
+
+	strength := func(value int) *int { return &value }
+	team := func(name string) dto.ImportTeam {
+		return dto.ImportTeam{Name: name, AttackStrength: strength(70), DefenseStrength: strength(70)}
+	}
+
+	// Every error is reported and nothing is imported
+	_, err := importService.Import(&dto.ImportRequest{
+		Teams: []dto.ImportTeam{team("North"), team("north"), {Name: "South", AttackStrength: strength(120), DefenseStrength: strength(70)}},
+		Leagues: []dto.ImportLeague{{
+			Name:    "Spring Cup",
+			Teams:   []string{"North", "Old Town", "Nowhere"},
+			Results: []dto.ImportResult{{Week: 1, HomeTeam: "North", AwayTeam: "Old Town", HomeTeamScore: 1}},
+		}},
+	})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	fields := map[string]bool{}
+	for _, field := range apperrors.FieldsOf(err) {
+		fields[field.Field] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"teams[1].name":            true,
+		"teams[2].attack_strength": true,
+		"leagues[0].teams[2]":      true,
+		"leagues[0].results":       true,
+	}, fields)
+	teams, err := teamService.GetAllTeams()
+	require.NoError(t, err)
+	assert.Len(t, teams, 1)
+
+	report, err := importService.Import(&dto.ImportRequest{
+		Teams: []dto.ImportTeam{team("North"), team("South"), team("East")},
+		Leagues: []dto.ImportLeague{
+			{Name: "Winter Cup", ResultMode: models.ResultModeManual, Teams: []string{"North", "South"}},
+			{
+				Name:  "Spring Cup",
+				Teams: []string{"North", "South", "East", "Old Town"},
+				Results: []dto.ImportResult{
+					{Week: 1, HomeTeam: "North", AwayTeam: "South", HomeTeamScore: 2, AwayTeamScore: 0},
+					{Week: 1, HomeTeam: "East", AwayTeam: "Old Town", HomeTeamScore: 1, AwayTeamScore: 1},
+					{Week: 2, HomeTeam: "South", AwayTeam: "East", HomeTeamScore: 0, AwayTeamScore: 3},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Teams, 3)
+	require.Len(t, report.Leagues, 2)
+	assert.Equal(t, 3, report.Results)
+
+	winter := report.Leagues[0]
+	assert.Equal(t, models.ResultModeManual, winter.ResultMode)
+	assert.Len(t, winter.Teams, 2)
+	assert.Equal(t, 0, winter.CurrentWeek)
+
+	// The results are the first weeks of the league, which continues after them
+	spring := report.Leagues[1]
+	assert.Equal(t, 3, spring.CurrentWeek)
+	standings, err := leagueService.GetStandings(spring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, report.Teams[2].ID, standings[0].TeamID, "East has won and drawn")
+	assert.Equal(t, 4, standings[0].Points)
+	consistency, err := leagueService.CheckStandings(spring.ID, false)
+	require.NoError(t, err)
+	assert.True(t, consistency.Consistent)
+
+	var unplayed []*models.Match
+	require.NoError(t, db.Where("league_id = ? AND status <> ?", spring.ID, models.MatchStatusPlayed).Find(&unplayed).Error)
+	assert.NotEmpty(t, unplayed)
+	for _, match := range unplayed {
+		assert.GreaterOrEqual(t, match.Week, 3)
+	}
+	require.NoError(t, leagueService.AdvanceWeek(spring.ID))
+
+	// The events are published once the import has committed
+	types := map[string]int{}
+	for _, event := range published {
+		types[event.Type]++
+	}
+	assert.Equal(t, 6, types[events.TeamAdded])
+	assert.Equal(t, 1, types[events.LeagueStarted])
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetCalendar dates the weeks of a league and moves the kickoffs of its unplayed matches along, except those set
+// for a single match
+func (s *LeagueServiceImpl) SetCalendar(leagueID uint, request *dto.CalendarRequest, expected ...dto.LeaguePrecondition) (*models.League, error) {
+	calendar, err := calendarOf(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var league *models.League
+	err = s.inTransaction(func(tx *LeagueServiceImpl) error {
+		league, err = tx.leagueRepo.GetLeagueByID(leagueID)
+		if err != nil {
+			return err
+		}
+		if err := checkPreconditions(league, expected); err != nil {
+			return err
+		}
+		before := snapshot(league.Calendar)
+
+		league.Calendar = calendar
+		if err := tx.leagueRepo.UpdateLeague(league); err != nil {
+			return err
+		}
+
+		matches, err := tx.matchRepo.GetMatchesByLeague(leagueID)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			if match.Status != models.MatchStatusScheduled || match.KickoffFixed {
+				continue
+			}
+			if match.KickoffAt, err = calendar.KickoffOf(match.Week); err != nil {
+				return err
+			}
+			if err := tx.matchRepo.UpdateMatch(match); err != nil {
+				return err
+			}
+		}
+		return tx.recordLeagueChange(AuditCalendarSet, league, before, snapshot(league.Calendar))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return league, nil
+}
+
+// SetMatchKickoff moves the kickoff of an unplayed match. A nil kickoff puts the match back on its league's calendar.
+func (s *LeagueServiceImpl) SetMatchKickoff(matchID uint, kickoff *time.Time) (*models.Match, error) {
+	var match *models.Match
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		match, err = tx.matchRepo.GetMatchByID(matchID)
+		if err != nil {
+			return err
+		}
+		switch match.Status {
+		case models.MatchStatusPlayed:
+			return apperrors.PreconditionFailed("match_already_played", "match %d has already been played", matchID)
+		case models.MatchStatusPostponed:
+			return apperrors.PreconditionFailed("match_postponed", "match %d is postponed, it gets a kickoff when it is rescheduled", matchID)
+		}
+		before := snapshot(match)
+
+		if kickoff != nil {
+			at := kickoff.UTC()
+			match.KickoffAt = &at
+			match.KickoffFixed = true
+		} else {
+			league, err := tx.leagueRepo.GetLeagueByID(match.LeagueID)
+			if err != nil {
+				return err
+			}
+			if match.KickoffAt, err = league.Calendar.KickoffOf(match.Week); err != nil {
+				return err
+			}
+			match.KickoffFixed = false
+		}
+
+		if err := tx.matchRepo.UpdateMatch(match); err != nil {
+			return err
+		}
+		return recordChange(tx.auditRepo, AuditMatchKickoffSet, models.AuditEntityMatch, match.ID, match.LeagueID, before, snapshot(match))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// GetLeagueFixtures returns every match of a league, played or not, for its calendar feed
+func (s *LeagueServiceImpl) GetLeagueFixtures(leagueID uint) (*dto.Fixtures, error) {
+	league, err := s.leagueRepo.GetLeagueByID(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.matchRepo.GetMatchesByLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := &dto.Fixtures{
+		Name:        league.Name,
+		Matches:     matches,
+		TeamNames:   map[uint]string{},
+		LeagueNames: map[uint]string{league.ID: league.Name},
+		Venues:      map[uint]*models.Venue{},
+	}
+	for _, team := range league.Teams {
+		fixtures.TeamNames[team.ID] = team.Name
+	}
+	return fixtures, s.nameFixtures(fixtures)
+}
+
+// GetTeamFixtures returns every match of a team in all of its leagues, for its calendar feed
+func (s *LeagueServiceImpl) GetTeamFixtures(teamID uint) (*dto.Fixtures, error) {
+	team, err := s.teamRepo.GetTeamByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.matchRepo.GetMatchesByTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := &dto.Fixtures{
+		Name:        team.Name,
+		Matches:     matches,
+		TeamNames:   map[uint]string{team.ID: team.Name},
+		LeagueNames: map[uint]string{},
+		Venues:      map[uint]*models.Venue{},
+	}
+	return fixtures, s.nameFixtures(fixtures)
+}
+
+// nameFixtures looks up the names of the teams and leagues and the venues of the fixtures that are not known yet.
+// Teams and leagues deleted since are named after their ID.
+func (s *LeagueServiceImpl) nameFixtures(fixtures *dto.Fixtures) error {
+	for _, match := range fixtures.Matches {
+		if match.VenueID != nil {
+			if _, ok := fixtures.Venues[*match.VenueID]; !ok {
+				venue, err := s.venueOf(match)
+				if err != nil {
+					return err
+				}
+				if venue != nil {
+					fixtures.Venues[venue.ID] = venue
+				}
+			}
+		}
+		if _, ok := fixtures.LeagueNames[match.LeagueID]; !ok {
+			league, err := s.leagueRepo.GetLeagueByID(match.LeagueID)
+			switch {
+			case err == nil:
+				fixtures.LeagueNames[league.ID] = league.Name
+			case errors.Is(err, apperrors.ErrNotFound):
+				fixtures.LeagueNames[match.LeagueID] = fmt.Sprintf("League %d", match.LeagueID)
+			default:
+				return err
+			}
+		}
+		for _, teamID := range []uint{match.HomeTeamID, match.AwayTeamID} {
+			if _, ok := fixtures.TeamNames[teamID]; ok {
+				continue
+			}
+			team, err := s.teamRepo.GetTeamByID(teamID)
+			switch {
+			case err == nil:
+				fixtures.TeamNames[team.ID] = team.Name
+			case errors.Is(err, apperrors.ErrNotFound):
+				fixtures.TeamNames[teamID] = fmt.Sprintf("Team %d", teamID)
+			default:
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// calendarOf checks a calendar request and fills in its defaults
+func calendarOf(request *dto.CalendarRequest) (models.LeagueCalendar, error) {
+	calendar := models.LeagueCalendar{
+		StartDate:            strings.TrimSpace(request.StartDate),
+		KickoffTime:          strings.TrimSpace(request.KickoffTime),
+		Timezone:             strings.TrimSpace(request.Timezone),
+		MatchdayIntervalDays: request.MatchdayIntervalDays,
+	}
+	if calendar.StartDate == "" {
+		return models.LeagueCalendar{}, nil
+	}
+	if calendar.Timezone == "" {
+		calendar.Timezone = "UTC"
+	}
+	if calendar.MatchdayIntervalDays == 0 {
+		calendar.MatchdayIntervalDays = models.DefaultMatchdayIntervalDays
+	}
+
+	var fields []apperrors.FieldError
+	if _, err := time.Parse("2006-01-02", calendar.StartDate); err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "start_date", Message: "must be a date such as 2024-08-17"})
+	}
+	if _, _, err := models.ParseTimeOfDay(calendar.KickoffTime); err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "kickoff_time", Message: "must be a 24-hour time such as 15:00"})
+	}
+	if _, err := time.LoadLocation(calendar.Timezone); err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "timezone", Message: "must be an IANA time zone such as Europe/London"})
+	}
+	if len(fields) > 0 {
+		return models.LeagueCalendar{}, apperrors.Validation("validation_failed", "invalid calendar").WithFields(fields...)
+	}
+	return calendar, nil
+}
@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeagueCalendar(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	league := createTestLeagueForService(leagueService, teamService)
+
+	// The whole season is drawn when the league starts
+	require.NoError(t, leagueService.StartLeague(league.ID))
+	var fixtures []*models.Match
+	require.NoError(t, db.Where("league_id = ?", league.ID).Order("week, id").Find(&fixtures).Error)
+	require.Len(t, fixtures, 2*models.TotalWeeks)
+	for _, fixture := range fixtures {
+		assert.Equal(t, models.MatchStatusScheduled, fixture.Status)
+		assert.Nil(t, fixture.KickoffAt, "no kickoff without a calendar")
+	}
+
+	_, err := leagueService.SetCalendar(league.ID, &dto.CalendarRequest{StartDate: "17/08/2024", KickoffTime: "15:00"})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	dated, err := leagueService.SetCalendar(league.ID, &dto.CalendarRequest{StartDate: "2024-08-17", KickoffTime: "15:00", Timezone: "Europe/London"})
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultMatchdayIntervalDays, dated.Calendar.MatchdayIntervalDays)
+
+	week1 := time.Date(2024, 8, 17, 14, 0, 0, 0, time.UTC)
+	match, err := leagueService.GetMatchByID(fixtures[2].ID) // The first match of week 2
+	require.NoError(t, err)
+	assert.Equal(t, week1.AddDate(0, 0, 7), *match.KickoffAt)
+
+	// A kickoff set for a single match stays put when the calendar moves
+	moved := time.Date(2024, 8, 25, 12, 30, 0, 0, time.UTC)
+	match, err = leagueService.SetMatchKickoff(match.ID, &moved)
+	require.NoError(t, err)
+	assert.True(t, match.KickoffFixed)
+
+	_, err = leagueService.SetCalendar(league.ID, &dto.CalendarRequest{StartDate: "2024-08-18", KickoffTime: "15:00", Timezone: "Europe/London"})
+	require.NoError(t, err)
+	match, err = leagueService.GetMatchByID(match.ID)
+	require.NoError(t, err)
+	assert.Equal(t, moved, *match.KickoffAt)
+	other, err := leagueService.GetMatchByID(fixtures[3].ID)
+	require.NoError(t, err)
+	assert.Equal(t, week1.AddDate(0, 0, 8), *other.KickoffAt)
+
+	// Clearing the override puts the match back on the calendar
+	match, err = leagueService.SetMatchKickoff(match.ID, nil)
+	require.NoError(t, err)
+	assert.False(t, match.KickoffFixed)
+	assert.Equal(t, week1.AddDate(0, 0, 8), *match.KickoffAt)
+
+	// Playing a week fills in the stored fixtures, which keep their kickoff
+	require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	played, err := leagueService.GetMatchByID(fixtures[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPlayed, played.Status)
+	assert.Equal(t, week1.AddDate(0, 0, 1), *played.KickoffAt)
+	var count int64
+	require.NoError(t, db.Model(&models.Match{}).Where("league_id = ?", league.ID).Count(&count).Error)
+	assert.Equal(t, int64(2*models.TotalWeeks), count)
+
+	_, err = leagueService.SetMatchKickoff(played.ID, &moved)
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+
+	// Changing the teams of a running league draws its remaining fixtures again
+	removed := league.Teams[3]
+	require.NoError(t, leagueService.RemoveTeamFromLeague(league.ID, removed.ID))
+	require.NoError(t, db.Model(&models.Match{}).Where("league_id = ? AND status = ?", league.ID, models.MatchStatusScheduled).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, leagueService.AddTeamToLeague(league.ID, removed.ID))
+	require.NoError(t, db.Model(&models.Match{}).Where("league_id = ? AND status = ?", league.ID, models.MatchStatusScheduled).Count(&count).Error)
+	assert.Equal(t, int64(2*(models.TotalWeeks-1)), count)
+
+	// Team feeds hold the team's matches along with the names of both sides
+	teamFixtures, err := leagueService.GetTeamFixtures(removed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, removed.Name, teamFixtures.Name)
+	assert.Len(t, teamFixtures.Matches, models.TotalWeeks)
+	assert.Equal(t, league.Name, teamFixtures.LeagueNames[league.ID])
+	assert.Len(t, teamFixtures.TeamNames, 4)
+}
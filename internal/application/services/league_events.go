@@ -0,0 +1,66 @@
+package services
+
+import (
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"time"
+)
+
+// The data of the events published by the league service
+
+// leagueEventData is the data of the league.started and league.season_finished events
+type leagueEventData struct {
+	Name        string `json:"name"`
+	CurrentWeek int    `json:"current_week"`
+}
+
+// weekEventData is the data of the league.week_advanced event, Week is the week that was just played
+// and Standings the ranked table after it
+type weekEventData struct {
+	Week      int                `json:"week"`
+	Matches   []models.Match     `json:"matches"`
+	Standings []*models.Standing `json:"standings"`
+}
+
+// teamEventData is the data of the league.team_added and league.team_removed events
+type teamEventData struct {
+	TeamID   uint   `json:"team_id"`
+	TeamName string `json:"team_name"`
+}
+
+// matchEditedEventData is the data of the match.result_edited event, with the ranked table after the edit
+type matchEditedEventData struct {
+	Match             *models.Match      `json:"match"`
+	PreviousHomeScore int                `json:"previous_home_team_score"`
+	PreviousAwayScore int                `json:"previous_away_team_score"`
+	Standings         []*models.Standing `json:"standings"`
+}
+
+// resultEventData is the data of the match.result_submitted event, with the ranked table after the result
+type resultEventData struct {
+	Match     *models.Match      `json:"match"`
+	Standings []*models.Standing `json:"standings"`
+}
+
+// liveWeekEventData is the data of the league.live_week_started event
+type liveWeekEventData struct {
+	Week            int             `json:"week"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	EndsAt          time.Time       `json:"ends_at"`
+	Matches         []dto.LiveMatch `json:"matches"`
+}
+
+// goalEventData is the data of the match.goal event, sent as a live match reaches the minute of a goal
+type goalEventData struct {
+	Week       int  `json:"week"`
+	HomeTeamID uint `json:"home_team_id"`
+	AwayTeamID uint `json:"away_team_id"`
+	dto.LiveGoal
+}
+
+// matchMovedEventData is the data of the match.postponed and match.rescheduled events, PreviousWeek is the week the
+// match was in before
+type matchMovedEventData struct {
+	Match        *models.Match `json:"match"`
+	PreviousWeek int           `json:"previous_week"`
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"LeagueManager/internal/application/fixtures"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"fmt"
+)
+
+// drawFixtures draws the whole season of the league from its constraints, without saving it. The matches are
+// dated by the league calendar and played at the home team's ground.
+func (s *LeagueServiceImpl) drawFixtures(league *models.League) ([]models.Match, error) {
+	constraints, err := s.fixtureConstraints(league)
+	if err != nil {
+		return nil, err
+	}
+
+	teamIDs := make([]uint, len(league.Teams))
+	grounds := make(map[uint]*uint, len(league.Teams))
+	for i, team := range league.Teams {
+		teamIDs[i] = team.ID
+		grounds[team.ID] = team.HomeVenueID
+	}
+
+	matches := fixtures.Schedule(teamIDs, models.TotalWeeks, constraints)
+	for i := range matches {
+		matches[i].LeagueID = league.ID
+		matches[i].Status = models.MatchStatusScheduled
+		matches[i].VenueID = grounds[matches[i].HomeTeamID]
+		if matches[i].KickoffAt, err = league.Calendar.KickoffOf(matches[i].Week); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// fixtureConstraints returns the stored constraints of the league, followed by a shared venue constraint for every
+// two of its teams that have the same ground
+func (s *LeagueServiceImpl) fixtureConstraints(league *models.League) ([]models.FixtureConstraint, error) {
+	stored, err := s.constraintRepo.GetConstraintsByLeague(league.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make([]models.FixtureConstraint, 0, len(stored))
+	for _, constraint := range stored {
+		constraints = append(constraints, *constraint)
+	}
+	for i, team := range league.Teams {
+		for _, other := range league.Teams[i+1:] {
+			if team.HomeVenueID != nil && sameVenue(team.HomeVenueID, other.HomeVenueID) {
+				constraints = append(constraints, models.FixtureConstraint{
+					LeagueID:    league.ID,
+					Kind:        models.ConstraintSharedVenue,
+					TeamID:      team.ID,
+					OtherTeamID: other.ID,
+				})
+			}
+		}
+	}
+	return constraints, nil
+}
+
+// scheduleFixtures stores the fixtures of the league from fromWeek to the end of the season
+func (s *LeagueServiceImpl) scheduleFixtures(league *models.League, fromWeek int) error {
+	matches, err := s.drawFixtures(league)
+	if err != nil {
+		return err
+	}
+	for i := range matches {
+		if matches[i].Week < fromWeek {
+			continue
+		}
+		if err := s.matchRepo.CreateMatch(&matches[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rescheduleFixtures draws the remaining fixtures of an active league again after its teams or constraints changed.
+// Kickoffs and venues set for single matches are lost, and a league without 4 teams has no fixtures until it has
+// again. Matches postponed from a week already played are not drawn again, they are kept while both teams stay.
+func (s *LeagueServiceImpl) rescheduleFixtures(league *models.League) error {
+	if !league.IsActive() {
+		return nil
+	}
+	matches, err := s.matchRepo.GetMatchesByLeague(league.ID)
+	if err != nil {
+		return err
+	}
+	members := make(map[uint]bool, len(league.Teams))
+	for _, team := range league.Teams {
+		members[team.ID] = true
+	}
+	var carried []uint
+	for _, match := range matches {
+		if match.Status != models.MatchStatusPlayed && match.OriginalWeek > 0 && match.OriginalWeek < league.CurrentWeek &&
+			members[match.HomeTeamID] && members[match.AwayTeamID] {
+			carried = append(carried, match.ID)
+		}
+	}
+	if err := s.matchRepo.DeleteUnplayedMatches(league.ID, carried...); err != nil {
+		return err
+	}
+	if len(league.Teams) != 4 {
+		return nil
+	}
+	return s.scheduleFixtures(league, league.CurrentWeek)
+}
+
+// currentFixtures returns the matches of the league's current week that are still to be played, postponed ones
+// excluded. Leagues started before fixtures were stored have none, theirs are drawn on the fly.
+func (s *LeagueServiceImpl) currentFixtures(league *models.League) ([]models.Match, error) {
+	stored, err := s.matchRepo.GetMatchesByWeek(league.ID, league.CurrentWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	var current []models.Match
+	for _, match := range stored {
+		if match.Status == models.MatchStatusScheduled {
+			current = append(current, *match)
+		}
+	}
+	if len(stored) > 0 {
+		return current, nil
+	}
+
+	drawn, err := s.drawFixtures(league)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range drawn {
+		if match.Week == league.CurrentWeek {
+			current = append(current, match)
+		}
+	}
+	return current, nil
+}
+
+// GetFixtureConstraints returns the stored fixture constraints of a league
+func (s *LeagueServiceImpl) GetFixtureConstraints(leagueID uint) ([]*models.FixtureConstraint, error) {
+	if _, err := s.leagueRepo.GetLeagueByID(leagueID); err != nil {
+		return nil, err
+	}
+	return s.constraintRepo.GetConstraintsByLeague(leagueID)
+}
+
+// SetFixtureConstraints replaces the fixture constraints of a league. The unplayed fixtures of an active league are
+// drawn again, the report tells which constraints the fixtures still do not satisfy.
+func (s *LeagueServiceImpl) SetFixtureConstraints(leagueID uint, constraints []models.FixtureConstraint, expected ...dto.LeaguePrecondition) (*dto.FixtureReport, error) {
+	var report *dto.FixtureReport
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		league, err := tx.leagueRepo.GetLeagueByID(leagueID)
+		if err != nil {
+			return err
+		}
+		if err := checkPreconditions(league, expected); err != nil {
+			return err
+		}
+		if err := validateConstraints(league, constraints); err != nil {
+			return err
+		}
+
+		previous, err := tx.constraintRepo.GetConstraintsByLeague(leagueID)
+		if err != nil {
+			return err
+		}
+		before := snapshot(previous)
+
+		replacement := make([]*models.FixtureConstraint, len(constraints))
+		for i := range constraints {
+			replacement[i] = &constraints[i]
+		}
+		if err := tx.constraintRepo.ReplaceConstraints(leagueID, replacement); err != nil {
+			return err
+		}
+		if err := tx.rescheduleFixtures(league); err != nil {
+			return err
+		}
+		if err := tx.recordLeagueChange(AuditConstraintsSet, league, before, snapshot(replacement)); err != nil {
+			return err
+		}
+
+		report, err = tx.fixtureReport(league)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetFixtureReport tells which constraints the fixtures of a league do not satisfy. The fixtures of a league that
+// has not started are not drawn yet, the report is of those it would get if it started now.
+func (s *LeagueServiceImpl) GetFixtureReport(leagueID uint) (*dto.FixtureReport, error) {
+	league, err := s.leagueRepo.GetLeagueByID(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.fixtureReport(league)
+}
+
+func (s *LeagueServiceImpl) fixtureReport(league *models.League) (*dto.FixtureReport, error) {
+	constraints, err := s.fixtureConstraints(league)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.Match
+	drawn := league.CurrentWeek > 0
+	if drawn {
+		stored, err := s.matchRepo.GetMatchesByLeague(league.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range stored {
+			// A postponed match has no week until it is rescheduled
+			if match.Status != models.MatchStatusPostponed {
+				matches = append(matches, *match)
+			}
+		}
+	} else if matches, err = s.drawFixtures(league); err != nil {
+		return nil, err
+	}
+
+	teamIDs := make([]uint, len(league.Teams))
+	for i, team := range league.Teams {
+		teamIDs[i] = team.ID
+	}
+	report := fixtures.Evaluate(matches, teamIDs, constraints)
+	report.LeagueID = league.ID
+	report.Drawn = drawn
+	return report, nil
+}
+
+// validateConstraints checks that the constraints are about teams of the league and weeks of the season, and clears
+// the fields their kind does not use
+func validateConstraints(league *models.League, constraints []models.FixtureConstraint) error {
+	members := make(map[uint]bool, len(league.Teams))
+	for _, team := range league.Teams {
+		members[team.ID] = true
+	}
+
+	var fields []apperrors.FieldError
+	for i := range constraints {
+		constraint := &constraints[i]
+		field := func(name, message string) {
+			fields = append(fields, apperrors.FieldError{Field: fmt.Sprintf("constraints[%d].%s", i, name), Message: message})
+		}
+
+		var pair, dated bool
+		switch constraint.Kind {
+		case models.ConstraintSharedVenue:
+			pair = true
+		case models.ConstraintBlackout:
+			dated = true
+		case models.ConstraintDerby:
+			pair, dated = true, true
+		default:
+			field("kind", "must be one of shared_venue, blackout, derby")
+			continue
+		}
+
+		if !members[constraint.TeamID] {
+			field("team_id", "must be a team of the league")
+		}
+		if !pair {
+			constraint.OtherTeamID = 0
+		} else if !members[constraint.OtherTeamID] || constraint.OtherTeamID == constraint.TeamID {
+			field("other_team_id", "must be another team of the league")
+		}
+		if !dated {
+			constraint.Week = 0
+		} else if constraint.Week < 1 || constraint.Week > models.TotalWeeks {
+			field("week", fmt.Sprintf("must be between 1 and %d", models.TotalWeeks))
+		}
+	}
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid fixture constraints").WithFields(fields...)
+	}
+	return nil
+}
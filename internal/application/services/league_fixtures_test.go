@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtureConstraints(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	require.NoError(t, db.AutoMigrate(&models.Venue{}))
+	league := createTestLeagueForService(leagueService, teamService)
+	league, err := leagueService.GetLeagueByID(league.ID)
+	require.NoError(t, err)
+	a, b, c, d := league.Teams[0], league.Teams[1], league.Teams[2], league.Teams[3]
+
+	// Before the league starts, the report previews the fixtures it would get
+	report, err := leagueService.GetFixtureReport(league.ID)
+	require.NoError(t, err)
+	assert.False(t, report.Drawn)
+	assert.Empty(t, report.Unsatisfied)
+
+	_, err = leagueService.SetFixtureConstraints(league.ID, []models.FixtureConstraint{
+		{Kind: models.ConstraintDerby, TeamID: a.ID, OtherTeamID: a.ID, Week: 40},
+		{Kind: models.ConstraintBlackout, TeamID: 99, Week: 3},
+	})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	var fields []string
+	for _, field := range apperrors.FieldsOf(err) {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"constraints[0].other_team_id", "constraints[0].week", "constraints[1].team_id"}, fields)
+
+	// Teams sharing a ground are never at home in the same week
+	venueService := services.NewVenueService(repositories.NewVenueRepository(db), repositories.NewUnitOfWork(db))
+	ground := &models.Venue{Name: "Shared Ground", Capacity: 40000}
+	require.NoError(t, venueService.CreateVenue(ground))
+	for _, team := range []*models.Team{&a, &b} {
+		team.HomeVenueID = &ground.ID
+		require.NoError(t, teamService.UpdateTeam(team))
+	}
+
+	report, err = leagueService.SetFixtureConstraints(league.ID, []models.FixtureConstraint{
+		{Kind: models.ConstraintDerby, TeamID: c.ID, OtherTeamID: d.ID, Week: 2},
+		{Kind: models.ConstraintBlackout, TeamID: c.ID, Week: 3, OtherTeamID: a.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Constraints, "the shared ground adds a constraint")
+	assert.Empty(t, report.Unsatisfied)
+
+	constraints, err := leagueService.GetFixtureConstraints(league.ID)
+	require.NoError(t, err)
+	require.Len(t, constraints, 2)
+	assert.Zero(t, constraints[1].OtherTeamID, "blackouts are about a single team")
+
+	require.NoError(t, leagueService.StartLeague(league.ID))
+	homeTeams := map[int][]uint{}
+	var derby bool
+	var matches []*models.Match
+	require.NoError(t, db.Where("league_id = ?", league.ID).Find(&matches).Error)
+	for _, match := range matches {
+		homeTeams[match.Week] = append(homeTeams[match.Week], match.HomeTeamID)
+		derby = derby || (match.Week == 2 && (match.HomeTeamID == c.ID || match.HomeTeamID == d.ID) && (match.AwayTeamID == c.ID || match.AwayTeamID == d.ID))
+	}
+	assert.True(t, derby)
+	assert.NotContains(t, homeTeams[3], c.ID)
+	for week, teams := range homeTeams {
+		assert.False(t, assert.ObjectsAreEqual([]uint{a.ID, b.ID}, teams) || assert.ObjectsAreEqual([]uint{b.ID, a.ID}, teams), "week %d", week)
+	}
+
+	// Constraints that cannot all hold are reported, the weeks played stay as they were
+	require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	report, err = leagueService.SetFixtureConstraints(league.ID, []models.FixtureConstraint{
+		{Kind: models.ConstraintDerby, TeamID: a.ID, OtherTeamID: c.ID, Week: 5},
+		{Kind: models.ConstraintDerby, TeamID: a.ID, OtherTeamID: d.ID, Week: 5},
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Drawn)
+	require.Len(t, report.Unsatisfied, 1)
+	assert.Equal(t, []int{5}, report.Unsatisfied[0].Weeks)
+	league, err = leagueService.GetLeagueByID(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, playedMatches(league))
+	assert.Len(t, league.Matches, 2*models.TotalWeeks)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"fmt"
+)
+
+// PostponeMatch calls off an unplayed match. It is skipped when its week is played and stays unplayed until it is
+// rescheduled.
+func (s *LeagueServiceImpl) PostponeMatch(matchID uint) (*models.Match, error) {
+	var match *models.Match
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		match, err = tx.matchRepo.GetMatchByID(matchID)
+		if err != nil {
+			return err
+		}
+		switch match.Status {
+		case models.MatchStatusPlayed:
+			return apperrors.PreconditionFailed("match_already_played", "match %d has already been played", matchID)
+		case models.MatchStatusPostponed:
+			return apperrors.Conflict("match_already_postponed", "match %d is already postponed", matchID)
+		}
+		league, err := tx.leagueRepo.GetLeagueByID(match.LeagueID)
+		if err != nil {
+			return err
+		}
+		return tx.postponeMatch(league, match)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// postponeMatch postpones a scheduled match of the league, and records and announces it
+func (s *LeagueServiceImpl) postponeMatch(league *models.League, match *models.Match) error {
+	before := snapshot(match)
+	if match.OriginalWeek == 0 {
+		match.OriginalWeek = match.Week
+	}
+	match.Status = models.MatchStatusPostponed
+	if err := s.matchRepo.UpdateMatch(match); err != nil {
+		return err
+	}
+	if err := recordChange(s.auditRepo, AuditMatchPostponed, models.AuditEntityMatch, match.ID, match.LeagueID, before, snapshot(match)); err != nil {
+		return err
+	}
+	s.publishLeagueEvent(events.MatchPostponed, league, matchMovedEventData{Match: match, PreviousWeek: match.Week})
+	return nil
+}
+
+// RescheduleMatch moves an unplayed match, postponed or not, into a week of its league that has not been played yet.
+// The match is played with the other matches of that week, so its teams may have played a different number of
+// matches until then.
+func (s *LeagueServiceImpl) RescheduleMatch(matchID uint, request *dto.RescheduleRequest) (*models.Match, error) {
+	var match *models.Match
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		match, err = tx.matchRepo.GetMatchByID(matchID)
+		if err != nil {
+			return err
+		}
+		if match.Status == models.MatchStatusPlayed {
+			return apperrors.PreconditionFailed("match_already_played", "match %d has already been played", matchID)
+		}
+		league, err := tx.leagueRepo.GetLeagueByID(match.LeagueID)
+		if err != nil {
+			return err
+		}
+		if !league.IsActive() {
+			return apperrors.PreconditionFailed("league_not_active", "league %d is not active", league.ID)
+		}
+
+		week, err := rescheduledWeek(league, request)
+		if err != nil {
+			return err
+		}
+		before := snapshot(match)
+		previousWeek := match.Week
+
+		if match.OriginalWeek == 0 {
+			match.OriginalWeek = match.Week
+		}
+		match.Week = week
+		match.Status = models.MatchStatusScheduled
+		if request.KickoffAt != nil {
+			at := request.KickoffAt.UTC()
+			match.KickoffAt = &at
+			match.KickoffFixed = true
+		} else {
+			if match.KickoffAt, err = league.Calendar.KickoffOf(week); err != nil {
+				return err
+			}
+			match.KickoffFixed = false
+		}
+
+		if err := tx.matchRepo.UpdateMatch(match); err != nil {
+			return err
+		}
+		if err := recordChange(tx.auditRepo, AuditMatchRescheduled, models.AuditEntityMatch, match.ID, match.LeagueID, before, snapshot(match)); err != nil {
+			return err
+		}
+		tx.publishLeagueEvent(events.MatchRescheduled, league, matchMovedEventData{Match: match, PreviousWeek: previousWeek})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// rescheduledWeek returns the week a match is rescheduled into, taken from the kickoff on the league's calendar when
+// the request does not name one
+func rescheduledWeek(league *models.League, request *dto.RescheduleRequest) (int, error) {
+	week, field := request.Week, "week"
+	if week == 0 {
+		if request.KickoffAt == nil {
+			return 0, apperrors.Validation("validation_failed", "a week or a kickoff is required").
+				WithFields(apperrors.FieldError{Field: "week", Message: "is required without kickoff_at"})
+		}
+		var err error
+		if week, err = league.Calendar.WeekOf(*request.KickoffAt); err != nil {
+			return 0, err
+		}
+		if week == 0 {
+			return 0, apperrors.Validation("validation_failed", "league %d has no calendar to find the week of the kickoff in", league.ID).
+				WithFields(apperrors.FieldError{Field: "week", Message: "is required when the league has no calendar"})
+		}
+		field = "kickoff_at"
+	}
+
+	if week < league.CurrentWeek || week > models.TotalWeeks {
+		return 0, apperrors.Validation("validation_failed", "week %d has been played or is past the end of the season", week).
+			WithFields(apperrors.FieldError{Field: field, Message: fmt.Sprintf("must be in a week between %d and %d", league.CurrentWeek, models.TotalWeeks)})
+	}
+	return week, nil
+}
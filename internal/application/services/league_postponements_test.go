@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostponeAndRescheduleMatch(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	league := createTestLeagueForService(leagueService, teamService)
+	require.NoError(t, leagueService.StartLeague(league.ID))
+
+	var fixtures []*models.Match
+	require.NoError(t, db.Where("league_id = ? AND week = 1", league.ID).Order("id").Find(&fixtures).Error)
+	require.Len(t, fixtures, 2)
+	called, other := fixtures[0], fixtures[1]
+
+	postponed, err := leagueService.PostponeMatch(called.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPostponed, postponed.Status)
+	assert.Equal(t, 1, postponed.OriginalWeek)
+	_, err = leagueService.PostponeMatch(called.ID)
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	// The postponed match is left unplayed when its week is played
+	require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	postponed, err = leagueService.GetMatchByID(called.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPostponed, postponed.Status)
+	played, err := leagueService.GetMatchByID(other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPlayed, played.Status)
+
+	standings, err := leagueService.GetStandings(league.ID)
+	require.NoError(t, err)
+	for _, standing := range standings {
+		if standing.TeamID == called.HomeTeamID || standing.TeamID == called.AwayTeamID {
+			assert.Zero(t, standing.Played, "team %d has not played", standing.TeamID)
+		} else {
+			assert.Equal(t, 1, standing.Played, "team %d has played", standing.TeamID)
+		}
+	}
+	_, err = leagueService.PostponeMatch(other.ID)
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+	_, err = leagueService.SetMatchKickoff(called.ID, nil)
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+
+	// Redrawing the remaining fixtures keeps a match postponed from a week already played
+	_, err = leagueService.SetFixtureConstraints(league.ID, nil)
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Model(&models.Match{}).Where("league_id = ?", league.ID).Count(&count).Error)
+	assert.Equal(t, int64(2*models.TotalWeeks), count)
+	_, err = leagueService.GetMatchByID(called.ID)
+	require.NoError(t, err)
+
+	for _, request := range []dto.RescheduleRequest{
+		{},                        // Neither a week nor a kickoff
+		{Week: 1},                 // Already played
+		{Week: 39},                // After the season
+		{KickoffAt: &time.Time{}}, // No calendar to find the week in
+	} {
+		_, err = leagueService.RescheduleMatch(called.ID, &request)
+		assert.ErrorIs(t, err, apperrors.ErrValidation, "%+v", request)
+	}
+
+	rescheduled, err := leagueService.RescheduleMatch(called.ID, &dto.RescheduleRequest{Week: 3})
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusScheduled, rescheduled.Status)
+	assert.Equal(t, 3, rescheduled.Week)
+	assert.Equal(t, 1, rescheduled.OriginalWeek)
+
+	// It is played out of order along with the matches of its new week
+	require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	rescheduled, err = leagueService.GetMatchByID(called.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPlayed, rescheduled.Status)
+	assert.Equal(t, 3, rescheduled.Week)
+	standings, err = leagueService.GetStandings(league.ID)
+	require.NoError(t, err)
+	for _, standing := range standings {
+		assert.Equal(t, 3, standing.Played, "team %d caught up", standing.TeamID)
+	}
+	report, err := leagueService.CheckStandings(league.ID, false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Discrepancies)
+
+	// With a calendar, a match can be rescheduled to a date and goes into the week of that date
+	_, err = leagueService.SetCalendar(league.ID, &dto.CalendarRequest{StartDate: "2024-08-17", KickoffTime: "15:00"})
+	require.NoError(t, err)
+	var later models.Match
+	require.NoError(t, db.Where("league_id = ? AND week = 5", league.ID).First(&later).Error)
+	_, err = leagueService.PostponeMatch(later.ID)
+	require.NoError(t, err)
+	midweek := time.Date(2024, 9, 25, 19, 45, 0, 0, time.UTC) // Between the matchdays of weeks 6 and 7
+	rescheduled, err = leagueService.RescheduleMatch(later.ID, &dto.RescheduleRequest{KickoffAt: &midweek})
+	require.NoError(t, err)
+	assert.Equal(t, 7, rescheduled.Week)
+	assert.Equal(t, 5, rescheduled.OriginalWeek)
+	assert.Equal(t, midweek, *rescheduled.KickoffAt)
+	assert.True(t, rescheduled.KickoffFixed)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"encoding/json"
+	"fmt"
+)
+
+// SetResultMode chooses whether the results of a league are simulated when a week is played or entered match by
+// match. It can change at any time, the matches of the current week that already have a result keep it.
+func (s *LeagueServiceImpl) SetResultMode(leagueID uint, mode string, expected ...dto.LeaguePrecondition) (*models.League, error) {
+	if mode != models.ResultModeSimulated && mode != models.ResultModeManual {
+		return nil, apperrors.Validation("validation_failed", "unknown result mode %q", mode).
+			WithFields(apperrors.FieldError{Field: "result_mode", Message: "must be one of simulated, manual"})
+	}
+
+	var league *models.League
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		league, err = tx.leagueRepo.GetLeagueByID(leagueID)
+		if err != nil {
+			return err
+		}
+		if err := checkPreconditions(league, expected); err != nil {
+			return err
+		}
+		before := leagueSnapshot(league)
+
+		league.ResultMode = mode
+		if err := tx.leagueRepo.UpdateLeague(league); err != nil {
+			return err
+		}
+		return tx.recordLeagueChange(AuditResultModeSet, league, before, leagueSnapshot(league))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return league, nil
+}
+
+// SubmitMatchResult enters the result of a match of the current week of a league with manual results. The standings
+// move with it, and the week closes with its last result.
+func (s *LeagueServiceImpl) SubmitMatchResult(matchID uint, result *dto.MatchResultRequest) (*models.Match, error) {
+	if err := validateMatchResult(result); err != nil {
+		return nil, err
+	}
+
+	var match *models.Match
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		match, err = tx.matchRepo.GetMatchByID(matchID)
+		if err != nil {
+			return err
+		}
+		switch match.Status {
+		case models.MatchStatusPlayed:
+			return apperrors.PreconditionFailed("match_already_played", "match %d has already been played, edit its result instead", matchID)
+		case models.MatchStatusPostponed:
+			return apperrors.PreconditionFailed("match_postponed", "match %d is postponed, reschedule it first", matchID)
+		}
+		league, err := tx.leagueRepo.GetLeagueByID(match.LeagueID)
+		if err != nil {
+			return err
+		}
+		if !league.HasManualResults() {
+			return apperrors.PreconditionFailed("league_results_simulated", "the results of league %d are simulated", league.ID)
+		}
+		if match.Week != league.CurrentWeek {
+			return apperrors.PreconditionFailed("match_not_current", "match %d is in week %d, results are taken for week %d", matchID, match.Week, league.CurrentWeek)
+		}
+		before := snapshot(match)
+		leagueBefore := leagueSnapshot(league)
+
+		match.HomeTeamScore = *result.HomeTeamScore
+		match.AwayTeamScore = *result.AwayTeamScore
+		match.Status = models.MatchStatusPlayed
+		if err := tx.saveMatchResult(match); err != nil {
+			return err
+		}
+		if err := recordChange(tx.auditRepo, AuditResultSubmitted, models.AuditEntityMatch, match.ID, match.LeagueID, before, snapshot(match)); err != nil {
+			return err
+		}
+		standings, err := tx.rankedStandings(league.ID)
+		if err != nil {
+			return err
+		}
+		tx.publishLeagueEvent(events.ResultSubmitted, league, resultEventData{Match: match, Standings: standings})
+
+		played, missing, err := tx.weekResults(league)
+		if err != nil || len(missing) > 0 {
+			return err
+		}
+		return tx.completeWeek(league, leagueBefore, played)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// CloseWeek moves a league with manual results past its current week even though some of its matches have no
+// result, those are postponed
+func (s *LeagueServiceImpl) CloseWeek(leagueID uint, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		league, err := tx.advanceableLeague(leagueID, expected)
+		if err != nil {
+			return err
+		}
+		if !league.HasManualResults() {
+			return apperrors.PreconditionFailed("league_results_simulated", "the results of league %d are simulated, advance it instead", league.ID)
+		}
+		return tx.closeWeek(league, leagueSnapshot(league), true)
+	})
+}
+
+// closeWeek moves a league with manual results past its current week once every match of the week has a result. A
+// forced close postpones the matches that have none.
+func (s *LeagueServiceImpl) closeWeek(league *models.League, before json.RawMessage, force bool) error {
+	if league.CurrentWeek < 1 {
+		return apperrors.PreconditionFailed("league_not_started", "league week must be greater than or equal to 1")
+	}
+	played, missing, err := s.weekResults(league)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 && !force {
+		return apperrors.PreconditionFailed("week_incomplete", "%d matches of week %d have no result yet", len(missing), league.CurrentWeek)
+	}
+	for _, match := range missing {
+		if err := s.postponeMatch(league, match); err != nil {
+			return err
+		}
+	}
+	return s.completeWeek(league, before, played)
+}
+
+// weekResults splits the matches of the league's current week into those played and those still without a result,
+// postponed matches are in neither
+func (s *LeagueServiceImpl) weekResults(league *models.League) ([]models.Match, []*models.Match, error) {
+	matches, err := s.matchRepo.GetMatchesByWeek(league.ID, league.CurrentWeek)
+	if err != nil {
+		return nil, nil, err
+	}
+	played := []models.Match{}
+	var missing []*models.Match
+	for _, match := range matches {
+		switch match.Status {
+		case models.MatchStatusPlayed:
+			played = append(played, *match)
+		case models.MatchStatusScheduled:
+			missing = append(missing, match)
+		}
+	}
+	return played, missing, nil
+}
+
+// ImportResults starts a league with results played before it was managed here. The results become its first weeks,
+// the league continues after the last of them and the rest of its season is drawn from there.
+func (s *LeagueServiceImpl) ImportResults(leagueID uint, results []models.Match) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		if err := tx.startLeague(leagueID, nil); err != nil {
+			return err
+		}
+		league, err := tx.leagueRepo.GetLeagueByID(leagueID)
+		if err != nil {
+			return err
+		}
+		if err := validateResults(league, results); err != nil {
+			return err
+		}
+		before := leagueSnapshot(league)
+
+		grounds := make(map[uint]*uint, len(league.Teams))
+		for _, team := range league.Teams {
+			grounds[team.ID] = team.HomeVenueID
+		}
+		for _, result := range results {
+			league.CurrentWeek = max(league.CurrentWeek, result.Week+1)
+		}
+		if err := tx.leagueRepo.UpdateLeague(league); err != nil {
+			return err
+		}
+
+		// The fixtures drawn for the weeks of the results make way for them
+		if err := tx.matchRepo.DeleteUnplayedMatches(league.ID); err != nil {
+			return err
+		}
+		for _, result := range results {
+			match := models.Match{
+				OrganizationID: league.OrganizationID,
+				LeagueID:       league.ID,
+				HomeTeamID:     result.HomeTeamID,
+				AwayTeamID:     result.AwayTeamID,
+				HomeTeamScore:  result.HomeTeamScore,
+				AwayTeamScore:  result.AwayTeamScore,
+				Week:           result.Week,
+				Status:         models.MatchStatusPlayed,
+				VenueID:        grounds[result.HomeTeamID],
+			}
+			if match.KickoffAt, err = league.Calendar.KickoffOf(match.Week); err != nil {
+				return err
+			}
+			if err := tx.saveMatchResult(&match); err != nil {
+				return err
+			}
+		}
+		if league.IsActive() {
+			if err := tx.scheduleFixtures(league, league.CurrentWeek); err != nil {
+				return err
+			}
+		}
+		return tx.recordLeagueChange(AuditResultsImported, league, before, leagueSnapshot(league))
+	})
+}
+
+// validateResults checks that every result is between two teams of the league in a week of the season, and that no
+// team plays twice in a week
+func validateResults(league *models.League, results []models.Match) error {
+	members := make(map[uint]bool, len(league.Teams))
+	for _, team := range league.Teams {
+		members[team.ID] = true
+	}
+	type appearance struct {
+		team uint
+		week int
+	}
+	playing := map[appearance]bool{}
+
+	var fields []apperrors.FieldError
+	for i, result := range results {
+		field := func(name, message string) {
+			fields = append(fields, apperrors.FieldError{Field: fmt.Sprintf("results[%d].%s", i, name), Message: message})
+		}
+		if result.Week < 1 || result.Week > models.TotalWeeks {
+			field("week", fmt.Sprintf("must be between 1 and %d", models.TotalWeeks))
+		}
+		if !members[result.HomeTeamID] {
+			field("home_team_id", "must be a team of the league")
+		}
+		if !members[result.AwayTeamID] || result.AwayTeamID == result.HomeTeamID {
+			field("away_team_id", "must be another team of the league")
+		}
+		if result.HomeTeamScore < 0 || result.AwayTeamScore < 0 {
+			field("home_team_score", "scores must not be negative")
+		}
+		for _, team := range []uint{result.HomeTeamID, result.AwayTeamID} {
+			if playing[appearance{team, result.Week}] {
+				field("week", fmt.Sprintf("team %d already plays in week %d", team, result.Week))
+			}
+			playing[appearance{team, result.Week}] = true
+		}
+	}
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid results").WithFields(fields...)
+	}
+	return nil
+}
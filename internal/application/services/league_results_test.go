@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualResults(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	league := createTestLeagueForService(leagueService, teamService)
+	assert.Equal(t, models.ResultModeSimulated, league.ResultMode)
+
+	_, err := leagueService.SetResultMode(league.ID, "guessed")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	manual, err := leagueService.SetResultMode(league.ID, models.ResultModeManual)
+	require.NoError(t, err)
+	assert.True(t, manual.HasManualResults())
+	require.NoError(t, leagueService.StartLeague(league.ID))
+
+	// Nothing is simulated
+	_, err = leagueService.SimulateWeek(league.ID)
+	assert.Equal(t, "league_results_manual", apperrors.CodeOf(err))
+	assert.Equal(t, "league_results_manual", apperrors.CodeOf(leagueService.PlayAllMatches(league.ID)))
+	assert.Equal(t, "week_incomplete", apperrors.CodeOf(leagueService.AdvanceWeek(league.ID)))
+
+	weekMatches := func(week int) []*models.Match {
+		var matches []*models.Match
+		require.NoError(t, db.Where("league_id = ? AND week = ?", league.ID, week).Order("id").Find(&matches).Error)
+		require.Len(t, matches, 2)
+		return matches
+	}
+	score := func(home, away int) *dto.MatchResultRequest {
+		return &dto.MatchResultRequest{HomeTeamScore: &home, AwayTeamScore: &away}
+	}
+	currentWeek := func() int {
+		reloaded, err := leagueService.GetLeagueByID(league.ID)
+		require.NoError(t, err)
+		return reloaded.CurrentWeek
+	}
+
+	week1 := weekMatches(1)
+	_, err = leagueService.SubmitMatchResult(weekMatches(2)[0].ID, score(1, 0))
+	assert.Equal(t, "match_not_current", apperrors.CodeOf(err))
+	_, err = leagueService.SubmitMatchResult(week1[0].ID, score(-1, 0))
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	submitted, err := leagueService.SubmitMatchResult(week1[0].ID, score(3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPlayed, submitted.Status)
+	assert.Equal(t, 1, currentWeek(), "the week stays open until every result is in")
+	standings, err := leagueService.GetStandings(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, week1[0].HomeTeamID, standings[0].TeamID)
+	assert.Equal(t, 3, standings[0].Points)
+	_, err = leagueService.SubmitMatchResult(week1[0].ID, score(3, 1))
+	assert.Equal(t, "match_already_played", apperrors.CodeOf(err))
+
+	// The last result closes the week
+	_, err = leagueService.SubmitMatchResult(week1[1].ID, score(0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 2, currentWeek())
+
+	// A closed week postpones the matches still without a result
+	week2 := weekMatches(2)
+	_, err = leagueService.SubmitMatchResult(week2[0].ID, score(2, 2))
+	require.NoError(t, err)
+	require.NoError(t, leagueService.CloseWeek(league.ID))
+	assert.Equal(t, 3, currentWeek())
+	unplayed, err := leagueService.GetMatchByID(week2[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusPostponed, unplayed.Status)
+
+	report, err := leagueService.CheckStandings(league.ID, false)
+	require.NoError(t, err)
+	assert.True(t, report.Consistent)
+
+	// Back to simulated results, the week is played as usual and results can no longer be submitted
+	_, err = leagueService.SetResultMode(league.ID, models.ResultModeSimulated)
+	require.NoError(t, err)
+	assert.Equal(t, "league_results_simulated", apperrors.CodeOf(leagueService.CloseWeek(league.ID)))
+	_, err = leagueService.SubmitMatchResult(weekMatches(3)[0].ID, score(1, 0))
+	assert.Equal(t, "league_results_simulated", apperrors.CodeOf(err))
+	require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	assert.Equal(t, 4, currentWeek())
+}
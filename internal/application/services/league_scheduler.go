@@ -0,0 +1,191 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dueSchedulesBatch is the number of due schedules run per round
+const dueSchedulesBatch = 50
+
+// ScheduleReason is recorded in the audit log as the reason of the weeks advanced by a schedule
+const ScheduleReason = "scheduled advancement"
+
+// SchedulerSettings tune how advancement schedules are run
+type SchedulerSettings struct {
+	PollInterval time.Duration // How often the scheduler looks for due schedules
+	// MissedRunTolerance is how late a run may be and still be made by schedules that skip missed runs
+	MissedRunTolerance time.Duration
+}
+
+// DefaultSchedulerSettings returns the settings used when nothing is configured
+func DefaultSchedulerSettings() SchedulerSettings {
+	return SchedulerSettings{
+		PollInterval:       15 * time.Second,
+		MissedRunTolerance: 5 * time.Minute,
+	}
+}
+
+// LeagueScheduler advances leagues on their schedules. A run is claimed in the database before the league is
+// advanced, so after a restart, or with several servers, every run is made at most once.
+type LeagueScheduler struct {
+	schedules repositories.ScheduleRepository
+	leagues   LeagueService
+	settings  SchedulerSettings
+	now       func() time.Time
+	wake      chan struct{}
+}
+
+func NewLeagueScheduler(schedules repositories.ScheduleRepository, leagues LeagueService, settings SchedulerSettings) *LeagueScheduler {
+	return &LeagueScheduler{
+		schedules: schedules,
+		leagues:   leagues,
+		settings:  settings,
+		now:       time.Now,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// SetClock replaces the clock the scheduler decides which runs are due with, for tests
+func (s *LeagueScheduler) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Start runs the scheduler until ctx is done
+func (s *LeagueScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.settings.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+			case <-ticker.C:
+			}
+			if err := s.RunDue(); err != nil {
+				logrus.WithError(err).Error("Failed to run league schedules")
+			}
+		}
+	}()
+}
+
+// notify makes the scheduler look for due schedules without waiting for the next tick
+func (s *LeagueScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunDue makes the runs of every schedule that is due
+func (s *LeagueScheduler) RunDue() error {
+	now := s.now()
+	for {
+		due, err := s.schedules.DueSchedules(now, dueSchedulesBatch)
+		if err != nil {
+			return err
+		}
+		for _, schedule := range due {
+			if err := s.run(schedule, now); err != nil {
+				return err
+			}
+		}
+		if len(due) < dueSchedulesBatch {
+			return nil
+		}
+	}
+}
+
+// run claims the due runs of a schedule and advances its league as many times as its missed run policy asks for
+func (s *LeagueScheduler) run(schedule *models.AdvancementSchedule, now time.Time) error {
+	log := logrus.WithField("league_id", schedule.LeagueID).WithField("schedule_id", schedule.ID)
+
+	next, err := schedule.NextAfter(now)
+	if err != nil {
+		// Only a schedule broken outside of the API gets here, it is paused rather than retried forever
+		log.WithError(err).Error("Pausing a league schedule that cannot be run")
+		return s.schedules.RecordOutcome(schedule.ID, err.Error(), true)
+	}
+
+	runs, err := s.dueRuns(schedule, now, next)
+	if err != nil {
+		return err
+	}
+
+	var ranAt *time.Time
+	if runs > 0 {
+		ranAt = &now
+	}
+	claimed, err := s.schedules.ClaimRun(schedule, next, ranAt)
+	if err != nil || !claimed {
+		// Not claimed: the schedule was changed, paused or run by someone else since it was read
+		return err
+	}
+	if runs == 0 {
+		log.Info("Skipped missed runs of a league schedule")
+		return nil
+	}
+
+	leagues := s.leagues.WithScope(repositories.Scope{
+		OrganizationID: schedule.OrganizationID,
+		Actor:          repositories.SystemActor,
+		Reason:         ScheduleReason,
+	})
+	for i := 0; i < runs; i++ {
+		err := leagues.AdvanceWeek(schedule.LeagueID)
+		switch {
+		case err == nil:
+			continue
+		case apperrors.CodeOf(err) == "league_ended":
+			// Nothing left to advance, the schedule would fail on every run
+			log.Info("Pausing the schedule of a league that has ended")
+			return s.schedules.RecordOutcome(schedule.ID, err.Error(), true)
+		case errors.Is(err, apperrors.ErrNotFound):
+			// Deleting a league deletes its schedule, this one was left by an older version or another server
+			log.Info("Pausing the schedule of a league that no longer exists")
+			return s.schedules.RecordOutcome(schedule.ID, err.Error(), true)
+		default:
+			log.WithError(err).Warn("Failed to advance a league on its schedule")
+			return s.schedules.RecordOutcome(schedule.ID, err.Error(), false)
+		}
+	}
+	log.WithField("weeks", runs).Info("Advanced a league on its schedule")
+	return s.schedules.RecordOutcome(schedule.ID, "", false)
+}
+
+// dueRuns returns how many times a due schedule advances its league now, given next, its first run after now
+func (s *LeagueScheduler) dueRuns(schedule *models.AdvancementSchedule, now, next time.Time) (int, error) {
+	switch schedule.MissedRunPolicy {
+	case models.MissedRunsSkip:
+		latest, err := schedule.RunBefore(next)
+		if err != nil {
+			return 0, err
+		}
+		if now.Sub(latest) > s.settings.MissedRunTolerance {
+			return 0, nil
+		}
+		return 1, nil
+	case models.MissedRunsCatchUp:
+		// No league has more weeks to advance than this, however long the server was down
+		runs := 1
+		for run := schedule.NextRunAt; runs <= models.TotalWeeks; runs++ {
+			var err error
+			if run, err = schedule.NextAfter(run); err != nil {
+				return 0, err
+			}
+			if run.After(now) {
+				break
+			}
+		}
+		return runs, nil
+	default:
+		return 1, nil
+	}
+}
@@ -0,0 +1,205 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schedulerTest adds a scheduler running on the test clock to a webhook test
+type schedulerTest struct {
+	*webhookTest
+	scheduler *services.LeagueScheduler
+	schedules services.ScheduleService
+}
+
+func setupSchedulerTest(t *testing.T) *schedulerTest {
+	test := &schedulerTest{webhookTest: setupWebhookTest(t)}
+	require.NoError(t, test.db.AutoMigrate(&models.AdvancementSchedule{}))
+
+	scheduleRepo := repositories.NewScheduleRepository(test.db)
+	test.scheduler = services.NewLeagueScheduler(scheduleRepo, test.leagues, services.DefaultSchedulerSettings())
+	test.scheduler.SetClock(func() time.Time { return test.now })
+	test.schedules = services.NewScheduleService(scheduleRepo, repositories.NewLeagueRepository(test.db), test.scheduler)
+	return test
+}
+
+// startedLeague creates a started league of the organization
+func (test *schedulerTest) startedLeague(t *testing.T, organizationID uint) *models.League {
+	scope := repositories.Scope{OrganizationID: organizationID}
+	league := createTestLeagueForService(test.leagues.WithScope(scope), test.teams.WithScope(scope))
+	require.NoError(t, test.leagues.WithScope(scope).StartLeague(league.ID))
+	return league
+}
+
+// runAt moves the clock to at and makes the due runs, then returns the current week of the league
+func (test *schedulerTest) runAt(t *testing.T, at time.Time, league *models.League) int {
+	test.now = at
+	require.NoError(t, test.scheduler.RunDue())
+	league, err := test.leagues.WithScope(repositories.Scope{OrganizationID: league.OrganizationID}).GetLeagueByID(league.ID)
+	require.NoError(t, err)
+	return league.CurrentWeek
+}
+
+func TestScheduledAdvancement(t *testing.T) {
+	test := setupSchedulerTest(t)
+	schedules := test.schedules.WithScope(repositories.Scope{OrganizationID: 1})
+	league := test.startedLeague(t, 1)
+	start := test.now
+
+	schedule, err := schedules.SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleInterval, IntervalMinutes: 10})
+	require.NoError(t, err)
+	assert.Equal(t, models.MissedRunsOnce, schedule.MissedRunPolicy)
+	assert.Equal(t, start.Add(10*time.Minute), schedule.NextRunAt)
+
+	assert.Equal(t, 1, test.runAt(t, start.Add(9*time.Minute), league), "not due yet")
+	assert.Equal(t, 2, test.runAt(t, start.Add(10*time.Minute), league))
+	assert.Equal(t, 2, test.runAt(t, start.Add(10*time.Minute), league), "a run is made once")
+
+	// The server was down for three runs, the league advances once and the schedule keeps its rhythm
+	assert.Equal(t, 3, test.runAt(t, start.Add(45*time.Minute), league))
+	schedule, err = schedules.GetSchedule(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(50*time.Minute), schedule.NextRunAt)
+	assert.Equal(t, start.Add(45*time.Minute), *schedule.LastRunAt)
+	assert.Empty(t, schedule.LastError)
+
+	var entry models.AuditEntry
+	require.NoError(t, test.db.Where("action = ?", services.AuditWeekAdvanced).Last(&entry).Error)
+	assert.Equal(t, repositories.SystemActor, entry.Actor)
+	assert.Equal(t, services.ScheduleReason, entry.Reason)
+
+	// Nothing runs while paused, and the runs missed meanwhile are dropped on resume
+	schedule, err = schedules.PauseSchedule(league.ID)
+	require.NoError(t, err)
+	assert.True(t, schedule.Paused)
+	assert.Equal(t, 3, test.runAt(t, start.Add(2*time.Hour), league))
+
+	schedule, err = schedules.ResumeSchedule(league.ID)
+	require.NoError(t, err)
+	assert.False(t, schedule.Paused)
+	assert.Equal(t, start.Add(130*time.Minute), schedule.NextRunAt)
+	assert.Equal(t, 3, test.runAt(t, start.Add(2*time.Hour), league))
+	assert.Equal(t, 4, test.runAt(t, start.Add(130*time.Minute), league))
+
+	require.NoError(t, schedules.DeleteSchedule(league.ID))
+	_, err = schedules.GetSchedule(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.Equal(t, 4, test.runAt(t, start.Add(3*time.Hour), league))
+}
+
+func TestScheduleMissedRunPolicies(t *testing.T) {
+	test := setupSchedulerTest(t)
+	start := test.now
+
+	// One league per organization, since test leagues share their team names
+	leagueWithSchedule := func(organizationID uint, policy string) *models.League {
+		league := test.startedLeague(t, organizationID)
+		_, err := test.schedules.WithScope(repositories.Scope{OrganizationID: organizationID}).
+			SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleInterval, IntervalMinutes: 10, MissedRunPolicy: policy})
+		require.NoError(t, err)
+		return league
+	}
+	catchUp := leagueWithSchedule(1, models.MissedRunsCatchUp)
+	skip := leagueWithSchedule(2, models.MissedRunsSkip)
+
+	// Runs were due at 10, 20 and 30 minutes; the latest is 4 minutes late, within the default tolerance
+	test.now = start.Add(34 * time.Minute)
+	assert.Equal(t, 4, test.runAt(t, test.now, catchUp), "every missed run is made")
+	assert.Equal(t, 2, test.runAt(t, test.now, skip))
+
+	// The run due at 40 minutes is 8 minutes late
+	test.now = start.Add(48 * time.Minute)
+	assert.Equal(t, 5, test.runAt(t, test.now, catchUp))
+	assert.Equal(t, 2, test.runAt(t, test.now, skip), "a run missed long ago is skipped")
+	schedule, err := test.schedules.WithScope(repositories.Scope{OrganizationID: 2}).GetSchedule(skip.ID)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(34*time.Minute), *schedule.LastRunAt)
+	assert.Equal(t, start.Add(50*time.Minute), schedule.NextRunAt)
+}
+
+func TestScheduleOfEndedLeagueIsPaused(t *testing.T) {
+	test := setupSchedulerTest(t)
+	schedules := test.schedules.WithScope(repositories.Scope{OrganizationID: 1})
+	league := test.startedLeague(t, 1)
+
+	_, err := schedules.SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleInterval, IntervalMinutes: 1})
+	require.NoError(t, err)
+	require.NoError(t, test.leagues.WithScope(repositories.Scope{OrganizationID: 1}).PlayAllMatches(league.ID))
+
+	// The last week is played on the first run, the next one finds the league over
+	assert.Equal(t, models.TotalWeeks+1, test.runAt(t, test.now.Add(time.Minute), league))
+	assert.Equal(t, models.TotalWeeks+1, test.runAt(t, test.now.Add(time.Minute), league))
+	schedule, err := schedules.GetSchedule(league.ID)
+	require.NoError(t, err)
+	assert.True(t, schedule.Paused)
+	assert.Contains(t, schedule.LastError, "ended")
+}
+
+func TestScheduleOfDeletedLeague(t *testing.T) {
+	test := setupSchedulerTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	schedules := test.schedules.WithScope(scope)
+	webhooks := test.webhooks.WithScope(scope)
+
+	// Deleting a league deletes its schedule and its webhooks
+	league := test.startedLeague(t, 1)
+	_, err := schedules.SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleInterval, IntervalMinutes: 1})
+	require.NoError(t, err)
+	_, err = webhooks.CreateWebhook(league.ID, &dto.WebhookRequest{URL: "https://example.com/hook"})
+	require.NoError(t, err)
+	require.NoError(t, test.leagues.WithScope(scope).DeleteLeague(league.ID))
+	_, err = schedules.GetSchedule(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	var subscriptions int64
+	require.NoError(t, test.db.Model(&models.WebhookSubscription{}).Where("league_id = ?", league.ID).Count(&subscriptions).Error)
+	assert.Zero(t, subscriptions)
+
+	// A schedule left behind by a league deleted some other way is paused on its next run instead of failing forever.
+	// The league is made in another organization, where the team names are still free.
+	league = test.startedLeague(t, 2)
+	_, err = test.schedules.WithScope(repositories.Scope{OrganizationID: 2}).SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleInterval, IntervalMinutes: 1})
+	require.NoError(t, err)
+	require.NoError(t, test.db.Delete(&models.League{}, league.ID).Error)
+	test.now = test.now.Add(time.Minute)
+	require.NoError(t, test.scheduler.RunDue())
+
+	var schedule models.AdvancementSchedule
+	require.NoError(t, test.db.Where("league_id = ?", league.ID).First(&schedule).Error)
+	assert.True(t, schedule.Paused)
+	assert.Contains(t, schedule.LastError, "not found")
+}
+
+func TestSetScheduleValidation(t *testing.T) {
+	test := setupSchedulerTest(t)
+	schedules := test.schedules.WithScope(repositories.Scope{OrganizationID: 1})
+	league := test.startedLeague(t, 1)
+
+	_, err := schedules.SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleWeekly, Weekday: "caturday", Time: "25:00", Timezone: "Nowhere/Land"})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	var fields []string
+	for _, field := range apperrors.FieldsOf(err) {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"weekday", "time", "timezone"}, fields)
+
+	_, err = schedules.SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleInterval})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	// Friday 2024-08-16 19:00 UTC is 21:00 in Madrid, the next Saturday at 15:00 there is 13:00 UTC
+	schedule, err := schedules.SetSchedule(league.ID, &dto.ScheduleRequest{Kind: models.ScheduleWeekly, Weekday: "Saturday", Time: "15:00", Timezone: "Europe/Madrid"})
+	require.NoError(t, err)
+	assert.Equal(t, "saturday", schedule.Weekday)
+	assert.Equal(t, time.Date(2024, 8, 17, 13, 0, 0, 0, time.UTC), schedule.NextRunAt)
+
+	// Schedules of other organizations are out of reach
+	_, err = test.schedules.WithScope(repositories.Scope{OrganizationID: 2}).GetSchedule(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
@@ -1,13 +1,18 @@
 package services
 
 import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/apperrors"
 	dto "LeagueManager/internal/domain/dtos"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
+	"time"
 )
 
 type LeagueService interface {
@@ -17,34 +22,152 @@ type LeagueService interface {
 	DeleteLeague(id uint) error
 	GetAllLeagues() ([]*models.League, error)
 	GetLeaguesByTeamID(teamID uint) ([]*models.League, error)
-	AddTeamToLeague(leagueID, teamID uint) error
-	RemoveTeamFromLeague(leagueID, teamID uint) error
-	StartLeague(leagueID uint) error
-	AdvanceWeek(leagueID uint) error
+	AddTeamToLeague(leagueID, teamID uint, expected ...dto.LeaguePrecondition) error
+	RemoveTeamFromLeague(leagueID, teamID uint, expected ...dto.LeaguePrecondition) error
+	StartLeague(leagueID uint, expected ...dto.LeaguePrecondition) error
+	AdvanceWeek(leagueID uint, expected ...dto.LeaguePrecondition) error
 	ViewMatchResults(leagueID uint) ([]*models.Match, error)
-	EditMatchResults(matchID uint, updatedMatch *models.Match) error
+	EditMatchResults(matchID uint, result *dto.MatchResultRequest) error
 	PredictChampion(leagueID uint) ([]*dto.TeamPrediction, error)
-	PlayAllMatches(leagueID uint) error
+	PlayAllMatches(leagueID uint, expected ...dto.LeaguePrecondition) error
+	CheckStandings(leagueID uint, repair bool) (*dto.StandingsReport, error)
+	CheckAllStandings(repair bool) ([]*dto.StandingsReport, error)
+	FindLeagues(filter repositories.LeagueFilter, page repositories.Page) ([]*models.League, int64, error)
+	FindMatches(filter repositories.MatchFilter, page repositories.Page) ([]*models.Match, int64, error)
+	RenameLeague(leagueID uint, name string, expected ...dto.LeaguePrecondition) (*models.League, error)
+	GetStandings(leagueID uint) ([]*models.Standing, error)
+	GetMatchByID(matchID uint) (*models.Match, error)
+	SimulateWeek(leagueID uint) ([]models.Match, error)
+	RecordWeek(leagueID uint, matches []models.Match, expected ...dto.LeaguePrecondition) error
+	SetCalendar(leagueID uint, request *dto.CalendarRequest, expected ...dto.LeaguePrecondition) (*models.League, error)
+	SetMatchKickoff(matchID uint, kickoff *time.Time) (*models.Match, error)
+	SetMatchVenue(matchID uint, venueID *uint) (*models.Match, error)
+	PostponeMatch(matchID uint) (*models.Match, error)
+	SetResultMode(leagueID uint, mode string, expected ...dto.LeaguePrecondition) (*models.League, error)
+	SubmitMatchResult(matchID uint, result *dto.MatchResultRequest) (*models.Match, error)
+	CloseWeek(leagueID uint, expected ...dto.LeaguePrecondition) error
+	ImportResults(leagueID uint, results []models.Match) error
+	RescheduleMatch(matchID uint, request *dto.RescheduleRequest) (*models.Match, error)
+	GetFixtureConstraints(leagueID uint) ([]*models.FixtureConstraint, error)
+	SetFixtureConstraints(leagueID uint, constraints []models.FixtureConstraint, expected ...dto.LeaguePrecondition) (*dto.FixtureReport, error)
+	GetFixtureReport(leagueID uint) (*dto.FixtureReport, error)
+	GetLeagueFixtures(leagueID uint) (*dto.Fixtures, error)
+	GetTeamFixtures(teamID uint) (*dto.Fixtures, error)
+	WithScope(scope repositories.Scope) LeagueService
 }
 
 type LeagueServiceImpl struct {
-	leagueRepo   repositories.LeagueRepository
-	teamRepo     repositories.TeamRepository
-	matchRepo    repositories.MatchRepository
-	standingRepo repositories.StandingRepository
+	leagueRepo     repositories.LeagueRepository
+	teamRepo       repositories.TeamRepository
+	matchRepo      repositories.MatchRepository
+	standingRepo   repositories.StandingRepository
+	venueRepo      repositories.VenueRepository
+	constraintRepo repositories.ConstraintRepository
+	auditRepo      repositories.AuditRepository
+	uow            repositories.UnitOfWork
+	bus            events.Bus
+
+	// outbox collects the events of the running transaction, they are published once it commits
+	outbox *[]events.Event
 }
 
-func NewLeagueService(leagueRepo repositories.LeagueRepository, teamRepo repositories.TeamRepository, matchRepo repositories.MatchRepository, standingRepo repositories.StandingRepository) LeagueService {
+func NewLeagueService(leagueRepo repositories.LeagueRepository, teamRepo repositories.TeamRepository, matchRepo repositories.MatchRepository, standingRepo repositories.StandingRepository, uow repositories.UnitOfWork, bus events.Bus) LeagueService {
 	return &LeagueServiceImpl{
-		leagueRepo:   leagueRepo,
-		teamRepo:     teamRepo,
-		matchRepo:    matchRepo,
-		standingRepo: standingRepo,
+		leagueRepo:     leagueRepo,
+		teamRepo:       teamRepo,
+		matchRepo:      matchRepo,
+		standingRepo:   standingRepo,
+		venueRepo:      uow.Venues(),
+		constraintRepo: uow.Constraints(),
+		auditRepo:      uow.Audit(),
+		uow:            uow,
+		bus:            bus,
 	}
 }
 
+// WithScope returns a service that only sees and changes the data of the scope's organization
+func (s *LeagueServiceImpl) WithScope(scope repositories.Scope) LeagueService {
+	return &LeagueServiceImpl{
+		leagueRepo:     s.leagueRepo.WithScope(scope),
+		teamRepo:       s.teamRepo.WithScope(scope),
+		matchRepo:      s.matchRepo.WithScope(scope),
+		standingRepo:   s.standingRepo.WithScope(scope),
+		venueRepo:      s.venueRepo.WithScope(scope),
+		constraintRepo: s.constraintRepo.WithScope(scope),
+		auditRepo:      s.auditRepo.WithScope(scope),
+		uow:            s.uow.WithScope(scope),
+		bus:            s.bus,
+		outbox:         s.outbox,
+	}
+}
+
+// inTransaction runs fn against a copy of the service whose repositories all share one transaction,
+// so every write made by fn is committed together or not at all.
+// The events published by fn are only delivered once the outermost transaction has committed.
+func (s *LeagueServiceImpl) inTransaction(fn func(tx *LeagueServiceImpl) error) error {
+	var outbox []events.Event
+	err := s.uow.Transaction(func(uow repositories.UnitOfWork) error {
+		return fn(&LeagueServiceImpl{
+			leagueRepo:     uow.Leagues(),
+			teamRepo:       uow.Teams(),
+			matchRepo:      uow.Matches(),
+			standingRepo:   uow.Standings(),
+			venueRepo:      uow.Venues(),
+			constraintRepo: uow.Constraints(),
+			auditRepo:      uow.Audit(),
+			uow:            uow,
+			bus:            s.bus,
+			outbox:         &outbox,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(outbox...)
+	return nil
+}
+
+// publish hands the events to the bus, or to the outbox of the surrounding transaction if there is one
+func (s *LeagueServiceImpl) publish(published ...events.Event) {
+	if len(published) == 0 {
+		return
+	}
+	if s.outbox != nil {
+		*s.outbox = append(*s.outbox, published...)
+		return
+	}
+	if s.bus != nil {
+		s.bus.Publish(published...)
+	}
+}
+
+// publishLeagueEvent queues an event about the league
+func (s *LeagueServiceImpl) publishLeagueEvent(eventType string, league *models.League, data interface{}) {
+	s.publish(events.New(eventType, league.OrganizationID, league.ID, data))
+}
+
+// publishWeekAdvanced queues the event of the week before the league's current week, with the standings after it
+func (s *LeagueServiceImpl) publishWeekAdvanced(league *models.League, matches []models.Match) error {
+	standings, err := s.rankedStandings(league.ID)
+	if err != nil {
+		return err
+	}
+	s.publishLeagueEvent(events.WeekAdvanced, league, weekEventData{Week: league.CurrentWeek - 1, Matches: matches, Standings: standings})
+	return nil
+}
+
+// recordLeagueChange appends an audit entry about the league
+func (s *LeagueServiceImpl) recordLeagueChange(action string, league *models.League, before, after json.RawMessage) error {
+	return recordChange(s.auditRepo, action, models.AuditEntityLeague, league.ID, league.ID, before, after)
+}
+
 func (s *LeagueServiceImpl) CreateLeague(league *models.League) error {
-	return s.leagueRepo.CreateLeague(league)
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		if err := tx.leagueRepo.CreateLeague(league); err != nil {
+			return err
+		}
+		return tx.recordLeagueChange(AuditLeagueCreated, league, nil, leagueSnapshot(league))
+	})
 }
 
 func (s *LeagueServiceImpl) GetLeagueByID(id uint) (*models.League, error) {
@@ -52,62 +175,208 @@ func (s *LeagueServiceImpl) GetLeagueByID(id uint) (*models.League, error) {
 }
 
 func (s *LeagueServiceImpl) UpdateLeague(league *models.League) error {
-	return s.leagueRepo.UpdateLeague(league)
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		existing, err := tx.leagueRepo.GetLeagueByID(league.ID)
+		if err != nil {
+			return err
+		}
+		before := leagueSnapshot(existing)
+
+		if err := tx.leagueRepo.UpdateLeague(league); err != nil {
+			return err
+		}
+		return tx.recordLeagueChange(AuditLeagueUpdated, league, before, leagueSnapshot(league))
+	})
 }
 
 func (s *LeagueServiceImpl) DeleteLeague(id uint) error {
-	return s.leagueRepo.DeleteLeague(id)
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		league, err := tx.leagueRepo.GetLeagueByID(id)
+		if err != nil {
+			return err
+		}
+		if err := tx.leagueRepo.DeleteLeague(id); err != nil {
+			return err
+		}
+		if err := tx.constraintRepo.ReplaceConstraints(id, nil); err != nil {
+			return err
+		}
+		// Left behind, the schedule would fail on every run and the webhooks would wait for events that never come
+		if err := tx.uow.Schedules().DeleteSchedule(id); err != nil {
+			return err
+		}
+		if err := tx.uow.Webhooks().DeleteSubscriptionsByLeague(id); err != nil {
+			return err
+		}
+		return tx.recordLeagueChange(AuditLeagueDeleted, league, leagueSnapshot(league), nil)
+	})
+}
+
+// RenameLeague changes the name of a league, guarded by the optional preconditions
+func (s *LeagueServiceImpl) RenameLeague(leagueID uint, name string, expected ...dto.LeaguePrecondition) (*models.League, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.Validation("validation_failed", "league name is required").
+			WithFields(apperrors.FieldError{Field: "name", Message: "is required"})
+	}
+
+	var league *models.League
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		league, err = tx.leagueRepo.GetLeagueByID(leagueID)
+		if err != nil {
+			return err
+		}
+		if err := checkPreconditions(league, expected); err != nil {
+			return err
+		}
+		before := leagueSnapshot(league)
+
+		league.Name = name
+		if err := tx.leagueRepo.UpdateLeague(league); err != nil {
+			return err
+		}
+		return tx.recordLeagueChange(AuditLeagueRenamed, league, before, leagueSnapshot(league))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return league, nil
+}
+
+// GetStandings returns the league table, ranked by points, then goal difference, then wins
+func (s *LeagueServiceImpl) GetStandings(leagueID uint) ([]*models.Standing, error) {
+	if _, err := s.leagueRepo.GetLeagueByID(leagueID); err != nil {
+		return nil, err
+	}
+
+	return s.rankedStandings(leagueID)
+}
+
+// rankedStandings reads the standings of a league ranked by points, then goal difference, then wins
+func (s *LeagueServiceImpl) rankedStandings(leagueID uint) ([]*models.Standing, error) {
+	standings, err := s.standingRepo.GetStandingsByLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.GoalDifference != b.GoalDifference {
+			return a.GoalDifference > b.GoalDifference
+		}
+		return a.Wins > b.Wins
+	})
+	return standings, nil
+}
+
+func (s *LeagueServiceImpl) GetMatchByID(matchID uint) (*models.Match, error) {
+	return s.matchRepo.GetMatchByID(matchID)
 }
 
 func (s *LeagueServiceImpl) GetAllLeagues() ([]*models.League, error) {
 	return s.leagueRepo.GetAllLeagues()
 }
 
+func (s *LeagueServiceImpl) FindLeagues(filter repositories.LeagueFilter, page repositories.Page) ([]*models.League, int64, error) {
+	return s.leagueRepo.FindLeagues(filter, page)
+}
+
+func (s *LeagueServiceImpl) FindMatches(filter repositories.MatchFilter, page repositories.Page) ([]*models.Match, int64, error) {
+	return s.matchRepo.FindMatches(filter, page)
+}
+
 func (s *LeagueServiceImpl) GetLeaguesByTeamID(teamID uint) ([]*models.League, error) {
+	if _, err := s.teamRepo.GetTeamByID(teamID); err != nil {
+		return nil, err
+	}
 	return s.leagueRepo.GetLeaguesByTeamID(teamID)
 }
 
-func (s *LeagueServiceImpl) AddTeamToLeague(leagueID, teamID uint) error {
+func (s *LeagueServiceImpl) AddTeamToLeague(leagueID, teamID uint, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		return tx.addTeamToLeague(leagueID, teamID, expected)
+	})
+}
+
+func (s *LeagueServiceImpl) addTeamToLeague(leagueID, teamID uint, expected []dto.LeaguePrecondition) error {
 	league, err := s.leagueRepo.GetLeagueByID(leagueID)
 	if err != nil {
-		return errors.New("error while retrieving the league with id: " + fmt.Sprint(leagueID))
+		return fmt.Errorf("error while retrieving the league with id: %d: %w", leagueID, err)
+	}
+
+	if err := checkPreconditions(league, expected); err != nil {
+		return err
+	}
+
+	// Adding a team twice leaves the league as it is
+	for _, member := range league.Teams {
+		if member.ID == teamID {
+			return nil
+		}
 	}
 
 	if len(league.Teams) >= 4 {
-		return errors.New("cannot add more than 4 teams to a league")
+		return apperrors.Conflict("league_full", "cannot add more than 4 teams to a league")
 	}
 
 	team, err := s.teamRepo.GetTeamByID(teamID)
 	if err != nil {
-		return errors.New("error while retrieving the team with id: " + fmt.Sprint(teamID))
+		return fmt.Errorf("error while retrieving the team with id: %d: %w", teamID, err)
 	}
 
+	before := leagueSnapshot(league)
 	league.Teams = append(league.Teams, *team)
 
 	res := s.leagueRepo.UpdateLeague(league)
 	if res != nil {
-		return errors.New("error while updating the league with id: " + fmt.Sprint(leagueID) + "error is" + fmt.Sprint(res))
+		return fmt.Errorf("error while updating the league with id: %d: %w", leagueID, res)
 	}
-	return res
+	if err := s.rescheduleFixtures(league); err != nil {
+		return err
+	}
+	if err := s.recordLeagueChange(AuditTeamAdded, league, before, leagueSnapshot(league)); err != nil {
+		return err
+	}
+	s.publishLeagueEvent(events.TeamAdded, league, teamEventData{TeamID: team.ID, TeamName: team.Name})
+	return nil
 }
 
-func (s *LeagueServiceImpl) RemoveTeamFromLeague(leagueID, teamID uint) error {
+// RemoveTeamFromLeague removes the association between a league and a team and bumps the league version
+func (s *LeagueServiceImpl) RemoveTeamFromLeague(leagueID, teamID uint, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		return tx.removeTeamFromLeague(leagueID, teamID, expected)
+	})
+}
+
+func (s *LeagueServiceImpl) removeTeamFromLeague(leagueID, teamID uint, expected []dto.LeaguePrecondition) error {
 	league, err := s.leagueRepo.GetLeagueByID(leagueID)
 	if err != nil {
 		return err
 	}
 
+	if err := checkPreconditions(league, expected); err != nil {
+		return err
+	}
+
+	before := leagueSnapshot(league)
+
 	// Check if the team is part of the league
-	teamFound := false
-	for _, team := range league.Teams {
+	var removed *models.Team
+	var remainingTeams []models.Team
+	for i, team := range league.Teams {
 		if team.ID == teamID {
-			teamFound = true
-			break
+			removed = &league.Teams[i]
+			continue
 		}
+		remainingTeams = append(remainingTeams, team)
 	}
 
-	if !teamFound {
-		return fmt.Errorf("team with ID %d not found in league %d", teamID, leagueID)
+	if removed == nil {
+		return apperrors.NotFound("team_not_in_league", "team with ID %d not found in league %d", teamID, leagueID)
 	}
 
 	// Remove the association between the league and the team
@@ -116,57 +385,193 @@ func (s *LeagueServiceImpl) RemoveTeamFromLeague(leagueID, teamID uint) error {
 		return fmt.Errorf("failed to remove team from league: %w", err)
 	}
 
+	league.Teams = remainingTeams
+	if err := s.leagueRepo.UpdateLeague(league); err != nil {
+		return err
+	}
+	if err := s.rescheduleFixtures(league); err != nil {
+		return err
+	}
+	if err := s.recordLeagueChange(AuditTeamRemoved, league, before, leagueSnapshot(league)); err != nil {
+		return err
+	}
+	s.publishLeagueEvent(events.TeamRemoved, league, teamEventData{TeamID: removed.ID, TeamName: removed.Name})
 	return nil
 }
 
-func (s *LeagueServiceImpl) StartLeague(leagueID uint) error {
+// StartLeague moves the league from week 0 to week 1, all-or-nothing
+func (s *LeagueServiceImpl) StartLeague(leagueID uint, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		return tx.startLeague(leagueID, expected)
+	})
+}
+
+func (s *LeagueServiceImpl) startLeague(leagueID uint, expected []dto.LeaguePrecondition) error {
 	league, err := s.leagueRepo.GetLeagueByID(leagueID)
 	if err != nil {
 		return err
 	}
 
+	if err := checkPreconditions(league, expected); err != nil {
+		return err
+	}
+
 	if len(league.Teams) != 4 {
-		return errors.New("league must have exactly 4 teams to start")
+		return apperrors.PreconditionFailed("league_team_count", "league must have exactly 4 teams to start")
 	}
 
 	if league.IsActive() {
-		return errors.New("league is already active")
+		return apperrors.Conflict("league_already_active", "league is already active")
 	}
 
 	if league.CurrentWeek >= 38 {
-		return errors.New("league has already ended")
+		return apperrors.Conflict("league_ended", "league has already ended")
 	}
 
+	before := leagueSnapshot(league)
 	league.CurrentWeek = 1
 	league.Standings = nil
 	league.Matches = nil
 
-	return s.leagueRepo.UpdateLeague(league)
+	if err := s.leagueRepo.UpdateLeague(league); err != nil {
+		return err
+	}
+	if err := s.scheduleFixtures(league, 1); err != nil {
+		return err
+	}
+	if err := s.recordLeagueChange(AuditLeagueStarted, league, before, leagueSnapshot(league)); err != nil {
+		return err
+	}
+	s.publishLeagueEvent(events.LeagueStarted, league, leagueEventData{Name: league.Name, CurrentWeek: league.CurrentWeek})
+	return nil
+}
+
+// AdvanceWeek advances the league to the next week and plays the matches for that week.
+// The matches, standings and the new week are written in a single transaction. A league with manual results is not
+// played, it only advances once every match of the week has its result.
+func (s *LeagueServiceImpl) AdvanceWeek(leagueID uint, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		return tx.advanceWeek(leagueID, expected)
+	})
 }
 
-// AdvanceWeek advances the league to the next week and plays the matches for that week
-func (s *LeagueServiceImpl) AdvanceWeek(leagueID uint) error {
-	league, err := s.leagueRepo.GetLeagueByID(leagueID)
+func (s *LeagueServiceImpl) advanceWeek(leagueID uint, expected []dto.LeaguePrecondition) error {
+	league, err := s.advanceableLeague(leagueID, expected)
 	if err != nil {
 		return err
 	}
+	before := leagueSnapshot(league)
+	if league.HasManualResults() {
+		return s.closeWeek(league, before, false)
+	}
 
-	if league.CurrentWeek > 38 { // TODO write a function inside league entity instead
-		return errors.New("league has already ended")
+	// Advance the league week
+	matches, err := s.advanceLeague(league)
+	if err != nil {
+		return err
 	}
+	return s.completeWeek(league, before, matches)
+}
 
-	if len(league.Teams) != 4 {
-		return errors.New(fmt.Sprint("league must have exactly 4 teams to advance, this league has ", len(league.Teams), " teams"))
+// SimulateWeek returns the matches of the league's current week with simulated scores, without saving anything
+func (s *LeagueServiceImpl) SimulateWeek(leagueID uint) ([]models.Match, error) {
+	league, err := s.advanceableLeague(leagueID, nil)
+	if err != nil {
+		return nil, err
 	}
+	if err := checkSimulated(league); err != nil {
+		return nil, err
+	}
+	if league.CurrentWeek < 1 {
+		return nil, apperrors.PreconditionFailed("league_not_started", "league week must be greater than or equal to 1")
+	}
+	return s.playMatches(league)
+}
 
-	// Advance the league week
-	league, err = s.advanceLeague(league)
+// RecordWeek saves matches played outside of the service, such as those of SimulateWeek, as the results of the
+// league's current week and advances the league, exactly like AdvanceWeek does with the matches it simulates
+func (s *LeagueServiceImpl) RecordWeek(leagueID uint, matches []models.Match, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		league, err := tx.advanceableLeague(leagueID, expected)
+		if err != nil {
+			return err
+		}
+		if err := checkSimulated(league); err != nil {
+			return err
+		}
+		if league.CurrentWeek < 1 {
+			return apperrors.PreconditionFailed("league_not_started", "league week must be greater than or equal to 1")
+		}
+		before := leagueSnapshot(league)
+
+		fixtures, err := tx.currentFixtures(league)
+		if err != nil {
+			return err
+		}
+		fixtureByID := make(map[uint]models.Match, len(fixtures))
+		for _, fixture := range fixtures {
+			fixtureByID[fixture.ID] = fixture
+		}
+
+		played := make([]models.Match, len(matches))
+		for i, match := range matches {
+			fixture, found := fixtureByID[match.ID]
+			if match.LeagueID != league.ID || match.Week != league.CurrentWeek || !found {
+				return apperrors.Validation("validation_failed", "match %d of the results is not a fixture of week %d of league %d", i, league.CurrentWeek, league.ID)
+			}
+			// Only the score and the crowd are taken from the results, the rest of the fixture stays as stored
+			fixture.HomeTeamScore = match.HomeTeamScore
+			fixture.AwayTeamScore = match.AwayTeamScore
+			fixture.Attendance = match.Attendance
+			fixture.OrganizationID = league.OrganizationID
+			fixture.Status = models.MatchStatusPlayed
+			played[i] = fixture
+			if err := tx.saveMatchResult(&played[i]); err != nil {
+				return err
+			}
+		}
+		return tx.completeWeek(league, before, played)
+	})
+}
+
+// advanceableLeague loads a league that may move to its next week
+func (s *LeagueServiceImpl) advanceableLeague(leagueID uint, expected []dto.LeaguePrecondition) (*models.League, error) {
+	league, err := s.leagueRepo.GetLeagueByID(leagueID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := checkPreconditions(league, expected); err != nil {
+		return nil, err
+	}
+
+	if league.CurrentWeek > 38 { // TODO write a function inside league entity instead
+		return nil, apperrors.Conflict("league_ended", "league has already ended")
 	}
+
+	if len(league.Teams) != 4 {
+		return nil, apperrors.PreconditionFailed("league_team_count", "league must have exactly 4 teams to advance, this league has %d teams", len(league.Teams))
+	}
+	return league, nil
+}
+
+// completeWeek moves the league past the week whose matches were just saved, and records and announces it
+func (s *LeagueServiceImpl) completeWeek(league *models.League, before json.RawMessage, matches []models.Match) error {
 	league.CurrentWeek++
 
-	return s.leagueRepo.UpdateLeague(league)
+	if err := s.leagueRepo.UpdateLeague(league); err != nil {
+		return err
+	}
+	if err := s.recordLeagueChange(AuditWeekAdvanced, league, before, leagueSnapshot(league)); err != nil {
+		return err
+	}
+	if err := s.publishWeekAdvanced(league, matches); err != nil {
+		return err
+	}
+	if !league.IsActive() {
+		s.publishLeagueEvent(events.SeasonFinished, league, leagueEventData{Name: league.Name, CurrentWeek: league.CurrentWeek})
+	}
+	return nil
 }
 
 // ViewMatchResults returns the match results for the current week
@@ -177,7 +582,7 @@ func (s *LeagueServiceImpl) ViewMatchResults(leagueID uint) ([]*models.Match, er
 	}
 
 	if !league.IsActive() {
-		return nil, errors.New("league is not active or has ended")
+		return nil, apperrors.PreconditionFailed("league_not_active", "league is not active or has ended")
 	}
 
 	matches, err := s.matchRepo.GetMatchesByWeek(leagueID, league.CurrentWeek-1) // Current week is always ahead by 1
@@ -187,21 +592,46 @@ func (s *LeagueServiceImpl) ViewMatchResults(leagueID uint) ([]*models.Match, er
 
 	return matches, nil
 }
-func (s *LeagueServiceImpl) EditMatchResults(matchID uint, updatedMatch *models.Match) error {
+
+// EditMatchResults overwrites the score of a match and moves the standings along with it, all-or-nothing
+func (s *LeagueServiceImpl) EditMatchResults(matchID uint, result *dto.MatchResultRequest) error {
+	if err := validateMatchResult(result); err != nil {
+		return err
+	}
+
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		return tx.editMatchResults(matchID, result)
+	})
+}
+
+func (s *LeagueServiceImpl) editMatchResults(matchID uint, result *dto.MatchResultRequest) error {
 	// Retrieve the existing match
 	existingMatch, err := s.matchRepo.GetMatchByID(matchID)
 	if err != nil {
 		return err
 	}
 
+	league, err := s.leagueRepo.GetLeagueByID(existingMatch.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	// Current week is always ahead by 1, so only matches of earlier weeks have been played
+	if existingMatch.Status != models.MatchStatusPlayed || existingMatch.Week >= league.CurrentWeek {
+		return apperrors.PreconditionFailed("match_not_played", "match %d has not been played yet", matchID)
+	}
+
+	before := snapshot(existingMatch)
+	previous := *existingMatch
+
 	// Revert the old match results from the standings
 	if err := s.updateTeamStandings(existingMatch.LeagueID, existingMatch, nil); err != nil {
 		return err
 	}
 
 	// Update the match result
-	existingMatch.HomeTeamScore = updatedMatch.HomeTeamScore
-	existingMatch.AwayTeamScore = updatedMatch.AwayTeamScore
+	existingMatch.HomeTeamScore = *result.HomeTeamScore
+	existingMatch.AwayTeamScore = *result.AwayTeamScore
 
 	if err := s.matchRepo.UpdateMatch(existingMatch); err != nil {
 		return err
@@ -212,6 +642,19 @@ func (s *LeagueServiceImpl) EditMatchResults(matchID uint, updatedMatch *models.
 		return err
 	}
 
+	if err := recordChange(s.auditRepo, AuditMatchResultEdited, models.AuditEntityMatch, existingMatch.ID, existingMatch.LeagueID, before, snapshot(existingMatch)); err != nil {
+		return err
+	}
+	standings, err := s.rankedStandings(league.ID)
+	if err != nil {
+		return err
+	}
+	s.publishLeagueEvent(events.MatchResultEdited, league, matchEditedEventData{
+		Match:             existingMatch,
+		PreviousHomeScore: previous.HomeTeamScore,
+		PreviousAwayScore: previous.AwayTeamScore,
+		Standings:         standings,
+	})
 	return nil
 }
 
@@ -222,21 +665,21 @@ func (s *LeagueServiceImpl) PredictChampion(leagueID uint) ([]*dto.TeamPredictio
 	}
 
 	if !league.IsActive() {
-		return nil, errors.New("league is not active or has ended")
+		return nil, apperrors.PreconditionFailed("league_not_active", "league is not active or has ended")
 	}
 
 	if league.CurrentWeek < 4 {
-		return nil, errors.New("league did not reach the 4th week yet")
+		return nil, apperrors.PreconditionFailed("league_too_early", "league did not reach the 4th week yet")
 	}
 
 	standings := league.Standings
 	if len(standings) == 0 {
-		return nil, errors.New("no standings found for the league")
+		return nil, apperrors.PreconditionFailed("league_no_standings", "no standings found for the league")
 	}
 
 	teams := league.Teams
 	if len(teams) != 4 {
-		return nil, errors.New("league must have 4 teams")
+		return nil, apperrors.PreconditionFailed("league_team_count", "league must have 4 teams")
 	}
 
 	teamStandings, err := s.combineTeamsAndStandings(teams, standings)
@@ -252,41 +695,191 @@ func (s *LeagueServiceImpl) PredictChampion(leagueID uint) ([]*dto.TeamPredictio
 	return predictions, nil
 }
 
-func (s *LeagueServiceImpl) PlayAllMatches(leagueID uint) error {
+// PlayAllMatches plays every remaining week of the league in a single transaction
+func (s *LeagueServiceImpl) PlayAllMatches(leagueID uint, expected ...dto.LeaguePrecondition) error {
+	return s.inTransaction(func(tx *LeagueServiceImpl) error {
+		return tx.playAllMatches(leagueID, expected)
+	})
+}
+
+func (s *LeagueServiceImpl) playAllMatches(leagueID uint, expected []dto.LeaguePrecondition) error {
 	league, err := s.leagueRepo.GetLeagueByID(leagueID)
 	if err != nil {
 		return err
 	}
 
+	if err := checkPreconditions(league, expected); err != nil {
+		return err
+	}
+
+	if err := checkSimulated(league); err != nil {
+		return err
+	}
+
 	if league.CurrentWeek == 0 {
-		return errors.New("the current week is 0, the league has not started yet, please start the league first")
+		return apperrors.PreconditionFailed("league_not_started", "the current week is 0, the league has not started yet, please start the league first")
 	}
 
 	if !league.IsActive() {
-		return errors.New(fmt.Sprint("league has ended, current week is: ", league.CurrentWeek))
+		return apperrors.Conflict("league_ended", "league has ended, current week is: %d", league.CurrentWeek)
 	}
 
 	if len(league.Teams) != 4 {
-		return errors.New("league must have exactly 4 teams to play matches")
+		return apperrors.PreconditionFailed("league_team_count", "league must have exactly 4 teams to play matches")
 	}
 
+	before := leagueSnapshot(league)
 	for league.CurrentWeek < 38 { // TODO refactor
-		league, err = s.advanceLeague(league)
+		matches, err := s.advanceLeague(league)
 		if err != nil {
 			return err
 		}
 		league.CurrentWeek++
+		if err := s.publishWeekAdvanced(league, matches); err != nil {
+			return err
+		}
 	}
 
-	return s.leagueRepo.UpdateLeague(league)
+	if err := s.leagueRepo.UpdateLeague(league); err != nil {
+		return err
+	}
+	return s.recordLeagueChange(AuditAllMatchesPlayed, league, before, leagueSnapshot(league))
+}
+
+// CheckStandings rebuilds the standings of a league from its matches and reports every difference from the stored rows.
+// When repair is true the stored rows are overwritten with the rebuilt ones, in the transaction they are read in so a
+// week played or a result edited meanwhile is not overwritten with the standings from before it.
+func (s *LeagueServiceImpl) CheckStandings(leagueID uint, repair bool) (*dto.StandingsReport, error) {
+	if !repair {
+		return s.checkStandings(leagueID, false)
+	}
+
+	var report *dto.StandingsReport
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		report, err = tx.checkStandings(leagueID, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *LeagueServiceImpl) checkStandings(leagueID uint, repair bool) (*dto.StandingsReport, error) {
+	league, err := s.leagueRepo.GetLeagueByID(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.matchRepo.GetMatchesByLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.standingRepo.GetStandingsByLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.StandingsReport{
+		LeagueID:      leagueID,
+		Discrepancies: s.compareStandings(stored, s.rebuildStandings(leagueID, matches)),
+	}
+	report.Consistent = len(report.Discrepancies) == 0
+
+	if repair && !report.Consistent {
+		if err := s.repairStandings(report.Discrepancies); err != nil {
+			return nil, err
+		}
+		before, after := []*models.Standing{}, []*models.Standing{}
+		for _, discrepancy := range report.Discrepancies {
+			if discrepancy.Stored != nil {
+				before = append(before, discrepancy.Stored)
+			}
+			if discrepancy.Expected != nil {
+				after = append(after, discrepancy.Expected)
+			}
+		}
+		if err := s.recordLeagueChange(AuditStandingsRepaired, league, snapshot(before), snapshot(after)); err != nil {
+			return nil, err
+		}
+		report.Repaired = true
+	}
+
+	return report, nil
+}
+
+// CheckAllStandings runs CheckStandings for every league
+func (s *LeagueServiceImpl) CheckAllStandings(repair bool) ([]*dto.StandingsReport, error) {
+	leagues, err := s.leagueRepo.GetAllLeagues()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*dto.StandingsReport, 0, len(leagues))
+	for _, league := range leagues {
+		report, err := s.CheckStandings(league.ID, repair)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check standings of league %d: %w", league.ID, err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
 }
 
 // Below are helper functions for simulating matches and calculating scores
 
-func (s *LeagueServiceImpl) advanceLeague(league *models.League) (*models.League, error) {
+// validateMatchResult checks that both scores are present and not negative
+func validateMatchResult(result *dto.MatchResultRequest) error {
+	var fields []apperrors.FieldError
+	for _, score := range []struct {
+		field string
+		value *int
+	}{
+		{"home_team_score", result.HomeTeamScore},
+		{"away_team_score", result.AwayTeamScore},
+	} {
+		if score.value == nil {
+			fields = append(fields, apperrors.FieldError{Field: score.field, Message: "is required"})
+		} else if *score.value < 0 {
+			fields = append(fields, apperrors.FieldError{Field: score.field, Message: "must not be negative"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid match result").WithFields(fields...)
+	}
+	return nil
+}
+
+// checkPreconditions fails with repositories.ErrVersionConflict if the league is not in the state the caller expects
+func checkPreconditions(league *models.League, expected []dto.LeaguePrecondition) error {
+	for _, precondition := range expected {
+		if precondition.Version != nil && *precondition.Version != league.Version {
+			return fmt.Errorf("%w: expected version %d, league is at version %d", repositories.ErrVersionConflict, *precondition.Version, league.Version)
+		}
+		if precondition.Week != nil && *precondition.Week != league.CurrentWeek {
+			return fmt.Errorf("%w: expected week %d, league is at week %d", repositories.ErrVersionConflict, *precondition.Week, league.CurrentWeek)
+		}
+	}
+	return nil
+}
+
+// checkSimulated fails for a league whose results are entered by hand, which cannot be played by the simulation
+func checkSimulated(league *models.League) error {
+	if league.HasManualResults() {
+		return apperrors.PreconditionFailed("league_results_manual", "the results of league %d are entered by hand, they cannot be simulated", league.ID)
+	}
+	return nil
+}
+
+// advanceLeague plays and saves the matches of the league's current week and returns them
+func (s *LeagueServiceImpl) advanceLeague(league *models.League) ([]models.Match, error) {
 	// check if week is more than or equal 1
 	if league.CurrentWeek < 1 {
-		return nil, errors.New("league week must be greater than or equal to 1")
+		return nil, apperrors.PreconditionFailed("league_not_started", "league week must be greater than or equal to 1")
 	}
 	// Play matches for the current week
 	matches, err := s.playMatches(league)
@@ -295,19 +888,19 @@ func (s *LeagueServiceImpl) advanceLeague(league *models.League) (*models.League
 	}
 
 	// Save match results
-	for _, match := range matches {
-		err := s.saveMatchResult(&match)
+	for i := range matches {
+		err := s.saveMatchResult(&matches[i])
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return league, nil
+	return matches, nil
 }
 
 func (s *LeagueServiceImpl) combineTeamsAndStandings(teams []models.Team, standings []models.Standing) ([]teamStanding, error) {
 	if len(standings) != 4 {
-		return nil, errors.New("4 standings must be present for the league")
+		return nil, apperrors.PreconditionFailed("league_no_standings", "4 standings must be present for the league")
 	}
 
 	var teamStandings []teamStanding
@@ -385,61 +978,58 @@ func (s *LeagueServiceImpl) normalizeProbabilities(predictions []*dto.TeamPredic
 
 // Custom object to store teams with their standings
 type teamStanding struct {
-	models.Team
-	models.Standing
+	Team     models.Team
+	Standing models.Standing
 }
 
-// playMatches simulates the matches for the current week
+// playMatches simulates the fixtures of the current week, without saving them
 func (s *LeagueServiceImpl) playMatches(league *models.League) ([]models.Match, error) {
-	var matches []models.Match
-	teams := league.Teams
-
-	if len(teams) != 4 {
-		return nil, errors.New("league must have exactly 4 teams to play matches")
+	if len(league.Teams) != 4 {
+		return nil, apperrors.PreconditionFailed("league_team_count", "league must have exactly 4 teams to play matches")
 	}
 
-	// Example fixtures for 4 teams:
-	// Week 1: A vs B, C vs D
-	// Week 2: A vs C, B vs D
-	// Week 3: A vs D, B vs C
-	// Repeat for each set of matches
-
-	weekFixtures := [][][2]int{
-		{{0, 1}, {2, 3}},
-		{{0, 2}, {1, 3}},
-		{{0, 3}, {1, 2}},
+	matches, err := s.currentFixtures(league)
+	if err != nil {
+		return nil, err
 	}
 
-	weekIndex := ((league.CurrentWeek - 1) + 3) % 3 // +3 is unnecessary unless it ever becomes negative which is impossible in the current implementation buy may change in the future
-	fixtures := weekFixtures[weekIndex]
-
-	for _, fixture := range fixtures {
-		homeTeam := teams[fixture[0]]
-		awayTeam := teams[fixture[1]]
-		homeScore, awayScore := s.simulateMatch(homeTeam, awayTeam)
-
-		match := models.Match{
-			LeagueID:      league.ID,
-			HomeTeamID:    homeTeam.ID,
-			AwayTeamID:    awayTeam.ID,
-			HomeTeamScore: homeScore,
-			AwayTeamScore: awayScore,
-			Week:          league.CurrentWeek,
+	teams := make(map[uint]models.Team, len(league.Teams))
+	for _, team := range league.Teams {
+		teams[team.ID] = team
+	}
+	for i := range matches {
+		homeTeam, homeFound := teams[matches[i].HomeTeamID]
+		awayTeam, awayFound := teams[matches[i].AwayTeamID]
+		if !homeFound || !awayFound {
+			return nil, apperrors.PreconditionFailed("fixture_team_missing", "match %d is between teams that are no longer in the league", matches[i].ID)
 		}
-
-		matches = append(matches, match)
+		venue, err := s.venueOf(&matches[i])
+		if err != nil {
+			return nil, err
+		}
+		atHome := atHomeGround(&homeTeam, &matches[i])
+		matches[i].HomeTeamScore, matches[i].AwayTeamScore = s.simulateMatch(homeTeam, awayTeam, atHome)
+		if venue != nil {
+			matches[i].Attendance = simulateAttendance(venue, homeTeam, awayTeam, atHome)
+		}
+		matches[i].Status = models.MatchStatusPlayed
 	}
 
 	return matches, nil
 }
 
-// simulateMatch simulates the result of a match based on teams' strengths
-func (s *LeagueServiceImpl) simulateMatch(homeTeam, awayTeam models.Team) (int, int) {
+// simulateMatch simulates the result of a match based on teams' strengths, the home team is stronger when it plays
+// at its own ground
+func (s *LeagueServiceImpl) simulateMatch(homeTeam, awayTeam models.Team, atHome bool) (int, int) {
 
 	homeAttack := homeTeam.AttackStrength
 	awayDefense := awayTeam.DefenseStrength
 	awayAttack := awayTeam.AttackStrength
 	homeDefense := homeTeam.DefenseStrength
+	if atHome {
+		homeAttack = min(homeAttack+homeAdvantage, models.MaxStrength)
+		homeDefense = min(homeDefense+homeAdvantage, models.MaxStrength)
+	}
 
 	homeScore := s.calculateScore(homeAttack, awayDefense)
 	awayScore := s.calculateScore(awayAttack, homeDefense)
@@ -449,7 +1039,11 @@ func (s *LeagueServiceImpl) simulateMatch(homeTeam, awayTeam models.Team) (int,
 
 // saveMatchResult saves the match result and updates the standings
 func (s *LeagueServiceImpl) saveMatchResult(match *models.Match) error {
-	if err := s.matchRepo.CreateMatch(match); err != nil {
+	save := s.matchRepo.UpdateMatch
+	if match.ID == 0 {
+		save = s.matchRepo.CreateMatch
+	}
+	if err := save(match); err != nil {
 		return err
 	}
 
@@ -484,6 +1078,9 @@ func (s *LeagueServiceImpl) updateTeamStandings(leagueID uint, oldMatch, newMatc
 // adjustStandings adjusts the standings for a team based on match results
 func (s *LeagueServiceImpl) adjustStandings(leagueID, teamID uint, teamScore, opponentScore int, isRevert bool) error {
 	standing, err := s.standingRepo.GetStandingByTeam(leagueID, teamID)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return err
+	}
 	if err != nil {
 		// Create new standings if not exists
 		standing = &models.Standing{
@@ -498,41 +1095,157 @@ func (s *LeagueServiceImpl) adjustStandings(leagueID, teamID uint, teamScore, op
 		}
 	}
 
-	// Revert old match result if needed
+	applyResult(standing, teamScore, opponentScore, isRevert)
+
+	// Standing is newly created if err is not nil
+	if err != nil {
+		return s.standingRepo.CreateStanding(standing)
+	}
+	return s.standingRepo.UpdateStanding(standing)
+}
+
+// applyResult applies a single match result to a team's standing, or reverts it if isRevert is true
+func applyResult(standing *models.Standing, teamScore, opponentScore int, isRevert bool) {
+	sign := 1
 	if isRevert {
-		standing.GoalDifference -= teamScore - opponentScore
-		standing.Played--
-
-		if teamScore > opponentScore {
-			standing.Wins--
-			standing.Points -= 3
-		} else if teamScore == opponentScore {
-			standing.Draws--
-			standing.Points--
-		} else {
-			standing.Losses--
-		}
+		sign = -1
+	}
+
+	standing.GoalDifference += sign * (teamScore - opponentScore)
+	standing.Played += sign
+
+	if teamScore > opponentScore {
+		standing.Wins += sign
+		standing.Points += sign * 3
+	} else if teamScore == opponentScore {
+		standing.Draws += sign
+		standing.Points += sign
 	} else {
-		// Apply new match result
-		standing.GoalDifference += teamScore - opponentScore
-		standing.Played++
+		standing.Losses += sign
+	}
+}
 
-		if teamScore > opponentScore {
-			standing.Wins++
-			standing.Points += 3
-		} else if teamScore == opponentScore {
-			standing.Draws++
-			standing.Points++
-		} else {
-			standing.Losses++
+// rebuildStandings computes the standings of a league purely from its matches, keyed by team ID
+func (s *LeagueServiceImpl) rebuildStandings(leagueID uint, matches []*models.Match) map[uint]*models.Standing {
+	standings := make(map[uint]*models.Standing)
+	standingOf := func(teamID uint) *models.Standing {
+		if standing, ok := standings[teamID]; ok {
+			return standing
 		}
+		standing := &models.Standing{LeagueID: leagueID, TeamID: teamID}
+		standings[teamID] = standing
+		return standing
 	}
 
-	// Standing is newly created if err is not nil
-	if err != nil {
-		return s.standingRepo.CreateStanding(standing)
+	for _, match := range matches {
+		if match.Status != models.MatchStatusPlayed {
+			continue
+		}
+		applyResult(standingOf(match.HomeTeamID), match.HomeTeamScore, match.AwayTeamScore, false)
+		applyResult(standingOf(match.AwayTeamID), match.AwayTeamScore, match.HomeTeamScore, false)
 	}
-	return s.standingRepo.UpdateStanding(standing)
+
+	return standings
+}
+
+// compareStandings lists the differences between the stored and the rebuilt standings, ordered by team ID
+func (s *LeagueServiceImpl) compareStandings(stored []*models.Standing, expected map[uint]*models.Standing) []dto.StandingDiscrepancy {
+	discrepancies := []dto.StandingDiscrepancy{}
+	seen := make(map[uint]bool)
+
+	for _, standing := range stored {
+		seen[standing.TeamID] = true
+		rebuilt := expected[standing.TeamID]
+
+		// A team without matches is expected to have an all-zero row, or none at all
+		compareTo := rebuilt
+		if compareTo == nil {
+			compareTo = &models.Standing{}
+		}
+
+		fields := standingFieldDiff(standing, compareTo)
+		if len(fields) == 0 {
+			continue
+		}
+
+		discrepancies = append(discrepancies, dto.StandingDiscrepancy{
+			TeamID:   standing.TeamID,
+			Fields:   fields,
+			Stored:   standing,
+			Expected: rebuilt,
+		})
+	}
+
+	for teamID, rebuilt := range expected {
+		if seen[teamID] {
+			continue
+		}
+		discrepancies = append(discrepancies, dto.StandingDiscrepancy{
+			TeamID:   teamID,
+			Fields:   standingFieldDiff(&models.Standing{}, rebuilt),
+			Expected: rebuilt,
+		})
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].TeamID < discrepancies[j].TeamID
+	})
+
+	return discrepancies
+}
+
+// standingFieldDiff returns the JSON names of the counters that differ between two standings
+func standingFieldDiff(a, b *models.Standing) []string {
+	var fields []string
+	if a.Points != b.Points {
+		fields = append(fields, "points")
+	}
+	if a.Played != b.Played {
+		fields = append(fields, "played")
+	}
+	if a.Wins != b.Wins {
+		fields = append(fields, "wins")
+	}
+	if a.Draws != b.Draws {
+		fields = append(fields, "draws")
+	}
+	if a.Losses != b.Losses {
+		fields = append(fields, "losses")
+	}
+	if a.GoalDifference != b.GoalDifference {
+		fields = append(fields, "goal_difference")
+	}
+	return fields
+}
+
+// repairStandings overwrites the stored standings with the rebuilt ones for every discrepancy
+func (s *LeagueServiceImpl) repairStandings(discrepancies []dto.StandingDiscrepancy) error {
+	for _, discrepancy := range discrepancies {
+		switch {
+		case discrepancy.Stored == nil:
+			standing := *discrepancy.Expected
+			if err := s.standingRepo.CreateStanding(&standing); err != nil {
+				return err
+			}
+		case discrepancy.Expected == nil:
+			if err := s.standingRepo.DeleteStanding(discrepancy.Stored.ID); err != nil {
+				return err
+			}
+		default:
+			// Work on a copy so the report keeps showing the values found before the repair
+			standing := *discrepancy.Stored
+			standing.Points = discrepancy.Expected.Points
+			standing.Played = discrepancy.Expected.Played
+			standing.Wins = discrepancy.Expected.Wins
+			standing.Draws = discrepancy.Expected.Draws
+			standing.Losses = discrepancy.Expected.Losses
+			standing.GoalDifference = discrepancy.Expected.GoalDifference
+			if err := s.standingRepo.UpdateStanding(&standing); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // calculateScore calculates the score for a team based on its attack strength and the opponent's defense strength
@@ -1,10 +1,15 @@
 package services_test
 
 import (
+	"LeagueManager/internal/application/events"
 	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -17,7 +22,8 @@ func setupLeagueServiceTest() (*gorm.DB, services.LeagueService, services.TeamSe
 	if err != nil {
 		panic("failed to connect to database")
 	}
-	err = db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{})
+	err = db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{}, &models.AuditEntry{}, &models.FixtureConstraint{},
+		&models.AdvancementSchedule{}, &models.WebhookSubscription{})
 	if err != nil {
 		panic("failed to connect to migrate database")
 	}
@@ -27,8 +33,8 @@ func setupLeagueServiceTest() (*gorm.DB, services.LeagueService, services.TeamSe
 	matchRepo := repositories.NewMatchRepository(db)
 	standingRepo := repositories.NewStandingRepository(db)
 
-	leagueService := services.NewLeagueService(leagueRepo, teamRepo, matchRepo, standingRepo)
-	teamService := services.NewTeamService(teamRepo, leagueRepo)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, matchRepo, standingRepo, repositories.NewUnitOfWork(db), events.NewBus())
+	teamService := services.NewTeamService(teamRepo, leagueRepo, repositories.NewUnitOfWork(db))
 
 	return db, leagueService, teamService
 }
@@ -80,6 +86,17 @@ func createTestLeagueForService(leagueService services.LeagueService, teamServic
 	return league
 }
 
+// playedMatches counts the matches of the league that have been played, its unplayed fixtures aside
+func playedMatches(league *models.League) int {
+	played := 0
+	for _, match := range league.Matches {
+		if match.Status == models.MatchStatusPlayed {
+			played++
+		}
+	}
+	return played
+}
+
 func TestCreateLeague(t *testing.T) {
 	db, leagueService, teamService := setupLeagueServiceTest()
 
@@ -200,13 +217,10 @@ func TestEditMatchResults(t *testing.T) {
 	assert.NoError(t, err)
 	match := matches[0]
 
-	updatedMatch := &models.Match{
-		HomeTeamScore: 2,
-		AwayTeamScore: 2,
-		LeagueID:      match.LeagueID,
-		HomeTeamID:    match.HomeTeamID,
-		AwayTeamID:    match.AwayTeamID,
-		Week:          match.Week,
+	two := 2
+	updatedMatch := &dto.MatchResultRequest{
+		HomeTeamScore: &two,
+		AwayTeamScore: &two,
 	}
 
 	err = leagueService.EditMatchResults(match.ID, updatedMatch)
@@ -226,8 +240,41 @@ func TestEditMatchResults(t *testing.T) {
 		}
 	}
 
-	err = leagueService.EditMatchResults(0, &models.Match{}) // Non-existent match
-	assert.Error(t, err)
+	err = leagueService.EditMatchResults(0, updatedMatch) // Non-existent match
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	negative := -1
+	err = leagueService.EditMatchResults(match.ID, &dto.MatchResultRequest{HomeTeamScore: &negative})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "home_team_score", Message: "must not be negative"},
+		{Field: "away_team_score", Message: "is required"},
+	}, apperrors.FieldsOf(err))
+}
+
+func TestEditUnplayedMatch(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	league := createTestLeagueForService(leagueService, teamService)
+
+	err := leagueService.StartLeague(league.ID)
+	assert.NoError(t, err)
+
+	// A fixture of the current week that has not been played yet
+	match := &models.Match{LeagueID: league.ID, HomeTeamID: league.Teams[0].ID, AwayTeamID: league.Teams[1].ID, Week: 1}
+	assert.NoError(t, db.Create(match).Error)
+
+	one := 1
+	err = leagueService.EditMatchResults(match.ID, &dto.MatchResultRequest{HomeTeamScore: &one, AwayTeamScore: &one})
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
 }
 
 func TestPredictChampion(t *testing.T) {
@@ -286,6 +333,19 @@ func TestGetLeagueStandings(t *testing.T) {
 	newLeague, err := leagueService.GetLeagueByID(league.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, 4, len(newLeague.Standings))
+
+	// The table is ranked by points, then goal difference
+	standings, err := leagueService.GetStandings(league.ID)
+	assert.NoError(t, err)
+	assert.Len(t, standings, 4)
+	for i := 1; i < len(standings); i++ {
+		previous, current := standings[i-1], standings[i]
+		assert.True(t, previous.Points > current.Points ||
+			previous.Points == current.Points && previous.GoalDifference >= current.GoalDifference)
+	}
+
+	_, err = leagueService.GetStandings(league.ID + 1)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
 }
 
 func TestAddTeamToLeague(t *testing.T) {
@@ -308,6 +368,10 @@ func TestAddTeamToLeague(t *testing.T) {
 	err = leagueService.AddTeamToLeague(league.ID, newTeam.ID)
 	assert.Error(t, err) // Should fail as league already has 4 teams
 
+	// Adding a team that already plays in the league changes nothing
+	err = leagueService.AddTeamToLeague(league.ID, league.Teams[0].ID)
+	assert.NoError(t, err)
+
 	league, err = leagueService.GetLeagueByID(league.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, 4, len(league.Teams))
@@ -386,3 +450,317 @@ func TestDeleteTeam(t *testing.T) {
 		return
 	}
 }
+
+func TestCheckStandings(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	league := createTestLeagueForService(leagueService, teamService)
+
+	err := leagueService.StartLeague(league.ID)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		err := leagueService.AdvanceWeek(league.ID)
+		assert.NoError(t, err)
+	}
+
+	report, err := leagueService.CheckStandings(league.ID, false)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Empty(t, report.Discrepancies)
+
+	// Corrupt one standing and drop another behind the service's back
+	var standings []models.Standing
+	assert.NoError(t, db.Where("league_id = ?", league.ID).Order("team_id").Find(&standings).Error)
+	assert.Equal(t, 4, len(standings))
+	assert.NoError(t, db.Model(&standings[0]).Update("points", standings[0].Points+7).Error)
+	assert.NoError(t, db.Unscoped().Delete(&standings[1]).Error)
+
+	report, err = leagueService.CheckStandings(league.ID, false)
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent)
+	assert.False(t, report.Repaired)
+	assert.Equal(t, 2, len(report.Discrepancies))
+	assert.Equal(t, standings[0].TeamID, report.Discrepancies[0].TeamID)
+	assert.Equal(t, []string{"points"}, report.Discrepancies[0].Fields)
+	assert.Equal(t, standings[1].TeamID, report.Discrepancies[1].TeamID)
+	assert.Nil(t, report.Discrepancies[1].Stored)
+
+	reports, err := leagueService.CheckAllStandings(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.True(t, reports[0].Repaired)
+
+	report, err = leagueService.CheckStandings(league.ID, false)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent)
+
+	_, err = leagueService.CheckStandings(999, false) // Non-existent league
+	assert.Error(t, err)
+}
+
+func TestAdvanceWeekRollsBackOnFailure(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	league := createTestLeagueForService(leagueService, teamService)
+
+	err := leagueService.StartLeague(league.ID)
+	assert.NoError(t, err)
+
+	// Fail every standing write, after the first match of the week has already been saved
+	err = db.Callback().Create().Before("gorm:create").Register("test:fail_standings", func(tx *gorm.DB) {
+		if tx.Statement.Table == "standings" {
+			_ = tx.AddError(errors.New("simulated standings failure"))
+		}
+	})
+	assert.NoError(t, err)
+
+	err = leagueService.AdvanceWeek(league.ID)
+	assert.Error(t, err)
+
+	err = leagueService.PlayAllMatches(league.ID)
+	assert.Error(t, err)
+
+	var matchCount int64
+	assert.NoError(t, db.Model(&models.Match{}).Where("status = ?", models.MatchStatusPlayed).Count(&matchCount).Error)
+	assert.Zero(t, matchCount)
+
+	unchanged, err := leagueService.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, unchanged.CurrentWeek)
+
+	// Once the failure is gone the same week can be played normally
+	assert.NoError(t, db.Callback().Create().Remove("test:fail_standings"))
+
+	err = leagueService.AdvanceWeek(league.ID)
+	assert.NoError(t, err)
+
+	advanced, err := leagueService.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, advanced.CurrentWeek)
+	assert.Equal(t, 2, playedMatches(advanced))
+	assert.Equal(t, 4, len(advanced.Standings))
+}
+
+func TestAdvanceWeekWithPrecondition(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	league := createTestLeagueForService(leagueService, teamService)
+
+	err := leagueService.StartLeague(league.ID)
+	assert.NoError(t, err)
+
+	started, err := leagueService.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+
+	version := started.Version
+	week := started.CurrentWeek
+
+	// Both clients read the league at the same version, the first one to advance wins
+	err = leagueService.AdvanceWeek(league.ID, dto.LeaguePrecondition{Version: &version})
+	assert.NoError(t, err)
+
+	err = leagueService.AdvanceWeek(league.ID, dto.LeaguePrecondition{Version: &version})
+	assert.ErrorIs(t, err, repositories.ErrVersionConflict)
+
+	err = leagueService.AdvanceWeek(league.ID, dto.LeaguePrecondition{Week: &week})
+	assert.ErrorIs(t, err, repositories.ErrVersionConflict)
+
+	advanced, err := leagueService.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, advanced.CurrentWeek)
+	assert.Equal(t, version+1, advanced.Version)
+	assert.Equal(t, 2, playedMatches(advanced)) // The week was played only once
+}
+
+func TestRenameLeague(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	league := createTestLeagueForService(leagueService, teamService)
+	version := league.Version
+
+	renamed, err := leagueService.RenameLeague(league.ID, "  Renamed League ", dto.LeaguePrecondition{Version: &version})
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed League", renamed.Name)
+	assert.Equal(t, version+1, renamed.Version)
+
+	// The version the rename was made against is stale now
+	_, err = leagueService.RenameLeague(league.ID, "Stale League", dto.LeaguePrecondition{Version: &version})
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	_, err = leagueService.RenameLeague(league.ID, " ")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	err = leagueService.DeleteLeague(league.ID)
+	assert.NoError(t, err)
+	err = leagueService.DeleteLeague(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+func TestOrganizationsAreIsolated(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	red := repositories.Scope{OrganizationID: 1}
+	blue := repositories.Scope{OrganizationID: 2}
+
+	league := createTestLeagueForService(leagueService.WithScope(red), teamService.WithScope(red))
+	assert.NoError(t, leagueService.WithScope(red).StartLeague(league.ID))
+	assert.NoError(t, leagueService.WithScope(red).AdvanceWeek(league.ID))
+
+	// Team names only need to be unique within an organization
+	blueTeam := models.Team{Name: "Team A", AttackStrength: 50, DefenseStrength: 50}
+	assert.NoError(t, teamService.WithScope(blue).CreateTeam(&blueTeam))
+	blueLeague := &models.League{Name: "Blue League"}
+	assert.NoError(t, leagueService.WithScope(blue).CreateLeague(blueLeague))
+
+	// Another organization's team cannot join a league, in either direction
+	err := leagueService.WithScope(blue).AddTeamToLeague(blueLeague.ID, league.Teams[0].ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	redLeague := &models.League{Name: "Red Cup"}
+	assert.NoError(t, leagueService.WithScope(red).CreateLeague(redLeague))
+	err = leagueService.WithScope(red).AddTeamToLeague(redLeague.ID, blueTeam.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	// The matches and standings played in a league belong to its organization
+	matches, total, err := leagueService.WithScope(red).FindMatches(repositories.MatchFilter{Status: models.MatchStatusPlayed}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	standings, err := leagueService.WithScope(red).GetStandings(league.ID)
+	assert.NoError(t, err)
+	assert.Len(t, standings, 4)
+	for _, standing := range standings {
+		assert.Equal(t, red.OrganizationID, standing.OrganizationID)
+	}
+
+	_, total, err = leagueService.WithScope(blue).FindMatches(repositories.MatchFilter{}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+	_, err = leagueService.WithScope(blue).GetStandings(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	err = leagueService.WithScope(blue).EditMatchResults(matches[0].ID, &dto.MatchResultRequest{HomeTeamScore: new(int), AwayTeamScore: new(int)})
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	err = leagueService.WithScope(blue).PlayAllMatches(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.ErrorIs(t, teamService.WithScope(blue).DeleteTeam(league.Teams[0].ID), apperrors.ErrNotFound)
+
+	reports, err := leagueService.WithScope(blue).CheckAllStandings(false)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, blueLeague.ID, reports[0].LeagueID)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+
+	sqlDB, _ := db.DB()
+	defer func(sqlDB *sql.DB) {
+		err := sqlDB.Close()
+		if err != nil {
+			panic("failed to close database connection")
+		}
+	}(sqlDB)
+
+	referee := repositories.Scope{OrganizationID: 1, Actor: "referee", Reason: "goal-line review"}
+	leagues := leagueService.WithScope(referee)
+	audit := services.NewAuditService(repositories.NewAuditRepository(db)).WithScope(referee)
+
+	league := createTestLeagueForService(leagues, teamService.WithScope(referee))
+	assert.NoError(t, leagues.StartLeague(league.ID))
+	assert.NoError(t, leagues.AdvanceWeek(league.ID))
+
+	matches, err := leagues.ViewMatchResults(league.ID)
+	assert.NoError(t, err)
+	match := matches[0]
+	home, away := 5, 0
+	assert.NoError(t, leagues.EditMatchResults(match.ID, &dto.MatchResultRequest{HomeTeamScore: &home, AwayTeamScore: &away}))
+
+	// Failed changes leave no entry
+	stale := uint(0)
+	_, err = leagues.RenameLeague(league.ID, "Renamed", dto.LeaguePrecondition{Version: &stale})
+	assert.Error(t, err)
+
+	entries, total, err := audit.FindEntries(repositories.AuditFilter{LeagueID: league.ID}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, "referee", entry.Actor)
+		assert.Equal(t, "goal-line review", entry.Reason)
+		assert.Equal(t, uint(1), entry.OrganizationID)
+		assert.False(t, entry.CreatedAt.IsZero())
+	}
+	assert.Equal(t, []string{services.AuditLeagueCreated, services.AuditLeagueStarted, services.AuditWeekAdvanced, services.AuditMatchResultEdited}, actions)
+	assert.Nil(t, entries[0].Before)
+	assert.JSONEq(t, `{"name": "Test League", "current_week": 1, "result_mode": "simulated", "version": 1, "team_ids": [1, 2, 3, 4]}`, string(entries[1].After))
+
+	// The match entry keeps the old and the new score
+	entries, _, err = audit.FindEntries(repositories.AuditFilter{EntityType: models.AuditEntityMatch, EntityID: match.ID}, repositories.Page{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		var before, after models.Match
+		assert.NoError(t, json.Unmarshal(entries[0].Before, &before))
+		assert.NoError(t, json.Unmarshal(entries[0].After, &after))
+		assert.Equal(t, match.HomeTeamScore, before.HomeTeamScore)
+		assert.Equal(t, match.AwayTeamScore, before.AwayTeamScore)
+		assert.Equal(t, 5, after.HomeTeamScore)
+		assert.Equal(t, 0, after.AwayTeamScore)
+	}
+
+	// Team changes are recorded without a league, and other organizations do not see any of it
+	_, total, err = audit.FindEntries(repositories.AuditFilter{EntityType: models.AuditEntityTeam}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	_, total, err = audit.WithScope(repositories.Scope{OrganizationID: 2}).FindEntries(repositories.AuditFilter{}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+
+	// Changes made without a caller are attributed to the system
+	assert.NoError(t, leagueService.CreateLeague(&models.League{Name: "Background"}))
+	entries, _, err = services.NewAuditService(repositories.NewAuditRepository(db)).FindEntries(repositories.AuditFilter{}, repositories.Page{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, repositories.SystemActor, entries[0].Actor)
+	}
+}
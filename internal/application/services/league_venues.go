@@ -0,0 +1,99 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"errors"
+	"math/rand"
+)
+
+// homeAdvantage is added to the attack and defense strength of a team playing at its own ground
+const homeAdvantage = 10
+
+// Shares of a venue's seats sold for a match, before the strength of the teams is accounted for
+const (
+	baseDemand    = 0.45
+	randomDemand  = 0.35 // At most this much is added at random
+	neutralDemand = 0.7  // Demand is scaled down by this at a ground that is not the home team's
+)
+
+// atHomeGround reports whether the home team of a match really plays it at its own ground
+func atHomeGround(homeTeam *models.Team, match *models.Match) bool {
+	return match.VenueID != nil && sameVenue(homeTeam.HomeVenueID, match.VenueID)
+}
+
+// sameVenue reports whether two optional venue IDs are equal, two missing venues are the same
+func sameVenue(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// simulateAttendance draws the crowd of a match at a venue, stronger teams draw a larger one
+func simulateAttendance(venue *models.Venue, homeTeam, awayTeam models.Team, atHome bool) int {
+	strength := float64(homeTeam.AttackStrength+homeTeam.DefenseStrength+awayTeam.AttackStrength+awayTeam.DefenseStrength) / (4 * models.MaxStrength)
+	demand := baseDemand + rand.Float64()*randomDemand + strength*(1-baseDemand-randomDemand)
+	if !atHome {
+		demand *= neutralDemand
+	}
+	return venue.Attendance(demand)
+}
+
+// venueOf returns the venue of a match, nil if it has none or the venue was deleted since
+func (s *LeagueServiceImpl) venueOf(match *models.Match) (*models.Venue, error) {
+	if match.VenueID == nil {
+		return nil, nil
+	}
+	venue, err := s.venueRepo.GetVenueByID(*match.VenueID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, nil
+	}
+	return venue, err
+}
+
+// SetMatchVenue moves an unplayed match to a venue, typically a neutral one. A nil venue moves the match back to the
+// home team's ground.
+func (s *LeagueServiceImpl) SetMatchVenue(matchID uint, venueID *uint) (*models.Match, error) {
+	var match *models.Match
+	err := s.inTransaction(func(tx *LeagueServiceImpl) error {
+		var err error
+		match, err = tx.matchRepo.GetMatchByID(matchID)
+		if err != nil {
+			return err
+		}
+		if match.Status == models.MatchStatusPlayed {
+			return apperrors.PreconditionFailed("match_already_played", "match %d has already been played", matchID)
+		}
+		before := snapshot(match)
+
+		if venueID != nil {
+			venue, err := tx.venueRepo.GetVenueByID(*venueID)
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.Validation("validation_failed", "venue %d does not exist", *venueID).
+					WithFields(apperrors.FieldError{Field: "venue_id", Message: "must be an existing venue"})
+			}
+			if err != nil {
+				return err
+			}
+			match.VenueID = &venue.ID
+			match.VenueFixed = true
+		} else {
+			homeTeam, err := tx.teamRepo.GetTeamByID(match.HomeTeamID)
+			if err != nil {
+				return err
+			}
+			match.VenueID = homeTeam.HomeVenueID
+			match.VenueFixed = false
+		}
+
+		if err := tx.matchRepo.UpdateMatch(match); err != nil {
+			return err
+		}
+		return recordChange(tx.auditRepo, AuditMatchVenueSet, models.AuditEntityMatch, match.ID, match.LeagueID, before, snapshot(match))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtHomeGround(t *testing.T) {
+	ground, other := uint(1), uint(2)
+	team := &models.Team{HomeVenueID: &ground}
+
+	assert.True(t, atHomeGround(team, &models.Match{VenueID: &ground}))
+	assert.False(t, atHomeGround(team, &models.Match{VenueID: &other}), "a neutral venue")
+	assert.False(t, atHomeGround(team, &models.Match{}))
+	assert.False(t, atHomeGround(&models.Team{}, &models.Match{}), "a team without a ground is never at home")
+}
+
+func TestHomeAdvantage(t *testing.T) {
+	service := &LeagueServiceImpl{}
+	home := models.Team{AttackStrength: 60, DefenseStrength: 60}
+	away := models.Team{AttackStrength: 60, DefenseStrength: 60}
+
+	margin := func(atHome bool) int {
+		total := 0
+		for i := 0; i < 5000; i++ {
+			homeScore, awayScore := service.simulateMatch(home, away, atHome)
+			total += homeScore - awayScore
+		}
+		return total
+	}
+	assert.Greater(t, margin(true), margin(false)+1000, "home teams score more at their own ground")
+}
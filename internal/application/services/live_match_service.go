@@ -0,0 +1,349 @@
+package services
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLiveWeekDuration is the wall-clock time a live week lasts unless the caller chooses another one
+const DefaultLiveWeekDuration = 90 * time.Second
+
+// matchMinutes is the length of a match in match minutes
+const matchMinutes = 90
+
+// LiveMatchService plays the matches of a week over a stretch of wall-clock time. The results are decided when
+// the week starts, but their goals are only revealed, and published as events, as the match minutes pass.
+// Nothing is saved until the week is over, when the results are recorded like those of AdvanceWeek.
+// Live weeks are held in memory, a week that is in progress when the server stops is cancelled and lost.
+type LiveMatchService interface {
+	Start(ctx context.Context)
+	StartLiveWeek(leagueID uint, duration time.Duration) (*dto.LiveWeek, error)
+	GetLiveWeek(leagueID uint) (*dto.LiveWeek, error)
+	StopLiveWeek(leagueID uint) (*dto.LiveWeek, error)
+	WithScope(scope repositories.Scope) LiveMatchService
+}
+
+type LiveMatchServiceImpl struct {
+	leagueService LeagueService
+	bus           events.Bus
+	live          *liveWeeks
+	scope         repositories.Scope
+}
+
+// liveWeeks holds the latest live week of every league, shared by all scopes of the service. Leagues in starting
+// are reserved by a week being drawn, which is done without holding the lock.
+type liveWeeks struct {
+	mu       sync.Mutex
+	ctx      context.Context // The weeks are played until it is done
+	weeks    map[uint]*liveWeek
+	starting map[uint]bool
+}
+
+type liveWeek struct {
+	scope     repositories.Scope // The caller who started the week, who the results are recorded for
+	leagueID  uint
+	week      int
+	startedAt time.Time
+	duration  time.Duration
+	matches   []models.Match   // The final results
+	goals     [][]dto.LiveGoal // The goals of every match, by minute
+	status    string
+	err       string
+	stoppedAt time.Time          // When a cancelled week was stopped, its scores stay as they were then
+	recording bool               // The week is over and its results are being recorded, it can no longer be stopped
+	cancel    context.CancelFunc // Stops playing the week
+}
+
+func NewLiveMatchService(leagueService LeagueService, bus events.Bus) LiveMatchService {
+	return &LiveMatchServiceImpl{
+		leagueService: leagueService,
+		bus:           bus,
+		live:          &liveWeeks{ctx: context.Background(), weeks: map[uint]*liveWeek{}, starting: map[uint]bool{}},
+	}
+}
+
+// Start plays the live weeks started from now on until ctx is done, when the weeks in progress are stopped without
+// their results being recorded
+func (s *LiveMatchServiceImpl) Start(ctx context.Context) {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	s.live.ctx = ctx
+}
+
+// WithScope returns a service that only plays and shows the leagues of the scope's organization
+func (s *LiveMatchServiceImpl) WithScope(scope repositories.Scope) LiveMatchService {
+	return &LiveMatchServiceImpl{leagueService: s.leagueService, bus: s.bus, live: s.live, scope: scope}
+}
+
+// StartLiveWeek starts playing the league's current week live, over the given duration
+func (s *LiveMatchServiceImpl) StartLiveWeek(leagueID uint, duration time.Duration) (*dto.LiveWeek, error) {
+	if duration <= 0 {
+		duration = DefaultLiveWeekDuration
+	}
+
+	ctx, err := s.live.claim(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	var week *liveWeek
+	defer func() { s.live.release(leagueID, week) }()
+
+	matches, err := s.leagueService.WithScope(s.scope).SimulateWeek(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, apperrors.PreconditionFailed("no_matches_to_play", "every match of the current week of league %d is postponed, there is nothing to play live", leagueID)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	week = &liveWeek{
+		scope:     s.scope,
+		leagueID:  leagueID,
+		week:      matches[0].Week,
+		startedAt: time.Now().UTC(),
+		duration:  duration,
+		matches:   matches,
+		status:    dto.LiveWeekInProgress,
+		cancel:    cancel,
+	}
+	for _, match := range matches {
+		week.goals = append(week.goals, goalTimeline(match))
+	}
+	snapshot := week.snapshot(week.startedAt)
+
+	s.bus.Publish(events.New(events.LiveWeekStarted, s.scope.OrganizationID, leagueID, liveWeekEventData{
+		Week:            week.week,
+		DurationSeconds: duration.Seconds(),
+		EndsAt:          snapshot.EndsAt,
+		Matches:         snapshot.Matches,
+	}))
+	go s.play(ctx, week)
+	return snapshot, nil
+}
+
+// claim reserves a league for a week about to be drawn, unless it is already playing one live
+func (l *liveWeeks) claim(leagueID uint) (context.Context, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if current, ok := l.weeks[leagueID]; ok && current.status == dto.LiveWeekInProgress {
+		return nil, apperrors.Conflict("league_live", "week %d of league %d is already being played live", current.week, leagueID)
+	}
+	if l.starting[leagueID] {
+		return nil, apperrors.Conflict("league_live", "a week of league %d is already being started live", leagueID)
+	}
+	l.starting[leagueID] = true
+	return l.ctx, nil
+}
+
+// release frees a league claimed for a week, keeping the week if it could be drawn
+func (l *liveWeeks) release(leagueID uint, week *liveWeek) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.starting, leagueID)
+	if week != nil {
+		l.weeks[leagueID] = week
+	}
+}
+
+// GetLiveWeek returns the state of the league's latest live week
+func (s *LiveMatchServiceImpl) GetLiveWeek(leagueID uint) (*dto.LiveWeek, error) {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+
+	week, ok := s.live.weeks[leagueID]
+	if !ok || week.scope.OrganizationID != s.scope.OrganizationID {
+		return nil, apperrors.NotFound("live_week_not_found", "league %d has no live week", leagueID)
+	}
+	return week.snapshot(time.Now()), nil
+}
+
+// StopLiveWeek stops the week the league is playing live, its results are not recorded and the league stays where
+// it was
+func (s *LiveMatchServiceImpl) StopLiveWeek(leagueID uint) (*dto.LiveWeek, error) {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+
+	week, ok := s.live.weeks[leagueID]
+	if !ok || week.scope.OrganizationID != s.scope.OrganizationID {
+		return nil, apperrors.NotFound("live_week_not_found", "league %d has no live week", leagueID)
+	}
+	if week.status != dto.LiveWeekInProgress || week.recording {
+		return nil, apperrors.Conflict("live_week_over", "week %d of league %d is no longer being played live", week.week, leagueID)
+	}
+	week.stop("")
+	return week.snapshot(week.stoppedAt), nil
+}
+
+// play publishes the goals of the week as their minutes are reached, then records the results. The week is stopped
+// without being recorded once ctx is done.
+func (s *LiveMatchServiceImpl) play(ctx context.Context, week *liveWeek) {
+	defer week.cancel()
+
+	type scheduledGoal struct {
+		match int
+		goal  dto.LiveGoal
+	}
+	var goals []scheduledGoal
+	for i, timeline := range week.goals {
+		for _, goal := range timeline {
+			goals = append(goals, scheduledGoal{match: i, goal: goal})
+		}
+	}
+	sort.SliceStable(goals, func(i, j int) bool { return goals[i].goal.Minute < goals[j].goal.Minute })
+
+	for _, scheduled := range goals {
+		if !sleepUntil(ctx, week.at(scheduled.goal.Minute)) {
+			s.stopped(week)
+			return
+		}
+		match := week.matches[scheduled.match]
+		s.bus.Publish(events.New(events.GoalScored, week.scope.OrganizationID, week.leagueID, goalEventData{
+			Week:       week.week,
+			HomeTeamID: match.HomeTeamID,
+			AwayTeamID: match.AwayTeamID,
+			LiveGoal:   scheduled.goal,
+		}))
+	}
+	if !sleepUntil(ctx, week.at(matchMinutes)) {
+		s.stopped(week)
+		return
+	}
+
+	s.live.mu.Lock()
+	if week.status != dto.LiveWeekInProgress {
+		s.live.mu.Unlock()
+		return
+	}
+	week.recording = true
+	s.live.mu.Unlock()
+
+	// The week only counts if the league is still where it was when the week started
+	err := s.leagueService.WithScope(week.scope).RecordWeek(week.leagueID, week.matches, dto.LeaguePrecondition{Week: &week.week})
+
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	if err != nil {
+		logrus.WithError(err).WithField("league", week.leagueID).Warn("Failed to record the results of a live week")
+		week.status = dto.LiveWeekFailed
+		week.err = err.Error()
+		return
+	}
+	week.status = dto.LiveWeekFinished
+}
+
+// stopped marks a week whose context ended as cancelled, unless it was stopped on request already
+func (s *LiveMatchServiceImpl) stopped(week *liveWeek) {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	if week.status == dto.LiveWeekInProgress {
+		week.stop("the server stopped before the week was over")
+	}
+}
+
+// stop cancels the week at the current minute, the caller holds the lock
+func (w *liveWeek) stop(reason string) {
+	w.cancel()
+	w.status = dto.LiveWeekCancelled
+	w.stoppedAt = time.Now()
+	w.err = reason
+}
+
+// sleepUntil waits until t, it reports false if ctx is done first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return ctx.Err() == nil
+	}
+}
+
+// at returns the wall-clock time at which the given match minute is reached
+func (w *liveWeek) at(minute int) time.Time {
+	return w.startedAt.Add(w.duration * time.Duration(minute) / matchMinutes)
+}
+
+// snapshot returns the state of the week at now, revealing only the goals scored so far
+func (w *liveWeek) snapshot(now time.Time) *dto.LiveWeek {
+	minute := matchMinutes
+	if w.status == dto.LiveWeekCancelled {
+		now = w.stoppedAt
+	}
+	if w.status == dto.LiveWeekInProgress || w.status == dto.LiveWeekCancelled {
+		minute = int(now.Sub(w.startedAt) * matchMinutes / w.duration)
+		if minute > matchMinutes {
+			minute = matchMinutes
+		}
+		if minute < 0 {
+			minute = 0
+		}
+	}
+
+	live := &dto.LiveWeek{
+		LeagueID:  w.leagueID,
+		Week:      w.week,
+		Status:    w.status,
+		Minute:    minute,
+		StartedAt: w.startedAt,
+		EndsAt:    w.at(matchMinutes),
+		Matches:   []dto.LiveMatch{},
+		Error:     w.err,
+	}
+	for i, match := range w.matches {
+		liveMatch := dto.LiveMatch{HomeTeamID: match.HomeTeamID, AwayTeamID: match.AwayTeamID, Goals: []dto.LiveGoal{}}
+		for _, goal := range w.goals[i] {
+			if goal.Minute > minute {
+				break
+			}
+			liveMatch.Goals = append(liveMatch.Goals, goal)
+			liveMatch.HomeTeamScore, liveMatch.AwayTeamScore = goal.HomeTeamScore, goal.AwayTeamScore
+		}
+		live.Matches = append(live.Matches, liveMatch)
+	}
+	return live
+}
+
+// goalTimeline spreads the goals of a decided match over random minutes of the match
+func goalTimeline(match models.Match) []dto.LiveGoal {
+	scorers := make([]uint, 0, match.HomeTeamScore+match.AwayTeamScore)
+	for i := 0; i < match.HomeTeamScore; i++ {
+		scorers = append(scorers, match.HomeTeamID)
+	}
+	for i := 0; i < match.AwayTeamScore; i++ {
+		scorers = append(scorers, match.AwayTeamID)
+	}
+	rand.Shuffle(len(scorers), func(i, j int) { scorers[i], scorers[j] = scorers[j], scorers[i] })
+
+	minutes := make([]int, len(scorers))
+	for i := range minutes {
+		minutes[i] = 1 + rand.Intn(matchMinutes)
+	}
+	sort.Ints(minutes)
+
+	goals := make([]dto.LiveGoal, len(scorers))
+	home, away := 0, 0
+	for i, teamID := range scorers {
+		if teamID == match.HomeTeamID {
+			home++
+		} else {
+			away++
+		}
+		goals[i] = dto.LiveGoal{Minute: minutes[i], TeamID: teamID, HomeTeamScore: home, AwayTeamScore: away}
+	}
+	return goals
+}
@@ -0,0 +1,194 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForLiveWeek polls the live week of the league until it is no longer in progress
+func waitForLiveWeek(t *testing.T, live services.LiveMatchService, leagueID uint) *dto.LiveWeek {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		week, err := live.GetLiveWeek(leagueID)
+		require.NoError(t, err)
+		if week.Status != dto.LiveWeekInProgress {
+			return week
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the live week did not finish")
+	return nil
+}
+
+func TestLiveWeek(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1, Actor: "presenter"}
+	leagues := test.leagues.WithScope(scope)
+	live := services.NewLiveMatchService(test.leagues, test.bus).WithScope(scope)
+
+	var mu sync.Mutex
+	var goals []events.Event
+	test.bus.Subscribe(func(event events.Event) {
+		if event.Type == events.GoalScored {
+			mu.Lock()
+			goals = append(goals, event)
+			mu.Unlock()
+		}
+	})
+
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+	_, err := live.StartLiveWeek(league.ID, time.Second)
+	assert.True(t, errors.Is(err, apperrors.ErrPreconditionFailed), "a league that has not started cannot play live")
+	require.NoError(t, leagues.StartLeague(league.ID))
+
+	started, err := live.StartLiveWeek(league.ID, 300*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, dto.LiveWeekInProgress, started.Status)
+	assert.Equal(t, 1, started.Week)
+	assert.Equal(t, 0, started.Minute)
+	if assert.Len(t, started.Matches, 2) {
+		assert.Zero(t, started.Matches[0].HomeTeamScore+started.Matches[0].AwayTeamScore)
+	}
+
+	_, err = live.StartLiveWeek(league.ID, time.Second)
+	assert.True(t, errors.Is(err, apperrors.ErrConflict))
+
+	// Nothing is saved while the week is being played
+	_, total, err := leagues.FindMatches(repositories.MatchFilter{LeagueID: league.ID, Status: models.MatchStatusPlayed}, repositories.Page{})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	// Another organization does not see the live week
+	_, err = services.NewLiveMatchService(test.leagues, test.bus).WithScope(repositories.Scope{OrganizationID: 2}).GetLiveWeek(league.ID)
+	assert.True(t, errors.Is(err, apperrors.ErrNotFound))
+
+	finished := waitForLiveWeek(t, live, league.ID)
+	assert.Equal(t, dto.LiveWeekFinished, finished.Status)
+	assert.Equal(t, 90, finished.Minute)
+
+	saved, err := leagues.GetLeagueByID(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, saved.CurrentWeek)
+
+	matches, err := leagues.ViewMatchResults(league.ID)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	scored := 0
+	for i, match := range finished.Matches {
+		assert.Equal(t, match.HomeTeamID, matches[i].HomeTeamID)
+		assert.Equal(t, match.HomeTeamScore, matches[i].HomeTeamScore)
+		assert.Equal(t, match.AwayTeamScore, matches[i].AwayTeamScore)
+		assert.Len(t, match.Goals, match.HomeTeamScore+match.AwayTeamScore)
+		for j := 1; j < len(match.Goals); j++ {
+			assert.LessOrEqual(t, match.Goals[j-1].Minute, match.Goals[j].Minute)
+		}
+		scored += len(match.Goals)
+	}
+	mu.Lock()
+	assert.Len(t, goals, scored, "every goal is published")
+	mu.Unlock()
+
+	standings, err := leagues.GetStandings(league.ID)
+	require.NoError(t, err)
+	assert.Len(t, standings, 4)
+}
+
+func TestLiveWeekFailsWhenTheLeagueMovesOn(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	leagues := test.leagues.WithScope(scope)
+	live := services.NewLiveMatchService(test.leagues, test.bus).WithScope(scope)
+
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+	require.NoError(t, leagues.StartLeague(league.ID))
+	_, err := live.StartLiveWeek(league.ID, 200*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+
+	failed := waitForLiveWeek(t, live, league.ID)
+	assert.Equal(t, dto.LiveWeekFailed, failed.Status)
+	assert.NotEmpty(t, failed.Error)
+
+	// Only the week played normally was saved
+	_, total, err := leagues.FindMatches(repositories.MatchFilter{LeagueID: league.ID, Status: models.MatchStatusPlayed}, repositories.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestLiveWeekWithEveryMatchPostponed(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	leagues := test.leagues.WithScope(scope)
+	live := services.NewLiveMatchService(test.leagues, test.bus).WithScope(scope)
+
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+	require.NoError(t, leagues.StartLeague(league.ID))
+	week := 1
+	fixtures, _, err := leagues.FindMatches(repositories.MatchFilter{LeagueID: league.ID, WeekFrom: &week, WeekTo: &week}, repositories.Page{})
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+	for _, match := range fixtures {
+		_, err := leagues.PostponeMatch(match.ID)
+		require.NoError(t, err)
+	}
+
+	_, err = live.StartLiveWeek(league.ID, time.Second)
+	assert.Equal(t, "no_matches_to_play", apperrors.CodeOf(err))
+
+	// The league is not left reserved and the other leagues are not held up
+	_, err = live.GetLiveWeek(league.ID)
+	assert.True(t, errors.Is(err, apperrors.ErrNotFound))
+	_, err = live.StartLiveWeek(league.ID, time.Second)
+	assert.Equal(t, "no_matches_to_play", apperrors.CodeOf(err))
+}
+
+func TestStopLiveWeek(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	leagues := test.leagues.WithScope(scope)
+	service := services.NewLiveMatchService(test.leagues, test.bus)
+	live := service.WithScope(scope)
+
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+	require.NoError(t, leagues.StartLeague(league.ID))
+	_, err := live.StopLiveWeek(league.ID)
+	assert.True(t, errors.Is(err, apperrors.ErrNotFound))
+
+	_, err = live.StartLiveWeek(league.ID, time.Hour)
+	require.NoError(t, err)
+	_, err = services.NewLiveMatchService(test.leagues, test.bus).WithScope(repositories.Scope{OrganizationID: 2}).StopLiveWeek(league.ID)
+	assert.True(t, errors.Is(err, apperrors.ErrNotFound))
+
+	stopped, err := live.StopLiveWeek(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, dto.LiveWeekCancelled, stopped.Status)
+	assert.Zero(t, stopped.Minute)
+	_, err = live.StopLiveWeek(league.ID)
+	assert.True(t, errors.Is(err, apperrors.ErrConflict))
+
+	// The week can be played again, until the server stops
+	ctx, shutdown := context.WithCancel(context.Background())
+	service.Start(ctx)
+	_, err = live.StartLiveWeek(league.ID, time.Hour)
+	require.NoError(t, err)
+	shutdown()
+	cancelled := waitForLiveWeek(t, live, league.ID)
+	assert.Equal(t, dto.LiveWeekCancelled, cancelled.Status)
+	assert.NotEmpty(t, cancelled.Error)
+
+	saved, err := leagues.GetLeagueByID(league.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, saved.CurrentWeek, "nothing was recorded")
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"errors"
+	"strings"
+)
+
+type OrganizationService interface {
+	GetOrganizationByID(id uint) (*models.Organization, error)
+	GetAllOrganizations() ([]*models.Organization, error)
+	EnsureOrganization(name string) (*models.Organization, error)
+}
+
+type OrganizationServiceImpl struct {
+	organizationRepo repositories.OrganizationRepository
+}
+
+func NewOrganizationService(organizationRepo repositories.OrganizationRepository) OrganizationService {
+	return &OrganizationServiceImpl{organizationRepo: organizationRepo}
+}
+
+func (s *OrganizationServiceImpl) GetOrganizationByID(id uint) (*models.Organization, error) {
+	return s.organizationRepo.GetOrganizationByID(id)
+}
+
+func (s *OrganizationServiceImpl) GetAllOrganizations() ([]*models.Organization, error) {
+	return s.organizationRepo.GetAllOrganizations()
+}
+
+// EnsureOrganization returns the organization with the given name, creating it if it does not exist yet
+func (s *OrganizationServiceImpl) EnsureOrganization(name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.Validation("validation_failed", "organization name is required").
+			WithFields(apperrors.FieldError{Field: "name", Message: "is required"})
+	}
+
+	organization, err := s.organizationRepo.GetOrganizationByName(name)
+	if err == nil || !errors.Is(err, apperrors.ErrNotFound) {
+		return organization, err
+	}
+
+	organization = &models.Organization{Name: name}
+	if err := s.organizationRepo.CreateOrganization(organization); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
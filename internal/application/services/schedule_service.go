@@ -0,0 +1,177 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ScheduleService manages the schedules that advance leagues automatically
+type ScheduleService interface {
+	SetSchedule(leagueID uint, request *dto.ScheduleRequest) (*models.AdvancementSchedule, error)
+	GetSchedule(leagueID uint) (*models.AdvancementSchedule, error)
+	DeleteSchedule(leagueID uint) error
+	PauseSchedule(leagueID uint) (*models.AdvancementSchedule, error)
+	ResumeSchedule(leagueID uint) (*models.AdvancementSchedule, error)
+	WithScope(scope repositories.Scope) ScheduleService
+}
+
+type ScheduleServiceImpl struct {
+	scheduleRepo repositories.ScheduleRepository
+	leagueRepo   repositories.LeagueRepository
+	scheduler    *LeagueScheduler
+}
+
+func NewScheduleService(scheduleRepo repositories.ScheduleRepository, leagueRepo repositories.LeagueRepository, scheduler *LeagueScheduler) ScheduleService {
+	return &ScheduleServiceImpl{scheduleRepo: scheduleRepo, leagueRepo: leagueRepo, scheduler: scheduler}
+}
+
+// WithScope returns a service that only sees and changes the schedules of the scope's organization
+func (s *ScheduleServiceImpl) WithScope(scope repositories.Scope) ScheduleService {
+	return &ScheduleServiceImpl{
+		scheduleRepo: s.scheduleRepo.WithScope(scope),
+		leagueRepo:   s.leagueRepo.WithScope(scope),
+		scheduler:    s.scheduler,
+	}
+}
+
+// SetSchedule creates or replaces the schedule of a league. The first run is the first one after now, a paused
+// schedule stays paused.
+func (s *ScheduleServiceImpl) SetSchedule(leagueID uint, request *dto.ScheduleRequest) (*models.AdvancementSchedule, error) {
+	if _, err := s.leagueRepo.GetLeagueByID(leagueID); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleRepo.GetScheduleByLeague(leagueID)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, err
+	}
+	if schedule == nil {
+		schedule = &models.AdvancementSchedule{LeagueID: leagueID}
+	}
+
+	schedule.Kind = request.Kind
+	schedule.Weekday = ""
+	schedule.TimeOfDay = ""
+	schedule.Timezone = ""
+	schedule.IntervalMinutes = 0
+	if request.Kind == models.ScheduleWeekly {
+		schedule.Weekday = strings.ToLower(strings.TrimSpace(request.Weekday))
+		schedule.TimeOfDay = strings.TrimSpace(request.Time)
+		schedule.Timezone = strings.TrimSpace(request.Timezone)
+		if schedule.Timezone == "" {
+			schedule.Timezone = "UTC"
+		}
+	} else {
+		schedule.IntervalMinutes = request.IntervalMinutes
+	}
+	schedule.MissedRunPolicy = request.MissedRunPolicy
+	if schedule.MissedRunPolicy == "" {
+		schedule.MissedRunPolicy = models.MissedRunsOnce
+	}
+	schedule.LastError = ""
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	// The new timing starts from now, not from the runs of the replaced schedule
+	schedule.NextRunAt = time.Time{}
+	if schedule.NextRunAt, err = schedule.NextAfter(s.scheduler.now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.SaveSchedule(schedule); err != nil {
+		return nil, err
+	}
+	s.scheduler.notify()
+	return schedule, nil
+}
+
+func (s *ScheduleServiceImpl) GetSchedule(leagueID uint) (*models.AdvancementSchedule, error) {
+	if _, err := s.leagueRepo.GetLeagueByID(leagueID); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.GetScheduleByLeague(leagueID)
+}
+
+// DeleteSchedule stops advancing the league automatically
+func (s *ScheduleServiceImpl) DeleteSchedule(leagueID uint) error {
+	if _, err := s.GetSchedule(leagueID); err != nil {
+		return err
+	}
+	return s.scheduleRepo.DeleteSchedule(leagueID)
+}
+
+// PauseSchedule stops the runs of a schedule until it is resumed
+func (s *ScheduleServiceImpl) PauseSchedule(leagueID uint) (*models.AdvancementSchedule, error) {
+	schedule, err := s.GetSchedule(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Paused {
+		return schedule, nil
+	}
+
+	schedule.Paused = true
+	if err := s.scheduleRepo.SaveSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// ResumeSchedule restarts a paused schedule from its first run after now, the runs missed while it was paused
+// are never made
+func (s *ScheduleServiceImpl) ResumeSchedule(leagueID uint) (*models.AdvancementSchedule, error) {
+	schedule, err := s.GetSchedule(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Paused {
+		return schedule, nil
+	}
+
+	if schedule.NextRunAt, err = schedule.NextAfter(s.scheduler.now()); err != nil {
+		return nil, err
+	}
+	schedule.Paused = false
+	schedule.LastError = ""
+	if err := s.scheduleRepo.SaveSchedule(schedule); err != nil {
+		return nil, err
+	}
+	s.scheduler.notify()
+	return schedule, nil
+}
+
+// validateSchedule checks the fields the kind of the schedule needs
+func validateSchedule(schedule *models.AdvancementSchedule) error {
+	var fields []apperrors.FieldError
+
+	switch schedule.Kind {
+	case models.ScheduleWeekly:
+		if _, err := models.ParseWeekday(schedule.Weekday); err != nil {
+			fields = append(fields, apperrors.FieldError{Field: "weekday", Message: "must be a day of the week such as saturday"})
+		}
+		if _, _, err := models.ParseTimeOfDay(schedule.TimeOfDay); err != nil {
+			fields = append(fields, apperrors.FieldError{Field: "time", Message: "must be a 24-hour time such as 15:00"})
+		}
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			fields = append(fields, apperrors.FieldError{Field: "timezone", Message: "must be an IANA time zone such as Europe/London"})
+		}
+	case models.ScheduleInterval:
+		if schedule.IntervalMinutes < 1 {
+			fields = append(fields, apperrors.FieldError{Field: "interval_minutes", Message: "must be at least 1"})
+		}
+	default:
+		fields = append(fields, apperrors.FieldError{Field: "kind", Message: "must be weekly or interval"})
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid schedule").WithFields(fields...)
+	}
+	return nil
+}
@@ -1,41 +1,132 @@
 package services
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 )
 
 type TeamService interface {
 	CreateTeam(team *models.Team) error
 	GetTeamByID(id uint) (*models.Team, error)
+	GetTeamByName(name string) (*models.Team, error)
 	UpdateTeam(team *models.Team) error
 	DeleteTeam(id uint) error
 	GetAllTeams() ([]*models.Team, error)
+	FindTeams(filter repositories.TeamFilter, page repositories.Page) ([]*models.Team, int64, error)
+	WithScope(scope repositories.Scope) TeamService
 }
 
 type TeamServiceImpl struct {
 	teamRepo   repositories.TeamRepository
 	leagueRepo repositories.LeagueRepository
+	auditRepo  repositories.AuditRepository
+	uow        repositories.UnitOfWork
 }
 
-func NewTeamService(teamRepo repositories.TeamRepository, leagueRepo repositories.LeagueRepository) TeamService {
-	return &TeamServiceImpl{teamRepo: teamRepo, leagueRepo: leagueRepo}
+func NewTeamService(teamRepo repositories.TeamRepository, leagueRepo repositories.LeagueRepository, uow repositories.UnitOfWork) TeamService {
+	return &TeamServiceImpl{teamRepo: teamRepo, leagueRepo: leagueRepo, auditRepo: uow.Audit(), uow: uow}
+}
+
+// WithScope returns a service that only sees and changes the teams of the scope's organization
+func (s *TeamServiceImpl) WithScope(scope repositories.Scope) TeamService {
+	return &TeamServiceImpl{
+		teamRepo:   s.teamRepo.WithScope(scope),
+		leagueRepo: s.leagueRepo.WithScope(scope),
+		auditRepo:  s.auditRepo.WithScope(scope),
+		uow:        s.uow.WithScope(scope),
+	}
+}
+
+// inTransaction runs fn against a copy of the service whose repositories all share one transaction
+func (s *TeamServiceImpl) inTransaction(fn func(tx *TeamServiceImpl) error) error {
+	return s.uow.Transaction(func(uow repositories.UnitOfWork) error {
+		return fn(&TeamServiceImpl{teamRepo: uow.Teams(), leagueRepo: uow.Leagues(), auditRepo: uow.Audit(), uow: uow})
+	})
+}
+
+func (s *TeamServiceImpl) recordTeamChange(action string, teamID uint, before, after json.RawMessage) error {
+	return recordChange(s.auditRepo, action, models.AuditEntityTeam, teamID, 0, before, after)
 }
 
 func (s *TeamServiceImpl) CreateTeam(team *models.Team) error {
-	return s.teamRepo.CreateTeam(team)
+	return s.inTransaction(func(tx *TeamServiceImpl) error {
+		if err := tx.validateTeam(team); err != nil {
+			return err
+		}
+		if err := tx.teamRepo.CreateTeam(team); err != nil {
+			return err
+		}
+		return tx.recordTeamChange(AuditTeamCreated, team.ID, nil, snapshot(team))
+	})
+}
+
+func (s *TeamServiceImpl) FindTeams(filter repositories.TeamFilter, page repositories.Page) ([]*models.Team, int64, error) {
+	return s.teamRepo.FindTeams(filter, page)
 }
 
 func (s *TeamServiceImpl) GetTeamByID(id uint) (*models.Team, error) {
 	return s.teamRepo.GetTeamByID(id)
 }
 
+func (s *TeamServiceImpl) GetTeamByName(name string) (*models.Team, error) {
+	return s.teamRepo.GetTeamByName(strings.TrimSpace(name))
+}
+
+// UpdateTeam overwrites the name, strengths and home ground of an existing team, team is refreshed with the stored
+// values. The unplayed home matches of the team move to its new ground.
 func (s *TeamServiceImpl) UpdateTeam(team *models.Team) error {
-	return s.teamRepo.UpdateTeam(team)
+	return s.inTransaction(func(tx *TeamServiceImpl) error {
+		return tx.updateTeam(team)
+	})
+}
+
+func (s *TeamServiceImpl) updateTeam(team *models.Team) error {
+	// Saving a team that does not exist would silently create it
+	existing, err := s.teamRepo.GetTeamByID(team.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateTeam(team); err != nil {
+		return err
+	}
+
+	before := snapshot(existing)
+	existing.Name = team.Name
+	existing.AttackStrength = team.AttackStrength
+	existing.DefenseStrength = team.DefenseStrength
+	groundChanged := !sameVenue(existing.HomeVenueID, team.HomeVenueID)
+	existing.HomeVenueID = team.HomeVenueID
+	if err := s.teamRepo.UpdateTeam(existing); err != nil {
+		return err
+	}
+	if groundChanged {
+		if err := s.uow.Matches().MoveHomeMatches(existing.ID, existing.HomeVenueID); err != nil {
+			return err
+		}
+	}
+
+	*team = *existing
+	return s.recordTeamChange(AuditTeamUpdated, team.ID, before, snapshot(team))
 }
 
 func (s *TeamServiceImpl) DeleteTeam(id uint) error {
+	return s.inTransaction(func(tx *TeamServiceImpl) error {
+		return tx.deleteTeam(id)
+	})
+}
+
+func (s *TeamServiceImpl) deleteTeam(id uint) error {
+	team, err := s.teamRepo.GetTeamByID(id)
+	if err != nil {
+		return err
+	}
+
 	leagues, err := s.leagueRepo.GetLeaguesByTeamID(id)
 	if err != nil {
 		return err
@@ -43,11 +134,14 @@ func (s *TeamServiceImpl) DeleteTeam(id uint) error {
 
 	for _, league := range leagues {
 		if league.IsActive() {
-			return errors.New("cannot delete team that is part of an active league")
+			return apperrors.Conflict("team_in_active_league", "cannot delete team that is part of an active league")
 		}
 	}
 
-	return s.teamRepo.DeleteTeam(id)
+	if err := s.teamRepo.DeleteTeam(id); err != nil {
+		return err
+	}
+	return s.recordTeamChange(AuditTeamDeleted, id, snapshot(team), nil)
 }
 
 func (s *TeamServiceImpl) GetAllTeams() ([]*models.Team, error) {
@@ -62,3 +156,43 @@ func (s *TeamServiceImpl) GetTeamsByLeague(leagueID uint) ([]models.Team, error)
 
 	return league.Teams, nil
 }
+
+// validateTeam checks the name and strength bounds and the home ground of a team and that no other team has the
+// same name
+func (s *TeamServiceImpl) validateTeam(team *models.Team) error {
+	team.Name = strings.TrimSpace(team.Name)
+
+	var fields []apperrors.FieldError
+	if team.Name == "" {
+		fields = append(fields, apperrors.FieldError{Field: "name", Message: "is required"})
+	}
+	strengthMessage := fmt.Sprintf("must be between %d and %d", models.MinStrength, models.MaxStrength)
+	if team.AttackStrength < models.MinStrength || team.AttackStrength > models.MaxStrength {
+		fields = append(fields, apperrors.FieldError{Field: "attack_strength", Message: strengthMessage})
+	}
+	if team.DefenseStrength < models.MinStrength || team.DefenseStrength > models.MaxStrength {
+		fields = append(fields, apperrors.FieldError{Field: "defense_strength", Message: strengthMessage})
+	}
+	if team.HomeVenueID != nil {
+		_, err := s.uow.Venues().GetVenueByID(*team.HomeVenueID)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			fields = append(fields, apperrors.FieldError{Field: "home_venue_id", Message: "must be an existing venue"})
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid team").WithFields(fields...)
+	}
+
+	existing, err := s.teamRepo.GetTeamByName(team.Name)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != team.ID {
+		return apperrors.Conflict("team_name_taken", "a team named %q already exists", team.Name).
+			WithFields(apperrors.FieldError{Field: "name", Message: "must be unique"})
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
 	"github.com/stretchr/testify/assert"
@@ -13,13 +14,13 @@ func TestTeamService(t *testing.T) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	assert.NoError(t, err)
 
-	err = db.AutoMigrate(&models.Team{}, &models.League{})
+	err = db.AutoMigrate(&models.Team{}, &models.League{}, &models.AuditEntry{})
 	assert.NoError(t, err)
 
 	repo := repositories.NewTeamRepository(db)
 	repoLeague := repositories.NewLeagueRepository(db)
 
-	service := NewTeamService(repo, repoLeague)
+	service := NewTeamService(repo, repoLeague, repositories.NewUnitOfWork(db))
 
 	// Create
 	team := &models.Team{Name: "Team A", AttackStrength: 80, DefenseStrength: 70}
@@ -43,6 +44,19 @@ func TestTeamService(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Team A Updated", updatedTeam.Name)
 
+	// Names are unique, ignoring case
+	err = service.CreateTeam(&models.Team{Name: "team a updated", AttackStrength: 50, DefenseStrength: 50})
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	// Every invalid field is reported
+	err = service.CreateTeam(&models.Team{Name: " ", AttackStrength: -1, DefenseStrength: 10000})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Len(t, apperrors.FieldsOf(err), 3)
+
+	// Updating a team that does not exist must not create it
+	err = service.UpdateTeam(&models.Team{Model: gorm.Model{ID: 999}, Name: "Ghost", AttackStrength: 50, DefenseStrength: 50})
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
 	// Delete
 	err = service.DeleteTeam(team.ID)
 	assert.NoError(t, err)
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type VenueService interface {
+	CreateVenue(venue *models.Venue) error
+	GetVenueByID(id uint) (*models.Venue, error)
+	UpdateVenue(venue *models.Venue) error
+	DeleteVenue(id uint) error
+	FindVenues(filter repositories.VenueFilter, page repositories.Page) ([]*models.Venue, int64, error)
+	WithScope(scope repositories.Scope) VenueService
+}
+
+type VenueServiceImpl struct {
+	venueRepo repositories.VenueRepository
+	auditRepo repositories.AuditRepository
+	uow       repositories.UnitOfWork
+}
+
+func NewVenueService(venueRepo repositories.VenueRepository, uow repositories.UnitOfWork) VenueService {
+	return &VenueServiceImpl{venueRepo: venueRepo, auditRepo: uow.Audit(), uow: uow}
+}
+
+// WithScope returns a service that only sees and changes the venues of the scope's organization
+func (s *VenueServiceImpl) WithScope(scope repositories.Scope) VenueService {
+	return &VenueServiceImpl{
+		venueRepo: s.venueRepo.WithScope(scope),
+		auditRepo: s.auditRepo.WithScope(scope),
+		uow:       s.uow.WithScope(scope),
+	}
+}
+
+// inTransaction runs fn against a copy of the service whose repositories all share one transaction
+func (s *VenueServiceImpl) inTransaction(fn func(tx *VenueServiceImpl) error) error {
+	return s.uow.Transaction(func(uow repositories.UnitOfWork) error {
+		return fn(&VenueServiceImpl{venueRepo: uow.Venues(), auditRepo: uow.Audit(), uow: uow})
+	})
+}
+
+func (s *VenueServiceImpl) recordVenueChange(action string, venueID uint, before, after json.RawMessage) error {
+	return recordChange(s.auditRepo, action, models.AuditEntityVenue, venueID, 0, before, after)
+}
+
+func (s *VenueServiceImpl) CreateVenue(venue *models.Venue) error {
+	return s.inTransaction(func(tx *VenueServiceImpl) error {
+		if err := validateVenue(venue); err != nil {
+			return err
+		}
+		if err := tx.venueRepo.CreateVenue(venue); err != nil {
+			return err
+		}
+		return tx.recordVenueChange(AuditVenueCreated, venue.ID, nil, snapshot(venue))
+	})
+}
+
+func (s *VenueServiceImpl) GetVenueByID(id uint) (*models.Venue, error) {
+	return s.venueRepo.GetVenueByID(id)
+}
+
+func (s *VenueServiceImpl) FindVenues(filter repositories.VenueFilter, page repositories.Page) ([]*models.Venue, int64, error) {
+	return s.venueRepo.FindVenues(filter, page)
+}
+
+// UpdateVenue overwrites an existing venue, venue is refreshed with the stored values
+func (s *VenueServiceImpl) UpdateVenue(venue *models.Venue) error {
+	return s.inTransaction(func(tx *VenueServiceImpl) error {
+		existing, err := tx.venueRepo.GetVenueByID(venue.ID)
+		if err != nil {
+			return err
+		}
+		if err := validateVenue(venue); err != nil {
+			return err
+		}
+
+		before := snapshot(existing)
+		existing.Name = venue.Name
+		existing.City = venue.City
+		existing.Capacity = venue.Capacity
+		existing.Latitude = venue.Latitude
+		existing.Longitude = venue.Longitude
+		if err := tx.venueRepo.UpdateVenue(existing); err != nil {
+			return err
+		}
+
+		*venue = *existing
+		return tx.recordVenueChange(AuditVenueUpdated, venue.ID, before, snapshot(venue))
+	})
+}
+
+// DeleteVenue removes a venue that is nobody's home ground and hosts no unplayed match. Played matches keep
+// referring to it.
+func (s *VenueServiceImpl) DeleteVenue(id uint) error {
+	return s.inTransaction(func(tx *VenueServiceImpl) error {
+		venue, err := tx.venueRepo.GetVenueByID(id)
+		if err != nil {
+			return err
+		}
+
+		_, teams, err := tx.uow.Teams().FindTeams(repositories.TeamFilter{VenueID: id}, repositories.Page{Limit: 1})
+		if err != nil {
+			return err
+		}
+		if teams > 0 {
+			return apperrors.Conflict("venue_in_use", "venue %d is the home ground of %d teams", id, teams)
+		}
+		for _, status := range []string{models.MatchStatusScheduled, models.MatchStatusPostponed} {
+			_, matches, err := tx.uow.Matches().FindMatches(repositories.MatchFilter{VenueID: id, Status: status}, repositories.Page{Limit: 1})
+			if err != nil {
+				return err
+			}
+			if matches > 0 {
+				return apperrors.Conflict("venue_in_use", "venue %d hosts %d %s matches", id, matches, status)
+			}
+		}
+
+		if err := tx.venueRepo.DeleteVenue(id); err != nil {
+			return err
+		}
+		return tx.recordVenueChange(AuditVenueDeleted, id, snapshot(venue), nil)
+	})
+}
+
+// validateVenue trims the names of a venue and checks its capacity and coordinates
+func validateVenue(venue *models.Venue) error {
+	venue.Name = strings.TrimSpace(venue.Name)
+	venue.City = strings.TrimSpace(venue.City)
+
+	var fields []apperrors.FieldError
+	if venue.Name == "" {
+		fields = append(fields, apperrors.FieldError{Field: "name", Message: "is required"})
+	}
+	if venue.Capacity < models.MinCapacity || venue.Capacity > models.MaxCapacity {
+		fields = append(fields, apperrors.FieldError{Field: "capacity", Message: fmt.Sprintf("must be between %d and %d", models.MinCapacity, models.MaxCapacity)})
+	}
+	if venue.Latitude < -90 || venue.Latitude > 90 {
+		fields = append(fields, apperrors.FieldError{Field: "latitude", Message: "must be between -90 and 90"})
+	}
+	if venue.Longitude < -180 || venue.Longitude > 180 {
+		fields = append(fields, apperrors.FieldError{Field: "longitude", Message: "must be between -180 and 180"})
+	}
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid venue").WithFields(fields...)
+	}
+	return nil
+}
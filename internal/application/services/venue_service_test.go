@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVenues(t *testing.T) {
+	db, leagueService, teamService := setupLeagueServiceTest()
+	require.NoError(t, db.AutoMigrate(&models.Venue{}))
+	venueService := services.NewVenueService(repositories.NewVenueRepository(db), repositories.NewUnitOfWork(db))
+
+	err := venueService.CreateVenue(&models.Venue{Name: " ", Capacity: 0, Latitude: 91})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Len(t, apperrors.FieldsOf(err), 3)
+
+	ground := &models.Venue{Name: "Riverside Park", City: "Leeds", Capacity: 30000, Latitude: 53.78, Longitude: -1.57}
+	neutral := &models.Venue{Name: "National Stadium", City: "London", Capacity: 10000}
+	newGround := &models.Venue{Name: "Hill Road", City: "Leeds", Capacity: 20000}
+	for _, venue := range []*models.Venue{ground, neutral, newGround} {
+		require.NoError(t, venueService.CreateVenue(venue))
+	}
+
+	league := createTestLeagueForService(leagueService, teamService)
+	league, err = leagueService.GetLeagueByID(league.ID)
+	require.NoError(t, err)
+	home := league.Teams[0]
+
+	missing := uint(99)
+	home.HomeVenueID = &missing
+	err = teamService.UpdateTeam(&home)
+	assert.Equal(t, "home_venue_id", apperrors.FieldsOf(err)[0].Field)
+	home.HomeVenueID = &ground.ID
+	require.NoError(t, teamService.UpdateTeam(&home))
+
+	// Fixtures are played at the home team's ground
+	require.NoError(t, leagueService.StartLeague(league.ID))
+	var first, second, away models.Match
+	require.NoError(t, db.Where("league_id = ? AND home_team_id = ?", league.ID, home.ID).Order("week").First(&first).Error)
+	require.NoError(t, db.Where("league_id = ? AND home_team_id = ? AND week > ?", league.ID, home.ID, first.Week).Order("week").First(&second).Error)
+	require.NoError(t, db.Where("league_id = ? AND home_team_id <> ? AND week = ?", league.ID, home.ID, first.Week).First(&away).Error)
+	assert.Equal(t, ground.ID, *first.VenueID)
+	assert.Nil(t, away.VenueID, "teams without a ground play nowhere in particular")
+
+	moved, err := leagueService.SetMatchVenue(first.ID, &neutral.ID)
+	require.NoError(t, err)
+	assert.True(t, moved.VenueFixed)
+	_, err = leagueService.SetMatchVenue(second.ID, &missing)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	// A new ground takes the unplayed home matches along, except those moved on their own
+	home.HomeVenueID = &newGround.ID
+	require.NoError(t, teamService.UpdateTeam(&home))
+	stored, err := leagueService.GetMatchByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, newGround.ID, *stored.VenueID)
+	stored, err = leagueService.GetMatchByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, neutral.ID, *stored.VenueID)
+
+	assert.Equal(t, "venue_in_use", apperrors.CodeOf(venueService.DeleteVenue(newGround.ID)))
+	assert.Equal(t, "venue_in_use", apperrors.CodeOf(venueService.DeleteVenue(neutral.ID)))
+	assert.NoError(t, venueService.DeleteVenue(ground.ID))
+
+	// Only matches at a venue draw a crowd, and no more than it holds
+	for week := 1; week <= first.Week; week++ {
+		require.NoError(t, leagueService.AdvanceWeek(league.ID))
+	}
+	played, err := leagueService.GetMatchByID(first.ID)
+	require.NoError(t, err)
+	assert.Greater(t, played.Attendance, 0)
+	assert.LessOrEqual(t, played.Attendance, neutral.Capacity)
+	played, err = leagueService.GetMatchByID(away.ID)
+	require.NoError(t, err)
+	assert.Zero(t, played.Attendance)
+
+	_, err = leagueService.SetMatchVenue(first.ID, nil)
+	assert.Equal(t, "match_already_played", apperrors.CodeOf(err))
+	assert.NoError(t, venueService.DeleteVenue(neutral.ID), "played matches do not hold on to their venue")
+
+	// Clearing the venue of a match sends it back to the home team's ground
+	_, err = leagueService.SetMatchVenue(second.ID, &newGround.ID)
+	require.NoError(t, err)
+	reset, err := leagueService.SetMatchVenue(second.ID, nil)
+	require.NoError(t, err)
+	assert.False(t, reset.VenueFixed)
+	assert.Equal(t, newGround.ID, *reset.VenueID)
+}
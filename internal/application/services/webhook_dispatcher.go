@@ -0,0 +1,313 @@
+package services
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookEventHeader     = "X-LeagueManager-Event"
+	WebhookDeliveryHeader  = "X-LeagueManager-Delivery"
+	WebhookTimestampHeader = "X-LeagueManager-Timestamp"
+	WebhookSignatureHeader = "X-LeagueManager-Signature"
+)
+
+// WebhookPingEvent is the type of the test event sent by a ping, it is only ever sent to the pinged subscription
+const WebhookPingEvent = "webhook.ping"
+
+// dueDeliveriesBatch is the number of due deliveries attempted per round
+const dueDeliveriesBatch = 50
+
+// pendingEventsSize is the number of published events that may wait for their deliveries to be queued, events
+// published while it is full are dropped
+const pendingEventsSize = 1024
+
+// WebhookSettings tune how deliveries are sent and retried
+type WebhookSettings struct {
+	MaxAttempts    int           // Attempts before a delivery is given up as failed
+	InitialBackoff time.Duration // Wait before the second attempt, doubled for every further attempt
+	MaxBackoff     time.Duration // Longest wait between two attempts
+	Timeout        time.Duration // Time a receiver has to answer an attempt
+	PollInterval   time.Duration // How often the worker looks for due retries
+
+	// Networks receivers may be in although they are loopback, private or link-local, which are refused otherwise,
+	// e.g. 127.0.0.0/8 to test against a local receiver
+	AllowedNetworks []netip.Prefix
+}
+
+// DefaultWebhookSettings returns the settings used when nothing is configured
+func DefaultWebhookSettings() WebhookSettings {
+	return WebhookSettings{
+		MaxAttempts:    6,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     time.Hour,
+		Timeout:        10 * time.Second,
+		PollInterval:   5 * time.Second,
+	}
+}
+
+// WebhookDispatcher turns the events published on the bus into deliveries for the matching subscriptions
+// and sends them. Every delivery is kept in the log with the outcome of its latest attempt.
+type WebhookDispatcher struct {
+	webhooks repositories.WebhookRepository
+	settings WebhookSettings
+	client   *http.Client
+	now      func() time.Time
+	wake     chan struct{}
+	pending  chan events.Event
+}
+
+// NewWebhookDispatcher creates a dispatcher subscribed to the bus. The deliveries of a published event are queued and
+// sent by the workers started with Start, the publisher does not wait on either.
+func NewWebhookDispatcher(webhooks repositories.WebhookRepository, bus events.Bus, settings WebhookSettings) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		webhooks: webhooks,
+		settings: settings,
+		client:   newWebhookClient(settings),
+		now:      time.Now,
+		wake:     make(chan struct{}, 1),
+		pending:  make(chan events.Event, pendingEventsSize),
+	}
+	bus.Subscribe(d.handle)
+	return d
+}
+
+// SetClock replaces the clock the dispatcher schedules attempts with, for tests
+func (d *WebhookDispatcher) SetClock(now func() time.Time) {
+	d.now = now
+}
+
+// Start runs the workers that queue the deliveries of published events and send them until ctx is done. They are
+// apart so that a slow receiver does not hold up the queueing of new events.
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-d.pending:
+				d.queue(event)
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(d.settings.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.wake:
+			case <-ticker.C:
+			}
+			if err := d.DeliverDue(); err != nil {
+				logrus.WithError(err).Error("Failed to send webhook deliveries")
+			}
+		}
+	}()
+}
+
+// QueuePending queues the deliveries of the events published so far. The worker does so as they come, a tool that
+// exits without running it calls this last so the server sends them.
+func (d *WebhookDispatcher) QueuePending() {
+	for {
+		select {
+		case event := <-d.pending:
+			d.queue(event)
+		default:
+			return
+		}
+	}
+}
+
+// DeliverDue queues the deliveries of the events published so far and attempts every delivery whose next attempt is
+// due
+func (d *WebhookDispatcher) DeliverDue() error {
+	d.QueuePending()
+	for {
+		due, err := d.webhooks.DueDeliveries(d.now(), dueDeliveriesBatch)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range due {
+			if err := d.attempt(delivery); err != nil {
+				return err
+			}
+		}
+		if len(due) < dueDeliveriesBatch {
+			return nil
+		}
+	}
+}
+
+// handle passes the event on to the worker. It runs on the publisher's goroutine, which must not wait on the database.
+func (d *WebhookDispatcher) handle(event events.Event) {
+	select {
+	case d.pending <- event:
+	default:
+		logrus.WithField("event", event.ID).Error("Too many events are waiting for their webhooks, the event is dropped")
+	}
+}
+
+// queue adds a delivery of the event for every active subscription of its league that wants it
+func (d *WebhookDispatcher) queue(event events.Event) {
+	webhooks := d.webhooks.WithScope(repositories.Scope{OrganizationID: event.OrganizationID})
+	subscriptions, err := webhooks.GetSubscriptionsByLeague(event.LeagueID)
+	if err != nil {
+		logrus.WithError(err).WithField("event", event.ID).Error("Failed to find the webhooks of an event")
+		return
+	}
+
+	queued := false
+	for _, subscription := range subscriptions {
+		if !subscription.Active || !subscription.Wants(event.Type) {
+			continue
+		}
+		next := d.now()
+		if _, err := d.enqueue(subscription, event, &next); err != nil {
+			logrus.WithError(err).WithField("event", event.ID).Error("Failed to queue a webhook delivery")
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// enqueue adds a pending delivery of the event to the log, due at next or never if next is nil
+func (d *WebhookDispatcher) enqueue(subscription *models.WebhookSubscription, event events.Event, next *time.Time) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		OrganizationID: subscription.OrganizationID,
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  next,
+	}
+	return delivery, d.webhooks.CreateDelivery(delivery)
+}
+
+// deliverNow queues a delivery of the event and attempts it right away, failed attempts are retried by the worker
+func (d *WebhookDispatcher) deliverNow(subscription *models.WebhookSubscription, event events.Event) (*models.WebhookDelivery, error) {
+	delivery, err := d.enqueue(subscription, event, nil)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, d.attempt(delivery)
+}
+
+// attempt sends the delivery once and records the outcome. A failed attempt is scheduled again
+// with an exponential backoff, until the delivery runs out of attempts.
+func (d *WebhookDispatcher) attempt(delivery *models.WebhookDelivery) error {
+	webhooks := d.webhooks.WithScope(repositories.Scope{OrganizationID: delivery.OrganizationID})
+	subscription, err := webhooks.GetSubscriptionByID(delivery.SubscriptionID)
+	if err != nil {
+		// The subscription was deleted since the delivery was queued
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = err.Error()
+		return d.webhooks.UpdateDelivery(delivery)
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.send(subscription, delivery)
+	delivery.LastStatusCode = statusCode
+
+	now := d.now()
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	case delivery.Attempts >= d.settings.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = sendErr.Error()
+	default:
+		next := now.Add(d.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = sendErr.Error()
+	}
+	return d.webhooks.UpdateDelivery(delivery)
+}
+
+// backoff returns the wait after the given number of failed attempts
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	wait := d.settings.InitialBackoff
+	for i := 1; i < attempts && wait < d.settings.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.settings.MaxBackoff {
+		wait = d.settings.MaxBackoff
+	}
+	return wait
+}
+
+// send POSTs the payload of the delivery, any response other than 2xx is an error
+func (d *WebhookDispatcher) send(subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	request, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "LeagueManager-Webhooks")
+	request.Header.Set(WebhookEventHeader, delivery.EventType)
+	request.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, deliveryError(err)
+	}
+	defer response.Body.Close()
+	// Drain a little of the body so the connection can be reused, it is never kept
+	io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+
+	// The reason phrase is left out, it is the receiver's text as much as the body is
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("receiver answered %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// SignWebhookPayload returns the signature header value of a payload: "sha256=" followed by the hex HMAC-SHA256
+// of the timestamp, a dot and the payload, keyed with the subscription's secret
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
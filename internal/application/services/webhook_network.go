@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// errBlockedAddress is returned when a webhook URL leads to an address receivers may not have
+var errBlockedAddress = errors.New("the receiver's address is loopback, private or link-local")
+
+// reservedNetworks are the ranges that netip.Addr does not report as loopback, private, link-local or multicast but
+// must not be reached either
+var reservedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // This network
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved
+	netip.MustParsePrefix("fec0::/10"),      // Deprecated site-local
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which embeds any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo, likewise
+	netip.MustParsePrefix("2002::/16"),      // 6to4, likewise
+}
+
+// allowedAddress reports whether webhooks may be delivered to the address: public addresses always are, the others
+// only when one of the allowed networks holds them
+func (s WebhookSettings) allowedAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, network := range s.AllowedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowedHost reports whether the host of a webhook URL may be delivered to, as far as can be told without resolving
+// it. Names are resolved when a delivery is sent and their addresses checked then.
+func (s WebhookSettings) allowedHost(host string) bool {
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return s.allowedAddress(addr)
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return s.allowedAddress(netip.MustParseAddr("127.0.0.1"))
+	}
+	return true
+}
+
+// newWebhookClient returns the client deliveries are sent with. The address of every connection is checked once the
+// name is resolved, so a name cannot lead to a blocked address, and redirects are not followed: a receiver that
+// answers with one fails the attempt.
+func newWebhookClient(settings WebhookSettings) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: settings.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !settings.allowedAddress(addrPort.Addr()) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would connect on the dispatcher's behalf, out of reach of the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   settings.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// deliveryError describes why an attempt failed without repeating anything the receiver sent, the delivery log is
+// shown to the managers of the league
+func deliveryError(err error) error {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, errBlockedAddress):
+		return errBlockedAddress
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &urlErr) && urlErr.Timeout():
+		return errors.New("the receiver did not answer in time")
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("failed to resolve %s", dnsErr.Name)
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return fmt.Errorf("failed to connect to the receiver: %v", opErr.Err)
+	default:
+		return errors.New("failed to read the receiver's answer")
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// webhookSecretBytes is the size of the generated secrets before hex encoding
+const webhookSecretBytes = 32
+
+// WebhookService manages the webhook subscriptions of leagues and their delivery log
+type WebhookService interface {
+	CreateWebhook(leagueID uint, request *dto.WebhookRequest) (*dto.CreatedWebhook, error)
+	ListWebhooks(leagueID uint) ([]*models.WebhookSubscription, error)
+	DeleteWebhook(leagueID, webhookID uint) error
+	ListDeliveries(leagueID, webhookID uint, page repositories.Page) ([]*models.WebhookDelivery, int64, error)
+	PingWebhook(leagueID, webhookID uint) (*models.WebhookDelivery, error)
+	WithScope(scope repositories.Scope) WebhookService
+}
+
+type WebhookServiceImpl struct {
+	webhookRepo repositories.WebhookRepository
+	leagueRepo  repositories.LeagueRepository
+	dispatcher  *WebhookDispatcher
+}
+
+func NewWebhookService(webhookRepo repositories.WebhookRepository, leagueRepo repositories.LeagueRepository, dispatcher *WebhookDispatcher) WebhookService {
+	return &WebhookServiceImpl{webhookRepo: webhookRepo, leagueRepo: leagueRepo, dispatcher: dispatcher}
+}
+
+// WithScope returns a service that only sees and changes the webhooks of the scope's organization
+func (s *WebhookServiceImpl) WithScope(scope repositories.Scope) WebhookService {
+	return &WebhookServiceImpl{
+		webhookRepo: s.webhookRepo.WithScope(scope),
+		leagueRepo:  s.leagueRepo.WithScope(scope),
+		dispatcher:  s.dispatcher,
+	}
+}
+
+// CreateWebhook subscribes a URL to the events of a league
+func (s *WebhookServiceImpl) CreateWebhook(leagueID uint, request *dto.WebhookRequest) (*dto.CreatedWebhook, error) {
+	if _, err := s.leagueRepo.GetLeagueByID(leagueID); err != nil {
+		return nil, err
+	}
+	if err := validateWebhook(request, s.dispatcher.settings); err != nil {
+		return nil, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		generated := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(generated); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(generated)
+	}
+
+	subscription := &models.WebhookSubscription{
+		LeagueID: leagueID,
+		URL:      strings.TrimSpace(request.URL),
+		Secret:   secret,
+		Events:   request.Events,
+		Active:   true,
+	}
+	if err := s.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return &dto.CreatedWebhook{WebhookSubscription: *subscription, Secret: secret}, nil
+}
+
+func (s *WebhookServiceImpl) ListWebhooks(leagueID uint) ([]*models.WebhookSubscription, error) {
+	if _, err := s.leagueRepo.GetLeagueByID(leagueID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetSubscriptionsByLeague(leagueID)
+}
+
+// DeleteWebhook removes a subscription, its pending deliveries are given up when they are next attempted
+func (s *WebhookServiceImpl) DeleteWebhook(leagueID, webhookID uint) error {
+	if _, err := s.getWebhook(leagueID, webhookID); err != nil {
+		return err
+	}
+	return s.webhookRepo.DeleteSubscription(webhookID)
+}
+
+// ListDeliveries returns one page of the delivery log of a subscription and the total number of deliveries
+func (s *WebhookServiceImpl) ListDeliveries(leagueID, webhookID uint, page repositories.Page) ([]*models.WebhookDelivery, int64, error) {
+	if _, err := s.getWebhook(leagueID, webhookID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.FindDeliveries(webhookID, page)
+}
+
+// PingWebhook sends a webhook.ping event to the subscription right away and returns the delivery with its outcome
+func (s *WebhookServiceImpl) PingWebhook(leagueID, webhookID uint) (*models.WebhookDelivery, error) {
+	subscription, err := s.getWebhook(leagueID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	event := events.New(WebhookPingEvent, subscription.OrganizationID, leagueID, map[string]uint{"webhook_id": subscription.ID})
+	return s.dispatcher.deliverNow(subscription, event)
+}
+
+// getWebhook finds a subscription of the league
+func (s *WebhookServiceImpl) getWebhook(leagueID, webhookID uint) (*models.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.GetSubscriptionByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.LeagueID != leagueID {
+		return nil, apperrors.NotFound("webhook_not_found", "webhook %d not found in league %d", webhookID, leagueID)
+	}
+	return subscription, nil
+}
+
+// validateWebhook checks that the URL is an absolute http(s) URL whose host may be delivered to and that every event
+// type is known
+func validateWebhook(request *dto.WebhookRequest, settings WebhookSettings) error {
+	var fields []apperrors.FieldError
+
+	target, err := url.Parse(strings.TrimSpace(request.URL))
+	switch {
+	case err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "":
+		fields = append(fields, apperrors.FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	case !settings.allowedHost(target.Hostname()):
+		fields = append(fields, apperrors.FieldError{Field: "url", Message: "must not be a loopback, private or link-local address"})
+	}
+
+	for _, eventType := range request.Events {
+		if !events.IsType(eventType) {
+			fields = append(fields, apperrors.FieldError{
+				Field:   "events",
+				Message: "unknown event " + eventType + ", must be one of " + strings.Join(events.Types, ", "),
+			})
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperrors.Validation("validation_failed", "invalid webhook").WithFields(fields...)
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package services_test
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// webhookTest holds the services of a webhook test, all bound to the same bus
+type webhookTest struct {
+	db         *gorm.DB
+	bus        events.Bus
+	leagues    services.LeagueService
+	teams      services.TeamService
+	webhooks   services.WebhookService
+	dispatcher *services.WebhookDispatcher
+	now        time.Time
+}
+
+func setupWebhookTest(t *testing.T) *webhookTest {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{}, &models.AuditEntry{},
+		&models.FixtureConstraint{}, &models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.AdvancementSchedule{}))
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+
+	test := &webhookTest{db: db, bus: events.NewBus(), now: time.Date(2024, 8, 16, 19, 0, 0, 0, time.UTC)}
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	test.leagues = services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, test.bus)
+	test.teams = services.NewTeamService(teamRepo, leagueRepo, uow)
+
+	settings := services.DefaultWebhookSettings()
+	settings.MaxAttempts = 3
+	settings.Timeout = 2 * time.Second
+	// The receivers of the tests listen on the loopback interface
+	settings.AllowedNetworks = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+	webhookRepo := repositories.NewWebhookRepository(db)
+	test.dispatcher = services.NewWebhookDispatcher(webhookRepo, test.bus, settings)
+	test.dispatcher.SetClock(func() time.Time { return test.now })
+	test.webhooks = services.NewWebhookService(webhookRepo, leagueRepo, test.dispatcher)
+	return test
+}
+
+// receiver is a local webhook endpoint that answers with the queued status codes, then 200
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestLeagueEventsArePublishedAfterCommit(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	leagues := test.leagues.WithScope(scope)
+
+	var published []events.Event
+	unsubscribe := test.bus.Subscribe(func(event events.Event) { published = append(published, event) })
+	defer unsubscribe()
+
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+	assert.Empty(t, published, "creating a league publishes nothing")
+
+	// A change that fails publishes nothing
+	stale := uint(99)
+	assert.Error(t, leagues.StartLeague(league.ID, dto.LeaguePrecondition{Version: &stale}))
+	assert.Empty(t, published)
+
+	require.NoError(t, leagues.StartLeague(league.ID))
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+	matches, err := leagues.ViewMatchResults(league.ID)
+	require.NoError(t, err)
+	home, away := 4, 4
+	require.NoError(t, leagues.EditMatchResults(matches[0].ID, &dto.MatchResultRequest{HomeTeamScore: &home, AwayTeamScore: &away}))
+
+	var types []string
+	for _, event := range published {
+		types = append(types, event.Type)
+		assert.Equal(t, uint(1), event.OrganizationID)
+		assert.Equal(t, league.ID, event.LeagueID)
+		assert.NotEmpty(t, event.ID)
+	}
+	assert.Equal(t, []string{events.LeagueStarted, events.WeekAdvanced, events.MatchResultEdited}, types)
+
+	// The played matches are sent with their IDs
+	var week struct {
+		Week    int            `json:"week"`
+		Matches []models.Match `json:"matches"`
+	}
+	data, _ := json.Marshal(published[1].Data)
+	require.NoError(t, json.Unmarshal(data, &week))
+	assert.Equal(t, 1, week.Week)
+	if assert.Len(t, week.Matches, 2) {
+		assert.NotZero(t, week.Matches[0].ID)
+	}
+
+	// Playing the rest of the season announces every week, advancing past the last one finishes the season
+	published = nil
+	require.NoError(t, leagues.PlayAllMatches(league.ID))
+	assert.Len(t, published, models.TotalWeeks-2)
+	published = nil
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+	types = nil
+	for _, event := range published {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{events.WeekAdvanced, events.SeasonFinished}, types)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	leagues := test.leagues.WithScope(scope)
+	webhooks := test.webhooks.WithScope(scope)
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+
+	// The receiver fails the first attempt
+	receiver := newReceiver(t, http.StatusInternalServerError)
+	webhook, err := webhooks.CreateWebhook(league.ID, &dto.WebhookRequest{URL: receiver.URL, Events: []string{events.LeagueStarted}})
+	require.NoError(t, err)
+	assert.Len(t, webhook.Secret, 64, "a secret is generated")
+	other := newReceiver(t)
+	_, err = webhooks.CreateWebhook(league.ID, &dto.WebhookRequest{URL: other.URL, Events: []string{events.SeasonFinished}, Secret: "s3cret"})
+	require.NoError(t, err)
+
+	require.NoError(t, leagues.StartLeague(league.ID))
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+
+	// The publisher does not wait on the dispatcher, the deliveries are queued once it gets to the events
+	_, queued, err := webhooks.ListDeliveries(league.ID, webhook.ID, repositories.Page{})
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+	require.NoError(t, test.dispatcher.DeliverDue())
+
+	require.Len(t, receiver.requests, 1)
+	assert.Empty(t, other.requests, "webhooks only receive the events they subscribed to")
+
+	deliveries, total, err := webhooks.ListDeliveries(league.ID, webhook.ID, repositories.Page{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	delivery := deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	assert.Contains(t, delivery.LastError, "500")
+	if assert.NotNil(t, delivery.NextAttemptAt) {
+		assert.Equal(t, test.now.Add(services.DefaultWebhookSettings().InitialBackoff), delivery.NextAttemptAt.UTC())
+	}
+
+	// Nothing is retried before the backoff has passed
+	require.NoError(t, test.dispatcher.DeliverDue())
+	assert.Len(t, receiver.requests, 1)
+
+	test.now = test.now.Add(time.Minute)
+	require.NoError(t, test.dispatcher.DeliverDue())
+	require.Len(t, receiver.requests, 2)
+
+	deliveries, _, err = webhooks.ListDeliveries(league.ID, webhook.ID, repositories.Page{})
+	require.NoError(t, err)
+	delivery = deliveries[0]
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// The receiver can check the signature with the secret it was given
+	request, body := receiver.requests[1], receiver.bodies[1]
+	assert.Equal(t, events.LeagueStarted, request.Header.Get(services.WebhookEventHeader))
+	assert.Equal(t, delivery.EventID, request.Header.Get(services.WebhookDeliveryHeader))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	timestamp := request.Header.Get(services.WebhookTimestampHeader)
+	assert.Equal(t, services.SignWebhookPayload(webhook.Secret, timestamp, body), request.Header.Get(services.WebhookSignatureHeader))
+	assert.NotEqual(t, services.SignWebhookPayload("wrong", timestamp, body), request.Header.Get(services.WebhookSignatureHeader))
+
+	var event events.Event
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, events.LeagueStarted, event.Type)
+	assert.Equal(t, league.ID, event.LeagueID)
+}
+
+func TestWebhookWorker(t *testing.T) {
+	test := setupWebhookTest(t)
+	// The worker and the test share the in-memory database, which lives on a single connection
+	sqlDB, err := test.db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	scope := repositories.Scope{OrganizationID: 1}
+	leagues := test.leagues.WithScope(scope)
+	league := createTestLeagueForService(leagues, test.teams.WithScope(scope))
+
+	receiver := newReceiver(t)
+	_, err = test.webhooks.WithScope(scope).CreateWebhook(league.ID, &dto.WebhookRequest{URL: receiver.URL, Events: []string{events.LeagueStarted}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	test.dispatcher.Start(ctx)
+
+	// The worker queues and sends the deliveries of the events as they are published
+	require.NoError(t, leagues.StartLeague(league.ID))
+	assert.Eventually(t, func() bool {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		return len(receiver.requests) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWebhookDeliveryGivesUp(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	webhooks := test.webhooks.WithScope(scope)
+	league := createTestLeagueForService(test.leagues.WithScope(scope), test.teams.WithScope(scope))
+
+	receiver := newReceiver(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	webhook, err := webhooks.CreateWebhook(league.ID, &dto.WebhookRequest{URL: receiver.URL})
+	require.NoError(t, err)
+
+	// A ping is attempted right away, its retries are left to the worker
+	delivery, err := webhooks.PingWebhook(league.ID, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, services.WebhookPingEvent, delivery.EventType)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+
+	for i := 0; i < 5; i++ {
+		test.now = test.now.Add(time.Hour)
+		require.NoError(t, test.dispatcher.DeliverDue())
+	}
+	assert.Len(t, receiver.requests, 3, "no more than MaxAttempts attempts are made")
+
+	deliveries, _, err := webhooks.ListDeliveries(league.ID, webhook.ID, repositories.Page{})
+	require.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, models.WebhookDeliveryFailed, deliveries[0].Status)
+		assert.Equal(t, 3, deliveries[0].Attempts)
+		assert.Equal(t, http.StatusBadGateway, deliveries[0].LastStatusCode)
+		assert.Nil(t, deliveries[0].NextAttemptAt)
+	}
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	webhooks := test.webhooks.WithScope(scope)
+	league := createTestLeagueForService(test.leagues.WithScope(scope), test.teams.WithScope(scope))
+
+	_, err := webhooks.CreateWebhook(league.ID, &dto.WebhookRequest{URL: "ftp://example.com/hook", Events: []string{"league.exploded"}})
+	var appErr *apperrors.Error
+	if assert.True(t, errors.As(err, &appErr)) {
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
+		assert.Len(t, appErr.Fields, 2)
+	}
+
+	_, err = webhooks.CreateWebhook(league.ID+1, &dto.WebhookRequest{URL: "https://example.com/hook"})
+	assert.True(t, errors.Is(err, apperrors.ErrNotFound))
+
+	webhook, err := webhooks.CreateWebhook(league.ID, &dto.WebhookRequest{URL: "https://example.com/hook", Secret: "s3cret"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", webhook.Secret)
+
+	// Another organization can neither see nor delete the webhook
+	foreign := test.webhooks.WithScope(repositories.Scope{OrganizationID: 2})
+	assert.True(t, errors.Is(foreign.DeleteWebhook(league.ID, webhook.ID), apperrors.ErrNotFound))
+
+	listed, err := webhooks.ListWebhooks(league.ID)
+	require.NoError(t, err)
+	if assert.Len(t, listed, 1) {
+		encoded, _ := json.Marshal(listed[0])
+		assert.NotContains(t, string(encoded), "s3cret", "the secret is never listed")
+	}
+
+	require.NoError(t, webhooks.DeleteWebhook(league.ID, webhook.ID))
+	listed, err = webhooks.ListWebhooks(league.ID)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestWebhookReceiverAddresses(t *testing.T) {
+	test := setupWebhookTest(t)
+	scope := repositories.Scope{OrganizationID: 1}
+	league := createTestLeagueForService(test.leagues.WithScope(scope), test.teams.WithScope(scope))
+
+	// Without an allow-list, nothing inside the server's own network can be subscribed
+	webhookRepo := repositories.NewWebhookRepository(test.db)
+	leagueRepo := repositories.NewLeagueRepository(test.db)
+	strict := services.NewWebhookService(webhookRepo, leagueRepo, services.NewWebhookDispatcher(webhookRepo, events.NewBus(), services.DefaultWebhookSettings())).WithScope(scope)
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://[::1]/hook", "http://10.0.0.7/hook",
+		"http://169.254.169.254/latest/meta-data", "http://[::ffff:192.168.1.1]/hook", "http://0.0.0.0/hook",
+	} {
+		_, err := strict.CreateWebhook(league.ID, &dto.WebhookRequest{URL: target})
+		assert.ErrorIs(t, err, apperrors.ErrValidation, target)
+	}
+
+	// A name is only resolved when a delivery is sent, the address it leads to is refused then
+	receiver := newReceiver(t)
+	webhook, err := test.webhooks.WithScope(scope).CreateWebhook(league.ID, &dto.WebhookRequest{URL: receiver.URL})
+	require.NoError(t, err)
+	delivery, err := strict.PingWebhook(league.ID, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, "loopback")
+	assert.Empty(t, receiver.requests)
+
+	// Redirects are not followed and what the receiver answers is not repeated
+	target := newReceiver(t)
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", target.URL)
+		w.WriteHeader(http.StatusFound)
+		io.WriteString(w, "internal details")
+	}))
+	defer redirect.Close()
+	webhook, err = test.webhooks.WithScope(scope).CreateWebhook(league.ID, &dto.WebhookRequest{URL: redirect.URL})
+	require.NoError(t, err)
+	delivery, err = test.webhooks.WithScope(scope).PingWebhook(league.ID, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, delivery.LastStatusCode)
+	assert.Equal(t, "receiver answered 302", delivery.LastError)
+	assert.Empty(t, target.requests)
+}
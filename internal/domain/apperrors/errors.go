@@ -0,0 +1,106 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of domain errors. Every *Error matches exactly one of them with errors.Is.
+var (
+	// ErrNotFound means the requested entity does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the request conflicts with the current state of the entity, e.g. a concurrent update
+	ErrConflict = errors.New("conflict")
+	// ErrValidation means the request itself is malformed or violates a constraint
+	ErrValidation = errors.New("validation failed")
+	// ErrPreconditionFailed means the entity is not yet in a state that allows the operation, e.g. a league that has not started
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// FieldError describes why a single field of a request was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error with a kind, a machine-readable code and an optional cause.
+// Fields lists the offending fields when the error was caused by invalid input.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is and errors.As
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// Wrap returns a copy of the error with err attached as its cause
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithFields returns a copy of the error listing the given offending fields
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	withFields := *e
+	withFields.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &withFields
+}
+
+// New creates an error of the given kind
+func New(kind error, code, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NotFound creates an ErrNotFound error
+func NotFound(code, format string, args ...interface{}) *Error {
+	return New(ErrNotFound, code, format, args...)
+}
+
+// Conflict creates an ErrConflict error
+func Conflict(code, format string, args ...interface{}) *Error {
+	return New(ErrConflict, code, format, args...)
+}
+
+// Validation creates an ErrValidation error
+func Validation(code, format string, args ...interface{}) *Error {
+	return New(ErrValidation, code, format, args...)
+}
+
+// PreconditionFailed creates an ErrPreconditionFailed error
+func PreconditionFailed(code, format string, args ...interface{}) *Error {
+	return New(ErrPreconditionFailed, code, format, args...)
+}
+
+// CodeOf returns the code of the first *Error in err's chain, or an empty string
+func CodeOf(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+// FieldsOf returns the offending fields of the first *Error in err's chain
+func FieldsOf(err error) []FieldError {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Fields
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("record not found")
+	err := NotFound("team_not_found", "team with id %d not found", 7).Wrap(cause)
+
+	assert.EqualError(t, err, "team with id 7 not found: record not found")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, ErrConflict)
+
+	// Kind and code survive further wrapping
+	wrapped := fmt.Errorf("failed to add team: %w", err)
+	assert.ErrorIs(t, wrapped, ErrNotFound)
+	assert.Equal(t, "team_not_found", CodeOf(wrapped))
+
+	// Sentinel values keep their identity
+	sentinel := Conflict("league_version_conflict", "league was modified concurrently")
+	assert.ErrorIs(t, fmt.Errorf("%w: expected version 1", sentinel), sentinel)
+	assert.ErrorIs(t, sentinel, ErrConflict)
+
+	assert.Equal(t, "", CodeOf(cause))
+
+	// Offending fields are reported alongside the code
+	invalid := Validation("validation_failed", "invalid team").WithFields(FieldError{Field: "name", Message: "is required"})
+	assert.ErrorIs(t, fmt.Errorf("failed to create team: %w", invalid), ErrValidation)
+	assert.Equal(t, []FieldError{{Field: "name", Message: "is required"}}, FieldsOf(invalid))
+	assert.Nil(t, FieldsOf(cause))
+}
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"LeagueManager/internal/domain/models"
+	"time"
+)
+
+// Archive formats this version reads and writes
+const (
+	ArchiveFormat  = "leaguemanager/league-archive"
+	ArchiveVersion = 1
+)
+
+// LeagueArchive is a complete league, portable between instances. Records refer to each other by IDs that are only
+// meaningful within the archive and are mapped to new IDs on restore. Checksum is the hex SHA-256 of the archive
+// encoded as JSON with an empty checksum, archives edited by hand may leave it out.
+type LeagueArchive struct {
+	Format      string               `json:"format"`
+	Version     int                  `json:"version"`
+	ExportedAt  time.Time            `json:"exported_at"`
+	Checksum    string               `json:"checksum,omitempty"`
+	League      ArchivedLeague       `json:"league"`
+	Teams       []ArchivedTeam       `json:"teams"`
+	Venues      []ArchivedVenue      `json:"venues"`
+	Matches     []ArchivedMatch      `json:"matches"`
+	Standings   []ArchivedStanding   `json:"standings"`
+	Constraints []ArchivedConstraint `json:"constraints"`
+}
+
+// ArchivedLeague holds the settings and progress of the archived league, TeamIDs its teams
+type ArchivedLeague struct {
+	Name        string                `json:"name"`
+	CurrentWeek int                   `json:"current_week"`
+	ResultMode  string                `json:"result_mode"`
+	Calendar    models.LeagueCalendar `json:"calendar"`
+	TeamIDs     []uint                `json:"team_ids"`
+}
+
+type ArchivedTeam struct {
+	ID              uint   `json:"id"`
+	Name            string `json:"name"`
+	AttackStrength  int    `json:"attack_strength"`
+	DefenseStrength int    `json:"defense_strength"`
+	HomeVenueID     *uint  `json:"home_venue_id,omitempty"`
+}
+
+// ArchivedVenue is a venue a team or a match of the league refers to
+type ArchivedVenue struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Capacity  int     `json:"capacity"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type ArchivedMatch struct {
+	HomeTeamID    uint       `json:"home_team_id"`
+	AwayTeamID    uint       `json:"away_team_id"`
+	HomeTeamScore int        `json:"home_team_score"`
+	AwayTeamScore int        `json:"away_team_score"`
+	Week          int        `json:"week"`
+	OriginalWeek  int        `json:"original_week,omitempty"`
+	Status        string     `json:"status"`
+	KickoffAt     *time.Time `json:"kickoff_at,omitempty"`
+	KickoffFixed  bool       `json:"kickoff_fixed,omitempty"`
+	VenueID       *uint      `json:"venue_id,omitempty"`
+	VenueFixed    bool       `json:"venue_fixed,omitempty"`
+	Attendance    int        `json:"attendance,omitempty"`
+}
+
+type ArchivedStanding struct {
+	TeamID         uint `json:"team_id"`
+	Points         int  `json:"points"`
+	Played         int  `json:"played"`
+	Wins           int  `json:"wins"`
+	Draws          int  `json:"draws"`
+	Losses         int  `json:"losses"`
+	GoalDifference int  `json:"goal_difference"`
+}
+
+type ArchivedConstraint struct {
+	Kind        string `json:"kind"`
+	TeamID      uint   `json:"team_id"`
+	OtherTeamID uint   `json:"other_team_id,omitempty"`
+	Week        int    `json:"week,omitempty"`
+}
+
+// RestoreReport tells what restoring an archive created and which existing records it used instead
+type RestoreReport struct {
+	League  *models.League   `json:"league"`
+	Teams   []RestoredRecord `json:"teams"`
+	Venues  []RestoredRecord `json:"venues"`
+	Matches int              `json:"matches"`
+}
+
+// RestoredRecord maps a record of an archive to the record it became
+type RestoredRecord struct {
+	ArchiveID uint   `json:"archive_id"`
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Matched   bool   `json:"matched"` // An existing record of the same name was used, it is left as it was
+}
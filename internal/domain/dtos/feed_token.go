@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// FeedTokenRequest is the body accepted when issuing a feed token, which lasts ExpiresInDays or 90 days if left out
+type FeedTokenRequest struct {
+	ExpiresInDays *int `json:"expires_in_days" binding:"omitempty,min=1,max=366"`
+}
+
+// FeedToken is a read-only token for calendar feeds and event streams, sent in their access_token query parameter
+type FeedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "LeagueManager/internal/domain/models"
+
+// FixtureReport tells how well the fixtures of a league honour its constraints. Breaks counts the times a team
+// plays at home, or away, two weeks in a row.
+type FixtureReport struct {
+	LeagueID    uint                    `json:"league_id"`
+	Drawn       bool                    `json:"drawn"` // False when the league has not started and the report is of a preview
+	Breaks      int                     `json:"breaks"`
+	Constraints int                     `json:"constraints"`
+	Unsatisfied []UnsatisfiedConstraint `json:"unsatisfied"`
+}
+
+// UnsatisfiedConstraint is a constraint the fixtures break, in the weeks listed. Constraints derived from shared
+// grounds have no ID.
+type UnsatisfiedConstraint struct {
+	Constraint models.FixtureConstraint `json:"constraint"`
+	Weeks      []int                    `json:"weeks"`
+	Reason     string                   `json:"reason"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "LeagueManager/internal/domain/models"
+
+// Fixtures are the matches of a calendar feed along with the names needed to describe them
+type Fixtures struct {
+	Name        string
+	Matches     []*models.Match
+	TeamNames   map[uint]string
+	LeagueNames map[uint]string
+	Venues      map[uint]*models.Venue // Venues deleted since are missing
+}
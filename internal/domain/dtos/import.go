@@ -0,0 +1,50 @@
+package dto
+
+import "LeagueManager/internal/domain/models"
+
+// ImportRequest is the body accepted when importing teams, leagues and their results in bulk, as JSON or parsed from
+// CSV. The teams are created, then the leagues with the teams they name, imported or existing, and the leagues with
+// results start with those results as their first weeks.
+//
+// Source fields are set by parsers that know where each entry came from, such as the line of a CSV file, and name the
+// entry in errors. Without them entries are named by their path in the request.
+type ImportRequest struct {
+	Teams   []ImportTeam   `json:"teams"`
+	Leagues []ImportLeague `json:"leagues"`
+}
+
+// ImportTeam is a team to create
+type ImportTeam struct {
+	Name            string `json:"name"`
+	AttackStrength  *int   `json:"attack_strength"`
+	DefenseStrength *int   `json:"defense_strength"`
+	HomeVenueID     *uint  `json:"home_venue_id"`
+	Source          string `json:"-"`
+}
+
+// ImportLeague is a league to create with the teams it names, simulated unless ResultMode says otherwise
+type ImportLeague struct {
+	Name        string         `json:"name"`
+	ResultMode  string         `json:"result_mode"`
+	Teams       []string       `json:"teams"`
+	Results     []ImportResult `json:"results"`
+	Source      string         `json:"-"`
+	TeamSources []string       `json:"-"` // The source of each of Teams
+}
+
+// ImportResult is a match played before the league was imported
+type ImportResult struct {
+	Week          int    `json:"week"`
+	HomeTeam      string `json:"home_team"`
+	AwayTeam      string `json:"away_team"`
+	HomeTeamScore int    `json:"home_team_score"`
+	AwayTeamScore int    `json:"away_team_score"`
+	Source        string `json:"-"`
+}
+
+// ImportReport lists what an import created
+type ImportReport struct {
+	Teams   []*models.Team   `json:"teams"`
+	Leagues []*models.League `json:"leagues"`
+	Results int              `json:"results"` // Matches recorded as played
+}
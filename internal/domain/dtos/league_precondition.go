@@ -0,0 +1,8 @@
+package dto
+
+// LeaguePrecondition carries the state a client expects a league to be in before mutating it.
+// Nil fields are not checked.
+type LeaguePrecondition struct {
+	Version *uint `json:"version"`
+	Week    *int  `json:"week"`
+}
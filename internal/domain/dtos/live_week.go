@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// Statuses of a live week
+const (
+	LiveWeekInProgress = "in_progress"
+	LiveWeekFinished   = "finished"
+	LiveWeekFailed     = "failed"
+	LiveWeekCancelled  = "cancelled" // Stopped before it was over, nothing was recorded
+)
+
+// LiveWeekRequest is the body accepted when playing a week live, the matches last DurationSeconds of wall-clock
+// time instead of 90 minutes, or the server's default duration if left out
+type LiveWeekRequest struct {
+	DurationSeconds *int `json:"duration_seconds" binding:"omitempty,min=1,max=7200"`
+}
+
+// LiveWeek is the state of a week being played live, as of Minute of its matches. Matches only hold the goals
+// scored up to that minute; the results are saved to the league once the week is finished.
+type LiveWeek struct {
+	LeagueID  uint        `json:"league_id"`
+	Week      int         `json:"week"`
+	Status    string      `json:"status"`
+	Minute    int         `json:"minute"`
+	StartedAt time.Time   `json:"started_at"`
+	EndsAt    time.Time   `json:"ends_at"`
+	Matches   []LiveMatch `json:"matches"`
+	Error     string      `json:"error,omitempty"` // Why the results could not be saved, for failed and cancelled weeks
+}
+
+// LiveMatch is the running score of a match played live
+type LiveMatch struct {
+	HomeTeamID    uint       `json:"home_team_id"`
+	AwayTeamID    uint       `json:"away_team_id"`
+	HomeTeamScore int        `json:"home_team_score"`
+	AwayTeamScore int        `json:"away_team_score"`
+	Goals         []LiveGoal `json:"goals"`
+}
+
+// LiveGoal is a goal of a live match along with the score it made
+type LiveGoal struct {
+	Minute        int  `json:"minute"`
+	TeamID        uint `json:"team_id"`
+	HomeTeamScore int  `json:"home_team_score"`
+	AwayTeamScore int  `json:"away_team_score"`
+}
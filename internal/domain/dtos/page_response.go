@@ -0,0 +1,10 @@
+package dto
+
+// PageResponse is one page of a list returned by the v2 API
+type PageResponse[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"` // Number of items matching the filters across all pages
+
+	// Next is the URL of the following page, empty on the last page
+	Next string `json:"next,omitempty"`
+}
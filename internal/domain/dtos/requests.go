@@ -0,0 +1,107 @@
+package dto
+
+import "time"
+
+// TeamRequest is the body accepted when creating or updating a team, a team without HomeVenueID has no ground
+type TeamRequest struct {
+	Name            string `json:"name" binding:"required,notblank,max=100"`
+	AttackStrength  *int   `json:"attack_strength" binding:"required,min=0,max=100"`
+	DefenseStrength *int   `json:"defense_strength" binding:"required,min=0,max=100"`
+	HomeVenueID     *uint  `json:"home_venue_id"`
+}
+
+// VenueRequest is the body accepted when creating or updating a venue
+type VenueRequest struct {
+	Name      string   `json:"name" binding:"required,notblank,max=100"`
+	City      string   `json:"city" binding:"max=100"`
+	Capacity  *int     `json:"capacity" binding:"required,min=1,max=200000"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+}
+
+// CreateLeagueRequest is the body accepted when creating a league, whose results are simulated unless ResultMode
+// says otherwise
+type CreateLeagueRequest struct {
+	Name       string `json:"name" binding:"required,notblank,max=100"`
+	ResultMode string `json:"result_mode" binding:"omitempty,oneof=simulated manual"`
+}
+
+// ResultModeRequest is the body accepted when choosing how the results of a league come about
+type ResultModeRequest struct {
+	ResultMode string `json:"result_mode" binding:"required,oneof=simulated manual"`
+}
+
+// MatchResultRequest is the body accepted when submitting the result of a match or editing that of a played one
+type MatchResultRequest struct {
+	HomeTeamScore *int `json:"home_team_score" binding:"required,min=0,max=99"`
+	AwayTeamScore *int `json:"away_team_score" binding:"required,min=0,max=99"`
+}
+
+// UpdateLeagueRequest is the body accepted when renaming a league
+type UpdateLeagueRequest struct {
+	Name string `json:"name" binding:"required,notblank,max=100"`
+}
+
+// WebhookRequest is the body accepted when subscribing to the events of a league. Events lists the event
+// types to receive, every event if empty. A random secret is generated when none is given.
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required,notblank,max=2000"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret" binding:"max=200"`
+}
+
+// ScheduleRequest is the body accepted when setting the advancement schedule of a league. Weekly schedules need
+// Weekday and Time, read in Timezone (UTC if empty); interval schedules need IntervalMinutes.
+// MissedRunPolicy decides what happens to the runs missed while the server was down, "once" if empty.
+type ScheduleRequest struct {
+	Kind            string `json:"kind" binding:"required,oneof=weekly interval"`
+	Weekday         string `json:"weekday"`
+	Time            string `json:"time"`
+	Timezone        string `json:"timezone" binding:"max=100"`
+	IntervalMinutes int    `json:"interval_minutes" binding:"min=0,max=525600"`
+	MissedRunPolicy string `json:"missed_run_policy" binding:"omitempty,oneof=skip once catch_up"`
+}
+
+// CalendarRequest is the body accepted when dating the weeks of a league. Week 1 kicks off on StartDate
+// (YYYY-MM-DD) at KickoffTime (HH:MM) in Timezone, UTC if empty, and every further week MatchdayIntervalDays
+// later, 7 if left out. An empty StartDate removes the calendar.
+type CalendarRequest struct {
+	StartDate            string `json:"start_date"`
+	KickoffTime          string `json:"kickoff_time"`
+	Timezone             string `json:"timezone" binding:"max=100"`
+	MatchdayIntervalDays int    `json:"matchday_interval_days" binding:"min=0,max=365"`
+}
+
+// KickoffRequest is the body accepted when moving the kickoff of a single match, a null KickoffAt puts the match
+// back on its league's calendar
+type KickoffRequest struct {
+	KickoffAt *time.Time `json:"kickoff_at"`
+}
+
+// RescheduleRequest is the body accepted when rescheduling a match into Week, from the current week of its league
+// on. Without a Week the match is played in the week of KickoffAt on the league's calendar. Without a KickoffAt it
+// kicks off when the calendar says its week does.
+type RescheduleRequest struct {
+	Week      int        `json:"week" binding:"min=0,max=38"`
+	KickoffAt *time.Time `json:"kickoff_at"`
+}
+
+// MatchVenueRequest is the body accepted when moving a single match to a venue, a null VenueID moves it back to the
+// home team's ground
+type MatchVenueRequest struct {
+	VenueID *uint `json:"venue_id"`
+}
+
+// FixtureConstraintRequest is one constraint of a ConstraintsRequest. OtherTeamID is needed by shared_venue and
+// derby constraints, Week by blackout and derby constraints.
+type FixtureConstraintRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=shared_venue blackout derby"`
+	TeamID      uint   `json:"team_id" binding:"required"`
+	OtherTeamID uint   `json:"other_team_id"`
+	Week        int    `json:"week"`
+}
+
+// ConstraintsRequest is the body accepted when replacing the fixture constraints of a league
+type ConstraintsRequest struct {
+	Constraints []FixtureConstraintRequest `json:"constraints" binding:"max=500,dive"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "LeagueManager/internal/domain/models"
+
+// StandingsReport compares the stored standings of a league with the standings rebuilt from its matches
+type StandingsReport struct {
+	LeagueID      uint                  `json:"league_id"`
+	Consistent    bool                  `json:"consistent"`
+	Repaired      bool                  `json:"repaired"`
+	Discrepancies []StandingDiscrepancy `json:"discrepancies"`
+}
+
+// StandingDiscrepancy describes a single team whose stored standing differs from the rebuilt one.
+// Stored is nil when the row is missing, Expected is nil when the team has no matches but a row exists.
+type StandingDiscrepancy struct {
+	TeamID   uint             `json:"team_id"`
+	Fields   []string         `json:"fields"`
+	Stored   *models.Standing `json:"stored"`
+	Expected *models.Standing `json:"expected"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "LeagueManager/internal/domain/models"
+
+// CreatedWebhook is a new webhook subscription along with its secret, which is only ever returned on creation
+type CreatedWebhook struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
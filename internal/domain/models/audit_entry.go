@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entity types recorded in the audit log
+const (
+	AuditEntityTeam   = "team"
+	AuditEntityLeague = "league"
+	AuditEntityMatch  = "match"
+	AuditEntityVenue  = "venue"
+)
+
+// AuditEntry records one state change: who made it, when, to which entity and why, with the entity's state
+// before and after the change. Entries are only ever appended, they have no update or delete timestamps.
+type AuditEntry struct {
+	ID             uint      `json:"id" gorm:"primarykey"`
+	CreatedAt      time.Time `json:"created_at"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	Actor          string    `json:"actor"`
+	Action         string    `json:"action"` // What happened, e.g. "match.result_edited"
+	EntityType     string    `json:"entity_type" gorm:"index:idx_audit_entity"`
+	EntityID       uint      `json:"entity_id" gorm:"index:idx_audit_entity"`
+	LeagueID       uint      `json:"league_id" gorm:"index"` // The league the entity belongs to, 0 for teams
+
+	// Before and After are JSON snapshots of the entity, Before is null for created entities and After for deleted ones
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+	Reason string          `json:"reason"`
+}
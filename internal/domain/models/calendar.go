@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMatchdayIntervalDays is the number of days between two matchdays when a calendar does not say
+const DefaultMatchdayIntervalDays = 7
+
+// LeagueCalendar dates the weeks of a league: week 1 kicks off on StartDate at KickoffTime in Timezone and every
+// further week MatchdayIntervalDays later. A league without a StartDate has no kickoff times.
+type LeagueCalendar struct {
+	StartDate            string `json:"start_date,omitempty"`   // YYYY-MM-DD
+	KickoffTime          string `json:"kickoff_time,omitempty"` // HH:MM
+	Timezone             string `json:"timezone,omitempty"`     // IANA name
+	MatchdayIntervalDays int    `json:"matchday_interval_days,omitempty"`
+}
+
+// IsSet reports whether the calendar dates the weeks of its league
+func (c LeagueCalendar) IsSet() bool {
+	return c.StartDate != ""
+}
+
+// KickoffOf returns the kickoff time of the matches of the week, nil if the calendar is not set
+func (c LeagueCalendar) KickoffOf(week int) (*time.Time, error) {
+	if !c.IsSet() {
+		return nil, nil
+	}
+
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation("2006-01-02", c.StartDate, location)
+	if err != nil {
+		return nil, fmt.Errorf("start date %q is not YYYY-MM-DD", c.StartDate)
+	}
+	hour, minute, err := ParseTimeOfDay(c.KickoffTime)
+	if err != nil {
+		return nil, err
+	}
+	interval := c.MatchdayIntervalDays
+	if interval == 0 {
+		interval = DefaultMatchdayIntervalDays
+	}
+
+	// Counted in calendar days, so the kickoff keeps its local time across daylight saving changes
+	kickoff := time.Date(start.Year(), start.Month(), start.Day()+(week-1)*interval, hour, minute, 0, 0, location).UTC()
+	return &kickoff, nil
+}
+
+// WeekOf returns the first week whose matchday is on or after the day of at in the calendar's timezone, 0 if the
+// calendar is not set. Days before the start of the calendar belong to week 1.
+func (c LeagueCalendar) WeekOf(at time.Time) (int, error) {
+	first, err := c.KickoffOf(1)
+	if err != nil || first == nil {
+		return 0, err
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return 0, err
+	}
+	interval := c.MatchdayIntervalDays
+	if interval == 0 {
+		interval = DefaultMatchdayIntervalDays
+	}
+
+	start, day := first.In(location), at.In(location)
+	// Counted between local midnights in UTC, so daylight saving changes do not shorten a day
+	days := int(time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).
+		Sub(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	if days <= 0 {
+		return 1, nil
+	}
+	return (days+interval-1)/interval + 1, nil
+}
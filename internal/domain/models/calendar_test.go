@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeagueCalendarKickoffOf(t *testing.T) {
+	calendar := LeagueCalendar{}
+	kickoff, err := calendar.KickoffOf(1)
+	assert.NoError(t, err)
+	assert.Nil(t, kickoff, "an unset calendar has no kickoffs")
+
+	calendar = LeagueCalendar{StartDate: "2024-03-30", KickoffTime: "15:00", Timezone: "Europe/London"}
+	kickoff, err = calendar.KickoffOf(1)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 30, 15, 0, 0, 0, time.UTC), *kickoff)
+
+	// Week 2 falls in British Summer Time and keeps its local kickoff time
+	kickoff, err = calendar.KickoffOf(2)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 6, 14, 0, 0, 0, time.UTC), *kickoff)
+
+	calendar.MatchdayIntervalDays = 3
+	kickoff, err = calendar.KickoffOf(3)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 5, 14, 0, 0, 0, time.UTC), *kickoff)
+}
+
+func TestLeagueCalendarWeekOf(t *testing.T) {
+	week, err := LeagueCalendar{}.WeekOf(time.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, week, "an unset calendar has no weeks")
+
+	calendar := LeagueCalendar{StartDate: "2024-03-30", KickoffTime: "15:00", Timezone: "Europe/London"}
+	for at, expected := range map[time.Time]int{
+		time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC):  1, // Before the start
+		time.Date(2024, 4, 6, 23, 30, 0, 0, time.UTC): 3, // Already Sunday in London
+		time.Date(2024, 4, 3, 19, 45, 0, 0, time.UTC): 2, // Midweek, played with the next matchday
+		time.Date(2024, 4, 6, 14, 0, 0, 0, time.UTC):  2,
+		time.Date(2024, 4, 27, 10, 0, 0, 0, time.UTC): 5,
+		time.Date(2024, 4, 28, 10, 0, 0, 0, time.UTC): 6,
+		time.Date(2024, 11, 2, 10, 0, 0, 0, time.UTC): 32, // After the end of British Summer Time
+	} {
+		week, err := calendar.WeekOf(at)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, week, "week of %s", at)
+	}
+}
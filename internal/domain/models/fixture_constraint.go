@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// Kinds of fixture constraints
+const (
+	ConstraintSharedVenue = "shared_venue" // TeamID and OtherTeamID are never both at home in the same week
+	ConstraintBlackout    = "blackout"     // TeamID's ground is unavailable in Week, it does not play at home
+	ConstraintDerby       = "derby"        // TeamID and OtherTeamID meet in Week
+)
+
+// FixtureConstraint is a wish the fixtures of a league should honour when they are drawn. Teams sharing a ground
+// have a shared venue constraint without storing one.
+type FixtureConstraint struct {
+	gorm.Model
+	OrganizationID uint   `json:"organization_id" gorm:"index"`
+	LeagueID       uint   `json:"league_id" gorm:"index"`
+	Kind           string `json:"kind"`
+	TeamID         uint   `json:"team_id"`
+	OtherTeamID    uint   `json:"other_team_id,omitempty"` // For shared venue and derby constraints
+	Week           int    `json:"week,omitempty"`          // For blackout and derby constraints
+}
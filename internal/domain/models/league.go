@@ -2,13 +2,23 @@ package models
 
 import "gorm.io/gorm"
 
+// How the results of the matches of a league come about
+const (
+	ResultModeSimulated = "simulated" // Decided from the strengths of the teams when a week is played
+	ResultModeManual    = "manual"    // Entered match by match, e.g. from the referees of a real league
+)
+
 type League struct {
 	gorm.Model
-	Name        string     `json:"name"`
-	CurrentWeek int        `json:"current_week"`
-	Teams       []Team     `json:"teams" gorm:"many2many:league_teams;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Matches     []Match    `json:"matches" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Standings   []Standing `json:"standings" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OrganizationID uint           `json:"organization_id" gorm:"index"`
+	Name           string         `json:"name"`
+	CurrentWeek    int            `json:"current_week"`
+	ResultMode     string         `json:"result_mode" gorm:"default:simulated"`
+	Version        uint           `json:"version"` // Incremented on every update, used for optimistic locking
+	Calendar       LeagueCalendar `json:"calendar" gorm:"embedded;embeddedPrefix:calendar_"`
+	Teams          []Team         `json:"teams" gorm:"many2many:league_teams;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Matches        []Match        `json:"matches" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Standings      []Standing     `json:"standings" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 const TotalWeeks = 38 // TODO refactor into a constants file
@@ -16,3 +26,8 @@ const TotalWeeks = 38 // TODO refactor into a constants file
 func (l *League) IsActive() bool {
 	return l.CurrentWeek > 0 && l.CurrentWeek <= TotalWeeks
 }
+
+// HasManualResults reports whether the results of the league are entered rather than simulated
+func (l *League) HasManualResults() bool {
+	return l.ResultMode == ResultModeManual
+}
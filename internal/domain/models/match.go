@@ -1,14 +1,35 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
-// Match represents a match between two teams in a specific league
+	"gorm.io/gorm"
+)
+
+// Statuses of a match
+const (
+	MatchStatusScheduled = "scheduled" // A fixture that has not been played yet
+	MatchStatusPostponed = "postponed" // Called off, left unplayed until it is rescheduled
+	MatchStatusPlayed    = "played"
+)
+
+// Match represents a match between two teams in a specific league. The fixtures of a season are stored as
+// scheduled matches when the league starts and are played week by week. A postponed match is skipped when its week is
+// played, once rescheduled it is played with the matches of its new week.
 type Match struct {
 	gorm.Model
-	LeagueID      uint `json:"league_id"`
-	HomeTeamID    uint `json:"home_team_id"`
-	AwayTeamID    uint `json:"away_team_id"`
-	HomeTeamScore int  `json:"home_team_score"`
-	AwayTeamScore int  `json:"away_team_score"`
-	Week          int  `json:"week"`
+	OrganizationID uint       `json:"organization_id" gorm:"index"`
+	LeagueID       uint       `json:"league_id"`
+	HomeTeamID     uint       `json:"home_team_id"`
+	AwayTeamID     uint       `json:"away_team_id"`
+	HomeTeamScore  int        `json:"home_team_score"`
+	AwayTeamScore  int        `json:"away_team_score"`
+	Week           int        `json:"week"`
+	OriginalWeek   int        `json:"original_week,omitempty"` // The week the fixture was drawn for, set once it is postponed or moved
+	Status         string     `json:"status" gorm:"default:played"`
+	KickoffAt      *time.Time `json:"kickoff_at"`    // Taken from the league calendar, nil without one
+	KickoffFixed   bool       `json:"kickoff_fixed"` // Set for this match alone, the calendar no longer moves it
+	VenueID        *uint      `json:"venue_id"`      // The home team's ground unless VenueFixed, nil if it has none
+	VenueFixed     bool       `json:"venue_fixed"`   // Set for this match alone, e.g. a neutral venue
+	Attendance     int        `json:"attendance"`    // Simulated when the match is played at a venue
 }
@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// DefaultOrganizationName is the organization that owns the data created before organizations existed
+const DefaultOrganizationName = "default"
+
+// Organization is a tenant of the deployment. Every team, league, match and standing belongs to exactly one
+// organization and is only visible to its callers.
+type Organization struct {
+	gorm.Model
+	Name string `json:"name" gorm:"uniqueIndex"`
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of advancement schedules
+const (
+	ScheduleWeekly   = "weekly"   // On one day of the week at a time of day, e.g. every Saturday at 15:00
+	ScheduleInterval = "interval" // Every IntervalMinutes minutes, meant for demos
+)
+
+// Policies for the runs of a schedule that were missed, typically while the server was down
+const (
+	MissedRunsSkip    = "skip"     // Missed runs are dropped, only a run that is barely late still advances the league
+	MissedRunsOnce    = "once"     // The league advances once, however many runs were missed
+	MissedRunsCatchUp = "catch_up" // The league advances once per missed run
+)
+
+// AdvancementSchedule advances a league to its next week at regular times. NextRunAt is the time of the next run;
+// Revision changes with every write, so a run is only made by whoever claims it first.
+type AdvancementSchedule struct {
+	gorm.Model
+	OrganizationID  uint       `json:"organization_id" gorm:"index"`
+	LeagueID        uint       `json:"league_id" gorm:"uniqueIndex"`
+	Kind            string     `json:"kind"`
+	Weekday         string     `json:"weekday,omitempty"`  // Lower-case English day name, for weekly schedules
+	TimeOfDay       string     `json:"time,omitempty"`     // HH:MM, for weekly schedules
+	Timezone        string     `json:"timezone,omitempty"` // IANA name the time of day is read in, for weekly schedules
+	IntervalMinutes int        `json:"interval_minutes,omitempty"`
+	MissedRunPolicy string     `json:"missed_run_policy"`
+	Paused          bool       `json:"paused"`
+	NextRunAt       time.Time  `json:"next_run_at" gorm:"index"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	LastError       string     `json:"last_error"`
+	Revision        uint       `json:"-"`
+}
+
+// NextAfter returns the first time the schedule runs strictly after t
+func (s *AdvancementSchedule) NextAfter(t time.Time) (time.Time, error) {
+	switch s.Kind {
+	case ScheduleInterval:
+		if s.IntervalMinutes < 1 {
+			return time.Time{}, fmt.Errorf("interval of %d minutes", s.IntervalMinutes)
+		}
+		interval := time.Duration(s.IntervalMinutes) * time.Minute
+		if s.NextRunAt.IsZero() {
+			return t.Add(interval), nil
+		}
+		// Runs are aligned on NextRunAt
+		if s.NextRunAt.After(t) {
+			return s.NextRunAt, nil
+		}
+		periods := t.Sub(s.NextRunAt)/interval + 1
+		return s.NextRunAt.Add(periods * interval), nil
+	case ScheduleWeekly:
+		weekday, err := ParseWeekday(s.Weekday)
+		if err != nil {
+			return time.Time{}, err
+		}
+		hour, minute, err := ParseTimeOfDay(s.TimeOfDay)
+		if err != nil {
+			return time.Time{}, err
+		}
+		location, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		local := t.In(location)
+		for day := 0; day <= 7; day++ {
+			candidate := time.Date(local.Year(), local.Month(), local.Day()+day, hour, minute, 0, 0, location)
+			if candidate.Weekday() == weekday && candidate.After(t) {
+				return candidate.UTC(), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("no run found after %s", t)
+	default:
+		return time.Time{}, fmt.Errorf("unknown schedule kind %q", s.Kind)
+	}
+}
+
+// RunBefore returns the run that precedes next, which must itself be a run of the schedule
+func (s *AdvancementSchedule) RunBefore(next time.Time) (time.Time, error) {
+	switch s.Kind {
+	case ScheduleInterval:
+		if s.IntervalMinutes < 1 {
+			return time.Time{}, fmt.Errorf("interval of %d minutes", s.IntervalMinutes)
+		}
+		return next.Add(-time.Duration(s.IntervalMinutes) * time.Minute), nil
+	case ScheduleWeekly:
+		location, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		// A week earlier on the calendar, so the time of day survives daylight saving changes
+		return next.In(location).AddDate(0, 0, -7).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown schedule kind %q", s.Kind)
+	}
+}
+
+// ParseWeekday reads an English day name, ignoring case
+func ParseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", name)
+}
+
+// ParseTimeOfDay reads a 24-hour HH:MM time
+func ParseTimeOfDay(value string) (int, int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("time of day %q is not HH:MM", value)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeeklyScheduleNextAfter(t *testing.T) {
+	schedule := &AdvancementSchedule{Kind: ScheduleWeekly, Weekday: "Saturday", TimeOfDay: "15:00", Timezone: "Europe/London"}
+
+	// Friday 2024-03-29, London is on GMT
+	next, err := schedule.NextAfter(time.Date(2024, 3, 29, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 30, 15, 0, 0, 0, time.UTC), next)
+
+	// A run is strictly after t, and the following Saturday falls in British Summer Time
+	next, err = schedule.NextAfter(next)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 6, 14, 0, 0, 0, time.UTC), next)
+
+	previous, err := schedule.RunBefore(next)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 30, 15, 0, 0, 0, time.UTC), previous)
+
+	schedule.Timezone = "Mars/Olympus_Mons"
+	_, err = schedule.NextAfter(next)
+	assert.Error(t, err)
+}
+
+func TestIntervalScheduleNextAfter(t *testing.T) {
+	start := time.Date(2024, 3, 29, 12, 0, 0, 0, time.UTC)
+	schedule := &AdvancementSchedule{Kind: ScheduleInterval, IntervalMinutes: 10}
+
+	next, err := schedule.NextAfter(start)
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(10*time.Minute), next)
+
+	// Runs stay aligned on the next run
+	schedule.NextRunAt = next
+	next, err = schedule.NextAfter(start.Add(35 * time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(40*time.Minute), next)
+
+	next, err = schedule.NextAfter(start.Add(40 * time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(50*time.Minute), next)
+}
@@ -5,6 +5,7 @@ import "gorm.io/gorm"
 // Standing represents the standings of a team in a specific league
 type Standing struct {
 	gorm.Model
+	OrganizationID uint `json:"organization_id" gorm:"index"`
 	LeagueID       uint `json:"league_id"`
 	TeamID         uint `json:"team_id"`
 	Points         int  `json:"points"`
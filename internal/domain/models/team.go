@@ -2,10 +2,25 @@ package models
 
 import "gorm.io/gorm"
 
+// Bounds of a team's attack and defense strength
+const (
+	MinStrength = 0
+	MaxStrength = 100
+)
+
+// TeamNameIndex is the index that keeps the names of an organization's teams unique, ignoring case. It is created once
+// the names stored before it existed are made unique rather than by AutoMigrate, which would fail on them.
+const (
+	TeamNameIndex       = "idx_teams_organization_name"
+	CreateTeamNameIndex = "CREATE UNIQUE INDEX IF NOT EXISTS " + TeamNameIndex + " ON teams (organization_id, LOWER(name)) WHERE deleted_at IS NULL"
+)
+
 // Team represents a Football team, may be affiliated with multiple leagues.
 type Team struct {
 	gorm.Model
-	Name            string `json:"name"`
+	OrganizationID  uint   `json:"organization_id" gorm:"index"`
+	Name            string `json:"name"` // Unique in the organization, ignoring case
 	AttackStrength  int    `json:"attack_strength"`
 	DefenseStrength int    `json:"defense_strength"`
+	HomeVenueID     *uint  `json:"home_venue_id"` // The team's own ground, home advantage only applies there
 }
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// Bounds of a venue's capacity
+const (
+	MinCapacity = 1
+	MaxCapacity = 200000
+)
+
+// Venue is a ground matches are played at, the home ground of any number of teams
+type Venue struct {
+	gorm.Model
+	OrganizationID uint    `json:"organization_id" gorm:"index"`
+	Name           string  `json:"name"`
+	City           string  `json:"city"`
+	Capacity       int     `json:"capacity"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+}
+
+// Attendance returns the crowd of a match at the venue for a demand between 0 and 1, the share of seats sold.
+// Demand outside these bounds is clamped, a sold-out venue never holds more than its capacity.
+func (v *Venue) Attendance(demand float64) int {
+	demand = math.Max(0, math.Min(1, demand))
+	return int(math.Round(demand * float64(v.Capacity)))
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVenueAttendance(t *testing.T) {
+	venue := &Venue{Name: "Old Ground", Capacity: 30000}
+
+	assert.Equal(t, 22500, venue.Attendance(0.75))
+	assert.Equal(t, 30000, venue.Attendance(1.4), "a venue holds no more than its capacity")
+	assert.Equal(t, 0, venue.Attendance(-0.2))
+}
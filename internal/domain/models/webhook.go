@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookSubscription asks for the events of a league to be POSTed to URL. The payloads are signed with Secret,
+// which is never serialized. Events lists the event types to send, every event if empty.
+type WebhookSubscription struct {
+	gorm.Model
+	OrganizationID uint     `json:"organization_id" gorm:"index"`
+	LeagueID       uint     `json:"league_id" gorm:"index"`
+	URL            string   `json:"url"`
+	Secret         string   `json:"-"`
+	Events         []string `json:"events" gorm:"serializer:json"`
+	Active         bool     `json:"active"`
+}
+
+// Wants reports whether the subscription receives events of the given type
+func (w *WebhookSubscription) Wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, wanted := range w.Events {
+		if wanted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses of a webhook delivery
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event to be sent to one subscription, along with the outcome of its latest attempt.
+// Pending deliveries are attempted again at NextAttemptAt until they succeed or run out of attempts.
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primarykey"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	OrganizationID uint            `json:"organization_id" gorm:"index"`
+	SubscriptionID uint            `json:"subscription_id" gorm:"index"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status" gorm:"index"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
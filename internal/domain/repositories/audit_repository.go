@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// SystemActor is recorded as the actor of changes made without a caller
+const SystemActor = "system"
+
+// AuditFilter narrows down an audit log query, zero values are ignored
+type AuditFilter struct {
+	LeagueID   uint
+	EntityType string
+	EntityID   uint
+	Action     string
+}
+
+// AuditRepository appends to the audit log and reads it back, entries can never be changed or removed
+type AuditRepository interface {
+	RecordEntry(entry *models.AuditEntry) error
+	FindEntries(filter AuditFilter, page Page) ([]*models.AuditEntry, int64, error)
+	WithScope(scope Scope) AuditRepository
+}
+
+type AuditRepositoryImpl struct {
+	db    *gorm.DB
+	scope Scope
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+// WithScope returns a repository restricted to the audit log of the scope's organization, recording its actor and reason
+func (r *AuditRepositoryImpl) WithScope(scope Scope) AuditRepository {
+	return &AuditRepositoryImpl{db: r.db, scope: scope}
+}
+
+// RecordEntry appends the entry, stamped with the organization, actor and reason of the scope
+func (r *AuditRepositoryImpl) RecordEntry(entry *models.AuditEntry) error {
+	entry.ID = 0
+	entry.OrganizationID = r.scope.OrganizationID
+	entry.Actor = r.scope.Actor
+	if entry.Actor == "" {
+		entry.Actor = SystemActor
+	}
+	entry.Reason = r.scope.Reason
+	return r.db.Create(entry).Error
+}
+
+// FindEntries returns one page of the entries matching the filter, oldest first by default, and the total number of matching entries
+func (r *AuditRepositoryImpl) FindEntries(filter AuditFilter, page Page) ([]*models.AuditEntry, int64, error) {
+	query := r.scope.where(r.db.Model(&models.AuditEntry{}), "audit_entries")
+	if filter.LeagueID != 0 {
+		query = query.Where("league_id = ?", filter.LeagueID)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	// Allow the filtered query to be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	paged, err := paginate(query, page, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*models.AuditEntry
+	err = paged.Find(&entries).Error
+	return entries, total, err
+}
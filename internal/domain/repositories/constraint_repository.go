@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// ConstraintRepository stores the fixture constraints of leagues, which are always replaced as a whole
+type ConstraintRepository interface {
+	GetConstraintsByLeague(leagueID uint) ([]*models.FixtureConstraint, error)
+	// ReplaceConstraints removes the constraints of the league and stores the given ones instead
+	ReplaceConstraints(leagueID uint, constraints []*models.FixtureConstraint) error
+	WithScope(scope Scope) ConstraintRepository
+}
+
+type ConstraintRepositoryImpl struct {
+	db    *gorm.DB
+	scope Scope
+}
+
+func NewConstraintRepository(db *gorm.DB) ConstraintRepository {
+	return &ConstraintRepositoryImpl{db: db}
+}
+
+// WithScope returns a repository restricted to the constraints of the scope's organization
+func (r *ConstraintRepositoryImpl) WithScope(scope Scope) ConstraintRepository {
+	return &ConstraintRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *ConstraintRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "fixture_constraints")
+}
+
+func (r *ConstraintRepositoryImpl) GetConstraintsByLeague(leagueID uint) ([]*models.FixtureConstraint, error) {
+	var constraints []*models.FixtureConstraint
+	err := r.scoped().Where("league_id = ?", leagueID).Order("id").Find(&constraints).Error
+	return constraints, err
+}
+
+func (r *ConstraintRepositoryImpl) ReplaceConstraints(leagueID uint, constraints []*models.FixtureConstraint) error {
+	err := r.scoped().Unscoped().Where("league_id = ?", leagueID).Delete(&models.FixtureConstraint{}).Error
+	if err != nil {
+		return err
+	}
+	for _, constraint := range constraints {
+		constraint.ID = 0
+		constraint.OrganizationID = r.scope.OrganizationID
+		constraint.LeagueID = leagueID
+		if err := r.db.Create(constraint).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a league was updated by someone else since it was read
+var ErrVersionConflict = apperrors.Conflict("league_version_conflict", "league was modified concurrently")
+
+// translateError turns gorm's record-not-found error into a typed not-found error for the given entity,
+// any other error is returned unchanged
+func translateError(err error, entity string, id interface{}) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NotFound(entity+"_not_found", "%s %v not found", entity, id).Wrap(err)
+	}
+	return err
+}
+
+// isDuplicate reports whether err is the violation of a unique index, whichever driver reported it
+func isDuplicate(db *gorm.DB, err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		return errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"gorm.io/gorm"
 )
@@ -13,49 +14,100 @@ type LeagueRepository interface {
 	GetAllLeagues() ([]*models.League, error)
 	GetLeaguesByTeamID(teamID uint) ([]*models.League, error)
 	RemoveTeamFromLeague(leagueID, teamID uint) error
+	FindLeagues(filter LeagueFilter, page Page) ([]*models.League, int64, error)
+	WithScope(scope Scope) LeagueRepository
 }
 
 type LeagueRepositoryImpl struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope Scope
 }
 
 func NewLeagueRepository(db *gorm.DB) LeagueRepository {
 	return &LeagueRepositoryImpl{db: db}
 }
 
+// WithScope returns a repository restricted to the leagues of the scope's organization
+func (r *LeagueRepositoryImpl) WithScope(scope Scope) LeagueRepository {
+	return &LeagueRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *LeagueRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "leagues")
+}
+
+// CreateLeague stores the league in the scope's organization, together with its teams which must belong to the same organization
 func (r *LeagueRepositoryImpl) CreateLeague(league *models.League) error {
+	league.OrganizationID = r.scope.OrganizationID
+	if err := r.checkTeams(league); err != nil {
+		return err
+	}
 	return r.db.Create(&league).Error
 }
 
+// checkTeams rejects a league with a team of another organization, the association is saved along with the league
+func (r *LeagueRepositoryImpl) checkTeams(league *models.League) error {
+	for _, team := range league.Teams {
+		if !r.scope.owns(team.OrganizationID) {
+			return translateError(gorm.ErrRecordNotFound, "team", team.ID)
+		}
+	}
+	return nil
+}
+
 func (r *LeagueRepositoryImpl) GetLeagueByID(id uint) (*models.League, error) {
 	var league *models.League
 
 	// Include all related entities when a single league is retrieved by ID
-	err := r.db.Preload("Teams").Preload("Matches").Preload("Standings").First(&league, id).Error
-	return league, err
+	err := r.scoped().Preload("Teams").Preload("Matches").Preload("Standings").First(&league, id).Error
+	return league, translateError(err, "league", id)
 }
 
+// UpdateLeague saves the league only if its version still matches the stored one and increments the version.
+// ErrVersionConflict is returned if the league was updated since it was read.
 func (r *LeagueRepositoryImpl) UpdateLeague(league *models.League) error {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
+	if !r.scope.owns(league.OrganizationID) {
+		return translateError(gorm.ErrRecordNotFound, "league", league.ID)
 	}
-
-	if err := tx.Save(league).Error; err != nil {
-		tx.Rollback()
+	if err := r.checkTeams(league); err != nil {
 		return err
 	}
 
-	return tx.Commit().Error
+	// Transaction falls back to a savepoint when the repository is already bound to a transaction
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := r.scope.where(tx.Model(&models.League{}), "leagues").
+			Where("id = ? AND version = ?", league.ID, league.Version).
+			Update("version", league.Version+1)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			var count int64
+			if err := r.scope.where(tx.Model(&models.League{}), "leagues").Where("id = ?", league.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return translateError(gorm.ErrRecordNotFound, "league", league.ID)
+			}
+			return ErrVersionConflict
+		}
+
+		league.Version++
+		if err := tx.Save(league).Error; err != nil {
+			league.Version--
+			return err
+		}
+		return nil
+	})
 }
 
 func (r *LeagueRepositoryImpl) DeleteLeague(id uint) error {
-	return r.db.Delete(&models.League{}, id).Error
+	return r.scoped().Delete(&models.League{}, id).Error
 }
 
 func (r *LeagueRepositoryImpl) GetAllLeagues() ([]*models.League, error) {
 	var leagues []*models.League
-	err := r.db.Preload("Teams").Find(&leagues).Error
+	err := r.scoped().Preload("Teams").Find(&leagues).Error
 	return leagues, err
 }
 
@@ -63,14 +115,67 @@ func (r *LeagueRepositoryImpl) GetLeaguesByTeamID(teamID uint) ([]*models.League
 	var leagues []*models.League
 
 	// Joins the league_teams table to the leagues table and filters by the team ID
-	err := r.db.Joins("JOIN league_teams ON league_teams.league_id = leagues.id").
+	err := r.scoped().Joins("JOIN league_teams ON league_teams.league_id = leagues.id").
 		Where("league_teams.team_id = ?", teamID).
 		Find(&leagues).Error
 	return leagues, err
 }
 
 func (r *LeagueRepositoryImpl) RemoveTeamFromLeague(leagueID, teamID uint) error {
+	// The association is deleted by key, so check that the league is in scope first
+	var count int64
+	if err := r.scoped().Model(&models.League{}).Where("id = ?", leagueID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return translateError(gorm.ErrRecordNotFound, "league", leagueID)
+	}
+
 	league := models.League{Model: gorm.Model{ID: leagueID}}
 	team := models.Team{Model: gorm.Model{ID: teamID}}
 	return r.db.Model(&league).Association("Teams").Delete(&team)
 }
+
+// FindLeagues returns one page of the leagues matching the filter, with their teams, and the total number of matching leagues
+func (r *LeagueRepositoryImpl) FindLeagues(filter LeagueFilter, page Page) ([]*models.League, int64, error) {
+	query := r.scoped().Model(&models.League{})
+	if filter.NameContains != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, containsPattern(filter.NameContains))
+	}
+	switch filter.Status {
+	case "":
+	case LeagueStatusPending:
+		query = query.Where("current_week = 0")
+	case LeagueStatusActive:
+		query = query.Where("current_week BETWEEN 1 AND ?", models.TotalWeeks)
+	case LeagueStatusFinished:
+		query = query.Where("current_week > ?", models.TotalWeeks)
+	default:
+		return nil, 0, apperrors.Validation("validation_failed", "unknown league status %q", filter.Status).
+			WithFields(apperrors.FieldError{Field: "status", Message: "must be one of pending, active, finished"})
+	}
+	if filter.TeamID != 0 {
+		query = query.Where("id IN (SELECT league_id FROM league_teams WHERE team_id = ?)", filter.TeamID)
+	}
+	// Allow the filtered query to be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	paged, err := paginate(query, page, map[string]string{
+		"id":           "id",
+		"name":         "name",
+		"current_week": "current_week",
+		"created_at":   "created_at",
+	}, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var leagues []*models.League
+	err = paged.Preload("Teams").Find(&leagues).Error
+	return leagues, total, err
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -51,7 +52,73 @@ func TestLeagueRepository(t *testing.T) {
 	err = repo.UpdateLeague(readLeague)
 	assert.NoError(t, err)
 
+	// Stale copies are rejected
+	staleLeague, err := repo.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+	freshLeague, err := repo.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+
+	freshLeague.CurrentWeek = 2
+	err = repo.UpdateLeague(freshLeague)
+	assert.NoError(t, err)
+	assert.Equal(t, staleLeague.Version+1, freshLeague.Version)
+
+	staleLeague.CurrentWeek = 5
+	err = repo.UpdateLeague(staleLeague)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	storedLeague, err := repo.GetLeagueByID(league.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, storedLeague.CurrentWeek)
+
 	// Delete league
 	err = repo.DeleteLeague(league.ID)
 	assert.NoError(t, err)
 }
+
+func TestFindLeagues(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.League{}, &models.Team{})
+	assert.NoError(t, err)
+
+	repo := NewLeagueRepository(db)
+
+	team := &models.Team{Name: "Team A", AttackStrength: 80, DefenseStrength: 70}
+	assert.NoError(t, db.Create(team).Error)
+
+	pending := &models.League{Name: "Spring Cup", Teams: []models.Team{*team}}
+	active := &models.League{Name: "Premier League", CurrentWeek: 2}
+	finished := &models.League{Name: "Winter League", CurrentWeek: models.TotalWeeks + 1}
+	for _, league := range []*models.League{pending, active, finished} {
+		assert.NoError(t, repo.CreateLeague(league))
+	}
+
+	for status, expected := range map[string]*models.League{
+		LeagueStatusPending:  pending,
+		LeagueStatusActive:   active,
+		LeagueStatusFinished: finished,
+	} {
+		leagues, total, err := repo.FindLeagues(LeagueFilter{Status: status}, Page{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total, status)
+		assert.Equal(t, expected.ID, leagues[0].ID, status)
+	}
+
+	// Leagues of a team come with their teams
+	leagues, total, err := repo.FindLeagues(LeagueFilter{TeamID: team.ID}, Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, pending.ID, leagues[0].ID)
+	assert.Len(t, leagues[0].Teams, 1)
+
+	leagues, total, err = repo.FindLeagues(LeagueFilter{NameContains: "LEAGUE"}, Page{Sort: "-name"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.Equal(t, "Winter League", leagues[0].Name)
+	assert.Equal(t, "Premier League", leagues[1].Name)
+
+	_, _, err = repo.FindLeagues(LeagueFilter{Status: "cancelled"}, Page{})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
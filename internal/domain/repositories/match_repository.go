@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"gorm.io/gorm"
 )
@@ -12,42 +13,146 @@ type MatchRepository interface {
 	DeleteMatch(id uint) error
 	GetAllMatches() ([]*models.Match, error)
 	GetMatchesByWeek(leagueID uint, week int) ([]*models.Match, error)
+	GetMatchesByLeague(leagueID uint) ([]*models.Match, error)
+	GetMatchesByTeam(teamID uint) ([]*models.Match, error)
+	// DeleteUnplayedMatches removes the matches of the league that have not been played, postponed ones included,
+	// except those listed
+	DeleteUnplayedMatches(leagueID uint, except ...uint) error
+	// MoveHomeMatches moves the unplayed home matches of a team to venueID, except those whose venue was set for the
+	// match alone
+	MoveHomeMatches(teamID uint, venueID *uint) error
+	FindMatches(filter MatchFilter, page Page) ([]*models.Match, int64, error)
+	WithScope(scope Scope) MatchRepository
 }
 
 type MatchRepositoryImpl struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope Scope
 }
 
 func NewMatchRepository(db *gorm.DB) MatchRepository {
 	return &MatchRepositoryImpl{db: db}
 }
 
+// WithScope returns a repository restricted to the matches of the scope's organization
+func (r *MatchRepositoryImpl) WithScope(scope Scope) MatchRepository {
+	return &MatchRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *MatchRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "matches")
+}
+
 func (r *MatchRepositoryImpl) CreateMatch(match *models.Match) error {
+	match.OrganizationID = r.scope.OrganizationID
 	return r.db.Create(&match).Error
 }
 
 func (r *MatchRepositoryImpl) GetMatchByID(id uint) (*models.Match, error) {
 	var match *models.Match
-	err := r.db.First(&match, id).Error
-	return match, err
+	err := r.scoped().First(&match, id).Error
+	return match, translateError(err, "match", id)
 }
 
 func (r *MatchRepositoryImpl) UpdateMatch(match *models.Match) error {
+	if !r.scope.owns(match.OrganizationID) {
+		return translateError(gorm.ErrRecordNotFound, "match", match.ID)
+	}
 	return r.db.Save(&match).Error
 }
 
 func (r *MatchRepositoryImpl) DeleteMatch(id uint) error {
-	return r.db.Delete(&models.Match{}, id).Error
+	return r.scoped().Delete(&models.Match{}, id).Error
 }
 
 func (r *MatchRepositoryImpl) GetAllMatches() ([]*models.Match, error) {
 	var matches []*models.Match
-	err := r.db.Find(&matches).Error
+	err := r.scoped().Find(&matches).Error
 	return matches, err
 }
 
 func (r *MatchRepositoryImpl) GetMatchesByWeek(leagueID uint, week int) ([]*models.Match, error) {
 	var matches []*models.Match
-	err := r.db.Where("league_id = ? AND week = ?", leagueID, week).Find(&matches).Error
+	err := r.scoped().Where("league_id = ? AND week = ?", leagueID, week).Find(&matches).Error
+	return matches, err
+}
+
+func (r *MatchRepositoryImpl) GetMatchesByLeague(leagueID uint) ([]*models.Match, error) {
+	var matches []*models.Match
+	err := r.scoped().Where("league_id = ?", leagueID).Order("week, id").Find(&matches).Error
+	return matches, err
+}
+
+// GetMatchesByTeam returns the matches of a team in every league, in kickoff order with the undated ones first
+func (r *MatchRepositoryImpl) GetMatchesByTeam(teamID uint) ([]*models.Match, error) {
+	var matches []*models.Match
+	// SQLite puts NULLs first and PostgreSQL last, the order is spelt out so that both agree
+	err := r.scoped().Where("home_team_id = ? OR away_team_id = ?", teamID, teamID).
+		Order("kickoff_at IS NULL DESC, kickoff_at, league_id, week, id").
+		Find(&matches).Error
 	return matches, err
 }
+
+func (r *MatchRepositoryImpl) DeleteUnplayedMatches(leagueID uint, except ...uint) error {
+	query := r.scoped().Unscoped().Where("league_id = ? AND status <> ?", leagueID, models.MatchStatusPlayed)
+	if len(except) > 0 {
+		query = query.Where("id NOT IN ?", except)
+	}
+	return query.Delete(&models.Match{}).Error
+}
+
+func (r *MatchRepositoryImpl) MoveHomeMatches(teamID uint, venueID *uint) error {
+	return r.scoped().Model(&models.Match{}).
+		Where("home_team_id = ? AND status <> ? AND venue_fixed = ?", teamID, models.MatchStatusPlayed, false).
+		Update("venue_id", venueID).Error
+}
+
+// FindMatches returns one page of the matches matching the filter and the total number of matching matches
+func (r *MatchRepositoryImpl) FindMatches(filter MatchFilter, page Page) ([]*models.Match, int64, error) {
+	query := r.scoped().Model(&models.Match{})
+	if filter.LeagueID != 0 {
+		query = query.Where("league_id = ?", filter.LeagueID)
+	}
+	if filter.TeamID != 0 {
+		query = query.Where("(home_team_id = ? OR away_team_id = ?)", filter.TeamID, filter.TeamID)
+	}
+	if filter.VenueID != 0 {
+		query = query.Where("venue_id = ?", filter.VenueID)
+	}
+	if filter.WeekFrom != nil {
+		query = query.Where("week >= ?", *filter.WeekFrom)
+	}
+	if filter.WeekTo != nil {
+		query = query.Where("week <= ?", *filter.WeekTo)
+	}
+	switch filter.Status {
+	case "":
+	case models.MatchStatusScheduled, models.MatchStatusPostponed, models.MatchStatusPlayed:
+		query = query.Where("status = ?", filter.Status)
+	default:
+		return nil, 0, apperrors.Validation("validation_failed", "unknown match status %q", filter.Status).
+			WithFields(apperrors.FieldError{Field: "status", Message: "must be one of scheduled, postponed, played"})
+	}
+	// Allow the filtered query to be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	paged, err := paginate(query, page, map[string]string{
+		"id":         "id",
+		"week":       "week",
+		"league_id":  "league_id",
+		"created_at": "created_at",
+		"kickoff_at": "kickoff_at",
+	}, "week, id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matches []*models.Match
+	err = paged.Find(&matches).Error
+	return matches, total, err
+}
@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
 	"testing"
@@ -43,6 +44,16 @@ func TestMatchRepository(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 3, updatedMatch.HomeTeamScore)
 
+	// Get by league
+	otherLeagueMatch := &models.Match{LeagueID: 2, HomeTeamID: 1, AwayTeamID: 2, Week: 1}
+	err = repo.CreateMatch(otherLeagueMatch)
+	assert.NoError(t, err)
+
+	leagueMatches, err := repo.GetMatchesByLeague(match.LeagueID)
+	assert.NoError(t, err)
+	assert.Len(t, leagueMatches, 1)
+	assert.Equal(t, match.ID, leagueMatches[0].ID)
+
 	// Delete
 	err = repo.DeleteMatch(match.ID)
 	assert.NoError(t, err)
@@ -50,3 +61,112 @@ func TestMatchRepository(t *testing.T) {
 	_, err = repo.GetMatchByID(match.ID)
 	assert.Error(t, err)
 }
+
+func TestFindMatches(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.Match{})
+	assert.NoError(t, err)
+
+	repo := repositories.NewMatchRepository(db)
+	for _, match := range []models.Match{
+		{LeagueID: 1, HomeTeamID: 1, AwayTeamID: 2, Week: 1, Status: models.MatchStatusPlayed},
+		{LeagueID: 1, HomeTeamID: 3, AwayTeamID: 4, Week: 1, Status: models.MatchStatusPlayed},
+		{LeagueID: 1, HomeTeamID: 2, AwayTeamID: 3, Week: 2, Status: models.MatchStatusScheduled},
+		{LeagueID: 2, HomeTeamID: 5, AwayTeamID: 2, Week: 3, Status: models.MatchStatusScheduled},
+	} {
+		match := match
+		assert.NoError(t, repo.CreateMatch(&match))
+	}
+
+	// Home and away matches of a team
+	matches, total, err := repo.FindMatches(repositories.MatchFilter{TeamID: 2}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	assert.Len(t, matches, 3)
+
+	// Week range within a league
+	weekFrom, weekTo := 2, 3
+	matches, total, err = repo.FindMatches(repositories.MatchFilter{LeagueID: 1, WeekFrom: &weekFrom, WeekTo: &weekTo}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, 2, matches[0].Week)
+
+	// Status
+	_, total, err = repo.FindMatches(repositories.MatchFilter{Status: models.MatchStatusPlayed}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	// Matches come in week order by default
+	matches, total, err = repo.FindMatches(repositories.MatchFilter{}, repositories.Page{Limit: 1, Offset: 3})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, total)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, 3, matches[0].Week)
+
+	_, _, err = repo.FindMatches(repositories.MatchFilter{Status: "abandoned"}, repositories.Page{})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
+
+func TestMoveHomeMatches(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Match{}))
+	repo := repositories.NewMatchRepository(db)
+
+	oldGround, newGround, neutral := uint(1), uint(2), uint(3)
+	played := &models.Match{HomeTeamID: 1, AwayTeamID: 2, Week: 1, Status: models.MatchStatusPlayed, VenueID: &oldGround}
+	home := &models.Match{HomeTeamID: 1, AwayTeamID: 2, Week: 2, Status: models.MatchStatusScheduled, VenueID: &oldGround}
+	fixed := &models.Match{HomeTeamID: 1, AwayTeamID: 3, Week: 3, Status: models.MatchStatusScheduled, VenueID: &neutral, VenueFixed: true}
+	away := &models.Match{HomeTeamID: 2, AwayTeamID: 1, Week: 4, Status: models.MatchStatusScheduled}
+	for _, match := range []*models.Match{played, home, fixed, away} {
+		assert.NoError(t, repo.CreateMatch(match))
+	}
+
+	assert.NoError(t, repo.MoveHomeMatches(1, &newGround))
+
+	venueOf := func(match *models.Match) *uint {
+		stored, err := repo.GetMatchByID(match.ID)
+		assert.NoError(t, err)
+		return stored.VenueID
+	}
+	assert.Equal(t, &oldGround, venueOf(played), "played matches keep their venue")
+	assert.Equal(t, &newGround, venueOf(home))
+	assert.Equal(t, &neutral, venueOf(fixed), "venues set for a match alone are kept")
+	assert.Nil(t, venueOf(away))
+
+	assert.NoError(t, repo.MoveHomeMatches(1, nil))
+	assert.Nil(t, venueOf(home))
+}
+
+func TestDeleteUnplayedMatches(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Match{}))
+	repo := repositories.NewMatchRepository(db)
+
+	played := &models.Match{LeagueID: 1, HomeTeamID: 1, AwayTeamID: 2, Week: 1, Status: models.MatchStatusPlayed}
+	postponed := &models.Match{LeagueID: 1, HomeTeamID: 3, AwayTeamID: 4, Week: 1, Status: models.MatchStatusPostponed}
+	kept := &models.Match{LeagueID: 1, HomeTeamID: 2, AwayTeamID: 1, Week: 2, Status: models.MatchStatusScheduled}
+	scheduled := &models.Match{LeagueID: 1, HomeTeamID: 4, AwayTeamID: 3, Week: 2, Status: models.MatchStatusScheduled}
+	otherLeague := &models.Match{LeagueID: 2, HomeTeamID: 5, AwayTeamID: 6, Week: 2, Status: models.MatchStatusScheduled}
+	for _, match := range []*models.Match{played, postponed, kept, scheduled, otherLeague} {
+		assert.NoError(t, repo.CreateMatch(match))
+	}
+
+	assert.NoError(t, repo.DeleteUnplayedMatches(1, kept.ID))
+
+	matches, err := repo.GetAllMatches()
+	assert.NoError(t, err)
+	var remaining []uint
+	for _, match := range matches {
+		remaining = append(remaining, match.ID)
+	}
+	assert.ElementsMatch(t, []uint{played.ID, kept.ID, otherLeague.ID}, remaining)
+
+	assert.NoError(t, repo.DeleteUnplayedMatches(1))
+	matches, err = repo.GetAllMatches()
+	assert.NoError(t, err)
+	assert.Len(t, matches, 2)
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository stores the tenants. It is not scoped, organizations are looked up before a scope exists.
+type OrganizationRepository interface {
+	CreateOrganization(organization *models.Organization) error
+	GetOrganizationByID(id uint) (*models.Organization, error)
+	GetOrganizationByName(name string) (*models.Organization, error)
+	GetAllOrganizations() ([]*models.Organization, error)
+}
+
+type OrganizationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &OrganizationRepositoryImpl{db: db}
+}
+
+func (r *OrganizationRepositoryImpl) CreateOrganization(organization *models.Organization) error {
+	return r.db.Create(organization).Error
+}
+
+func (r *OrganizationRepositoryImpl) GetOrganizationByID(id uint) (*models.Organization, error) {
+	var organization *models.Organization
+	err := r.db.First(&organization, id).Error
+	return organization, translateError(err, "organization", id)
+}
+
+func (r *OrganizationRepositoryImpl) GetOrganizationByName(name string) (*models.Organization, error) {
+	var organization *models.Organization
+	err := r.db.Where("name = ?", name).First(&organization).Error
+	return organization, translateError(err, "organization", name)
+}
+
+func (r *OrganizationRepositoryImpl) GetAllOrganizations() ([]*models.Organization, error) {
+	var organizations []*models.Organization
+	err := r.db.Order("id").Find(&organizations).Error
+	return organizations, err
+}
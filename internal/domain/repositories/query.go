@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Page selects a window of an ordered result set, a zero Limit selects every row after Offset. Sort is the name of a sortable field, prefixed with
+// "-" for descending order; an empty Sort falls back to the entity's default order.
+type Page struct {
+	Limit  int
+	Offset int
+	Sort   string
+}
+
+// TeamFilter narrows down a team query, zero values are ignored
+type TeamFilter struct {
+	NameContains string
+	MinAttack    *int
+	MaxAttack    *int
+	MinDefense   *int
+	MaxDefense   *int
+	VenueID      uint // Teams whose home ground it is
+}
+
+// VenueFilter narrows down a venue query, zero values are ignored
+type VenueFilter struct {
+	NameContains string
+	City         string // Compared ignoring case
+}
+
+// League statuses that can be filtered on, derived from the current week
+const (
+	LeagueStatusPending  = "pending"
+	LeagueStatusActive   = "active"
+	LeagueStatusFinished = "finished"
+)
+
+// LeagueFilter narrows down a league query, zero values are ignored
+type LeagueFilter struct {
+	NameContains string
+	Status       string
+	TeamID       uint
+}
+
+// MatchFilter narrows down a match query, zero values are ignored
+type MatchFilter struct {
+	LeagueID uint
+	TeamID   uint
+	VenueID  uint
+	WeekFrom *int
+	WeekTo   *int
+	Status   string
+}
+
+// paginate applies the order, limit and offset of a page. sortable maps the accepted sort fields to columns.
+func paginate(query *gorm.DB, page Page, sortable map[string]string, defaultOrder string) (*gorm.DB, error) {
+	order := defaultOrder
+	if page.Sort != "" {
+		field := strings.TrimPrefix(page.Sort, "-")
+		column, ok := sortable[field]
+		if !ok {
+			fields := make([]string, 0, len(sortable))
+			for name := range sortable {
+				fields = append(fields, name)
+			}
+			sort.Strings(fields)
+			return nil, apperrors.Validation("validation_failed", "cannot sort by %q", field).
+				WithFields(apperrors.FieldError{Field: "sort", Message: "must be one of " + strings.Join(fields, ", ")})
+		}
+
+		order = column
+		if strings.HasPrefix(page.Sort, "-") {
+			order += " DESC"
+		}
+		// Keep the order stable for rows with equal sort values
+		order += ", id"
+	}
+
+	query = query.Order(order).Offset(page.Offset)
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+	return query, nil
+}
+
+// containsPattern builds a LIKE pattern matching values that contain s, with LIKE wildcards in s escaped
+func containsPattern(s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(s))
+	return "%" + escaped + "%"
+}
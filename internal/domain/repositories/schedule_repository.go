@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleRepository stores the advancement schedules of leagues, at most one per league
+type ScheduleRepository interface {
+	GetScheduleByLeague(leagueID uint) (*models.AdvancementSchedule, error)
+	// SaveSchedule creates the schedule or updates it, bumping its revision
+	SaveSchedule(schedule *models.AdvancementSchedule) error
+	DeleteSchedule(leagueID uint) error
+	// DueSchedules returns the schedules of every organization that are not paused and whose next run is due at now.
+	// It ignores the scope, it is meant for the scheduler that runs the schedules of all tenants.
+	DueSchedules(now time.Time, limit int) ([]*models.AdvancementSchedule, error)
+	// ClaimRun moves the schedule to its next run, provided nobody changed it since it was read, and records ranAt
+	// as its latest run unless it is nil. Only the caller that gets true may make the run, so a run is never made
+	// twice, even by several servers.
+	ClaimRun(schedule *models.AdvancementSchedule, next time.Time, ranAt *time.Time) (bool, error)
+	// RecordOutcome stores the error of the latest run, empty if it succeeded, and pauses the schedule if asked to
+	RecordOutcome(scheduleID uint, lastError string, pause bool) error
+	WithScope(scope Scope) ScheduleRepository
+}
+
+type ScheduleRepositoryImpl struct {
+	db    *gorm.DB
+	scope Scope
+}
+
+func NewScheduleRepository(db *gorm.DB) ScheduleRepository {
+	return &ScheduleRepositoryImpl{db: db}
+}
+
+// WithScope returns a repository restricted to the schedules of the scope's organization
+func (r *ScheduleRepositoryImpl) WithScope(scope Scope) ScheduleRepository {
+	return &ScheduleRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *ScheduleRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "advancement_schedules")
+}
+
+func (r *ScheduleRepositoryImpl) GetScheduleByLeague(leagueID uint) (*models.AdvancementSchedule, error) {
+	var schedule *models.AdvancementSchedule
+	err := r.scoped().Where("league_id = ?", leagueID).First(&schedule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("schedule_not_found", "league %d has no schedule", leagueID).Wrap(err)
+	}
+	return schedule, err
+}
+
+func (r *ScheduleRepositoryImpl) SaveSchedule(schedule *models.AdvancementSchedule) error {
+	if schedule.ID == 0 {
+		schedule.OrganizationID = r.scope.OrganizationID
+		schedule.Revision = 1
+		return r.db.Create(schedule).Error
+	}
+	// Save inserts rows it cannot update, so a schedule of another organization must not reach it
+	if !r.scope.owns(schedule.OrganizationID) {
+		return apperrors.NotFound("schedule_not_found", "league %d has no schedule", schedule.LeagueID)
+	}
+	schedule.Revision++
+	return r.db.Save(schedule).Error
+}
+
+// DeleteSchedule removes the schedule for good, so the league can be given a new one
+func (r *ScheduleRepositoryImpl) DeleteSchedule(leagueID uint) error {
+	return r.scoped().Unscoped().Where("league_id = ?", leagueID).Delete(&models.AdvancementSchedule{}).Error
+}
+
+func (r *ScheduleRepositoryImpl) DueSchedules(now time.Time, limit int) ([]*models.AdvancementSchedule, error) {
+	var schedules []*models.AdvancementSchedule
+	err := r.db.Where("paused = ? AND next_run_at <= ?", false, now).
+		Order("next_run_at, id").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *ScheduleRepositoryImpl) ClaimRun(schedule *models.AdvancementSchedule, next time.Time, ranAt *time.Time) (bool, error) {
+	updates := map[string]interface{}{
+		"next_run_at": next,
+		"revision":    gorm.Expr("revision + 1"),
+	}
+	if ranAt != nil {
+		updates["last_run_at"] = *ranAt
+	}
+
+	result := r.db.Model(&models.AdvancementSchedule{}).
+		Where("id = ? AND revision = ? AND paused = ?", schedule.ID, schedule.Revision, false).
+		Updates(updates)
+	if result.Error != nil || result.RowsAffected != 1 {
+		return false, result.Error
+	}
+	schedule.NextRunAt = next
+	if ranAt != nil {
+		schedule.LastRunAt = ranAt
+	}
+	schedule.Revision++
+	return true, nil
+}
+
+func (r *ScheduleRepositoryImpl) RecordOutcome(scheduleID uint, lastError string, pause bool) error {
+	updates := map[string]interface{}{"last_error": lastError}
+	if pause {
+		updates["paused"] = true
+		updates["revision"] = gorm.Expr("revision + 1")
+	}
+	return r.db.Model(&models.AdvancementSchedule{}).Where("id = ?", scheduleID).Updates(updates).Error
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestScheduleRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.AdvancementSchedule{}))
+
+	repo := NewScheduleRepository(db).WithScope(Scope{OrganizationID: 1})
+	now := time.Date(2024, 8, 16, 19, 0, 0, 0, time.UTC)
+
+	schedule := &models.AdvancementSchedule{LeagueID: 7, Kind: models.ScheduleInterval, IntervalMinutes: 10, NextRunAt: now}
+	require.NoError(t, repo.SaveSchedule(schedule))
+	assert.Equal(t, uint(1), schedule.OrganizationID)
+
+	_, err = NewScheduleRepository(db).WithScope(Scope{OrganizationID: 2}).GetScheduleByLeague(7)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	// Due schedules are found across organizations
+	due, err := NewScheduleRepository(db).DueSchedules(now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+
+	// Two schedulers read the same schedule, only the first one claims the run
+	first, second := due[0], *due[0]
+	claimed, err := repo.ClaimRun(first, now.Add(10*time.Minute), &now)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = repo.ClaimRun(&second, now.Add(10*time.Minute), &now)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	due, err = repo.DueSchedules(now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	// A paused schedule is neither due nor claimable
+	require.NoError(t, repo.RecordOutcome(schedule.ID, "league has already ended", true))
+	stored, err := repo.GetScheduleByLeague(7)
+	require.NoError(t, err)
+	assert.True(t, stored.Paused)
+	assert.Equal(t, "league has already ended", stored.LastError)
+	claimed, err = repo.ClaimRun(stored, now.Add(20*time.Minute), nil)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// A deleted schedule can be replaced
+	require.NoError(t, repo.DeleteSchedule(7))
+	require.NoError(t, repo.SaveSchedule(&models.AdvancementSchedule{LeagueID: 7, Kind: models.ScheduleInterval, IntervalMinutes: 5, NextRunAt: now}))
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// Scope identifies on whose behalf repositories act. Queries only see the rows of the organization and created
+// rows are assigned to it, so a scoped repository cannot read or change another tenant's data. Actor and Reason
+// are recorded with every audit entry written through the scope.
+// The zero Scope is bound to organization 0, which owns nothing outside of tests.
+type Scope struct {
+	OrganizationID uint
+	Actor          string // Subject of the caller, "system" if empty
+	Reason         string // Why the caller made the change, optional
+}
+
+// where filters a query on table to the rows of the scope's organization
+func (s Scope) where(db *gorm.DB, table string) *gorm.DB {
+	return db.Where(table+".organization_id = ?", s.OrganizationID)
+}
+
+// owns reports whether a row of the given organization belongs to the scope
+func (s Scope) owns(organizationID uint) bool {
+	return s.OrganizationID == organizationID
+}
@@ -0,0 +1,73 @@
+package repositories_test
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestScopeIsolatesOrganizations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{}))
+
+	red := repositories.NewUnitOfWork(db).WithScope(repositories.Scope{OrganizationID: 1})
+	blue := repositories.NewUnitOfWork(db).WithScope(repositories.Scope{OrganizationID: 2})
+
+	// Created rows belong to the organization of the scope, whatever the caller set
+	team := &models.Team{OrganizationID: 2, Name: "Arsenal", AttackStrength: 80, DefenseStrength: 70}
+	require.NoError(t, red.Teams().CreateTeam(team))
+	assert.Equal(t, uint(1), team.OrganizationID)
+	league := &models.League{Name: "Premier League"}
+	require.NoError(t, red.Leagues().CreateLeague(league))
+
+	// Another organization can neither read nor find them
+	_, err = blue.Teams().GetTeamByID(team.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	_, err = blue.Teams().GetTeamByName("Arsenal")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	_, err = blue.Leagues().GetLeagueByID(league.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	teams, total, err := blue.Teams().FindTeams(repositories.TeamFilter{}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Empty(t, teams)
+	assert.Zero(t, total)
+	leagues, err := blue.Leagues().GetAllLeagues()
+	assert.NoError(t, err)
+	assert.Empty(t, leagues)
+
+	// Nor change or delete them
+	assert.ErrorIs(t, blue.Teams().UpdateTeam(team), apperrors.ErrNotFound)
+	assert.ErrorIs(t, blue.Leagues().UpdateLeague(league), apperrors.ErrNotFound)
+	assert.ErrorIs(t, blue.Leagues().RemoveTeamFromLeague(league.ID, team.ID), apperrors.ErrNotFound)
+	require.NoError(t, blue.Teams().DeleteTeam(team.ID))
+	_, err = red.Teams().GetTeamByID(team.ID)
+	assert.NoError(t, err)
+
+	// A league cannot take a team of another organization
+	blueLeague := &models.League{Name: "Blue League", Teams: []models.Team{*team}}
+	assert.ErrorIs(t, blue.Leagues().CreateLeague(blueLeague), apperrors.ErrNotFound)
+	blueLeague.Teams = nil
+	require.NoError(t, blue.Leagues().CreateLeague(blueLeague))
+	blueLeague.Teams = []models.Team{*team}
+	assert.ErrorIs(t, blue.Leagues().UpdateLeague(blueLeague), apperrors.ErrNotFound)
+
+	// Transactions keep the scope
+	err = red.Transaction(func(tx repositories.UnitOfWork) error {
+		return tx.Matches().CreateMatch(&models.Match{LeagueID: league.ID, HomeTeamID: team.ID, Week: 1})
+	})
+	require.NoError(t, err)
+	matches, total, err := red.Matches().FindMatches(repositories.MatchFilter{}, repositories.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, uint(1), matches[0].OrganizationID)
+	_, err = blue.Matches().GetMatchByID(matches[0].ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
@@ -1,7 +1,9 @@
 package repositories
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
+	"errors"
 	"gorm.io/gorm"
 )
 
@@ -12,37 +14,53 @@ type StandingRepository interface {
 	DeleteStanding(id uint) error
 	GetAllStandings() ([]*models.Standing, error)
 	GetStandingByTeam(leagueID uint, teamID uint) (*models.Standing, error)
+	GetStandingsByLeague(leagueID uint) ([]*models.Standing, error)
+	WithScope(scope Scope) StandingRepository
 }
 
 type StandingRepositoryImpl struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope Scope
 }
 
 func NewStandingRepository(db *gorm.DB) StandingRepository {
 	return &StandingRepositoryImpl{db: db}
 }
 
+// WithScope returns a repository restricted to the standings of the scope's organization
+func (r *StandingRepositoryImpl) WithScope(scope Scope) StandingRepository {
+	return &StandingRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *StandingRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "standings")
+}
+
 func (r *StandingRepositoryImpl) CreateStanding(standing *models.Standing) error {
+	standing.OrganizationID = r.scope.OrganizationID
 	return r.db.Create(&standing).Error
 }
 
 func (r *StandingRepositoryImpl) GetStandingByID(id uint) (*models.Standing, error) {
 	var standing *models.Standing
-	err := r.db.First(&standing, id).Error
-	return standing, err
+	err := r.scoped().First(&standing, id).Error
+	return standing, translateError(err, "standing", id)
 }
 
 func (r *StandingRepositoryImpl) UpdateStanding(standing *models.Standing) error {
+	if !r.scope.owns(standing.OrganizationID) {
+		return translateError(gorm.ErrRecordNotFound, "standing", standing.ID)
+	}
 	return r.db.Save(&standing).Error
 }
 
 func (r *StandingRepositoryImpl) DeleteStanding(id uint) error {
-	return r.db.Delete(&models.Standing{}, id).Error
+	return r.scoped().Delete(&models.Standing{}, id).Error
 }
 
 func (r *StandingRepositoryImpl) GetAllStandings() ([]*models.Standing, error) {
 	var standings []*models.Standing
-	err := r.db.Find(&standings).Error
+	err := r.scoped().Find(&standings).Error
 	return standings, err
 }
 
@@ -50,8 +68,17 @@ func (r *StandingRepositoryImpl) GetStandingByTeam(leagueID uint, teamID uint) (
 	var standing *models.Standing
 
 	// query standings with leagueID and teamID matching the requested one
-	err := r.db.Where("league_id = ? AND team_id = ?", leagueID, teamID).
+	err := r.scoped().Where("league_id = ? AND team_id = ?", leagueID, teamID).
 		First(&standing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return standing, apperrors.NotFound("standing_not_found", "standing of team %d in league %d not found", teamID, leagueID).Wrap(err)
+	}
 
 	return standing, err
 }
+
+func (r *StandingRepositoryImpl) GetStandingsByLeague(leagueID uint) ([]*models.Standing, error) {
+	var standings []*models.Standing
+	err := r.scoped().Where("league_id = ?", leagueID).Order("team_id").Find(&standings).Error
+	return standings, err
+}
@@ -72,4 +72,11 @@ func TestStandingRepository(t *testing.T) {
 	assert.Equal(t, standing1.Draws, standingByTeam.Draws)
 	assert.Equal(t, standing1.Losses, standingByTeam.Losses)
 	assert.Equal(t, standing1.GoalDifference, standingByTeam.GoalDifference)
+
+	// Test GetStandingsByLeague
+	leagueStandings, err := repo.GetStandingsByLeague(2)
+	assert.NoError(t, err)
+	assert.Len(t, leagueStandings, 2)
+	assert.Equal(t, standing1.TeamID, leagueStandings[0].TeamID)
+	assert.Equal(t, standing2.TeamID, leagueStandings[1].TeamID)
 }
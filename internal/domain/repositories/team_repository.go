@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"gorm.io/gorm"
 )
@@ -8,39 +9,119 @@ import (
 type TeamRepository interface {
 	CreateTeam(team *models.Team) error
 	GetTeamByID(id uint) (*models.Team, error)
+	GetTeamByName(name string) (*models.Team, error)
 	UpdateTeam(team *models.Team) error
 	DeleteTeam(id uint) error
 	GetAllTeams() ([]*models.Team, error)
+	FindTeams(filter TeamFilter, page Page) ([]*models.Team, int64, error)
+	WithScope(scope Scope) TeamRepository
 }
 
 type TeamRepositoryImpl struct {
-	db *gorm.DB
+	db    *gorm.DB
+	scope Scope
 }
 
 func NewTeamRepository(db *gorm.DB) TeamRepository {
 	return &TeamRepositoryImpl{db: db}
 }
 
+// WithScope returns a repository restricted to the teams of the scope's organization
+func (r *TeamRepositoryImpl) WithScope(scope Scope) TeamRepository {
+	return &TeamRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *TeamRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "teams")
+}
+
 func (r *TeamRepositoryImpl) CreateTeam(team *models.Team) error {
-	return r.db.Create(&team).Error
+	team.OrganizationID = r.scope.OrganizationID
+	return r.translateWriteError(r.db.Create(&team).Error, team)
 }
 
 func (r *TeamRepositoryImpl) GetTeamByID(id uint) (*models.Team, error) {
 	var team *models.Team
-	err := r.db.First(&team, id).Error
-	return team, err
+	err := r.scoped().First(&team, id).Error
+	return team, translateError(err, "team", id)
+}
+
+// GetTeamByName finds a team by its name, ignoring case
+func (r *TeamRepositoryImpl) GetTeamByName(name string) (*models.Team, error) {
+	var team *models.Team
+	err := r.scoped().Where("LOWER(name) = LOWER(?)", name).First(&team).Error
+	return team, translateError(err, "team", name)
 }
 
 func (r *TeamRepositoryImpl) UpdateTeam(team *models.Team) error {
-	return r.db.Save(&team).Error
+	// Save inserts rows it cannot update, so a team of another organization must not reach it
+	if !r.scope.owns(team.OrganizationID) {
+		return translateError(gorm.ErrRecordNotFound, "team", team.ID)
+	}
+	return r.translateWriteError(r.db.Save(&team).Error, team)
+}
+
+// translateWriteError turns the violation of the unique index on the names of an organization's teams into a conflict.
+// The services check the name first, the index catches the writes that raced past the check.
+func (r *TeamRepositoryImpl) translateWriteError(err error, team *models.Team) error {
+	if isDuplicate(r.db, err) {
+		return apperrors.Conflict("team_name_taken", "a team named %q already exists", team.Name).
+			WithFields(apperrors.FieldError{Field: "name", Message: "must be unique"}).Wrap(err)
+	}
+	return err
 }
 
 func (r *TeamRepositoryImpl) DeleteTeam(id uint) error {
-	return r.db.Delete(&models.Team{}, id).Error
+	return r.scoped().Delete(&models.Team{}, id).Error
 }
 
 func (r *TeamRepositoryImpl) GetAllTeams() ([]*models.Team, error) {
 	var teams []*models.Team
-	err := r.db.Find(&teams).Error
+	err := r.scoped().Find(&teams).Error
 	return teams, err
 }
+
+// FindTeams returns one page of the teams matching the filter and the total number of matching teams
+func (r *TeamRepositoryImpl) FindTeams(filter TeamFilter, page Page) ([]*models.Team, int64, error) {
+	query := r.scoped().Model(&models.Team{})
+	if filter.NameContains != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, containsPattern(filter.NameContains))
+	}
+	if filter.VenueID != 0 {
+		query = query.Where("home_venue_id = ?", filter.VenueID)
+	}
+	if filter.MinAttack != nil {
+		query = query.Where("attack_strength >= ?", *filter.MinAttack)
+	}
+	if filter.MaxAttack != nil {
+		query = query.Where("attack_strength <= ?", *filter.MaxAttack)
+	}
+	if filter.MinDefense != nil {
+		query = query.Where("defense_strength >= ?", *filter.MinDefense)
+	}
+	if filter.MaxDefense != nil {
+		query = query.Where("defense_strength <= ?", *filter.MaxDefense)
+	}
+	// Allow the filtered query to be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	paged, err := paginate(query, page, map[string]string{
+		"id":               "id",
+		"name":             "name",
+		"attack_strength":  "attack_strength",
+		"defense_strength": "defense_strength",
+		"created_at":       "created_at",
+	}, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var teams []*models.Team
+	err = paged.Find(&teams).Error
+	return teams, total, err
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -46,3 +47,78 @@ func TestTeamRepository(t *testing.T) {
 	_, err = repo.GetTeamByID(team.ID)
 	assert.Error(t, err)
 }
+
+func TestFindTeams(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.Team{})
+	assert.NoError(t, err)
+
+	repo := NewTeamRepository(db)
+	for _, team := range []models.Team{
+		{Name: "Arsenal", AttackStrength: 85, DefenseStrength: 80},
+		{Name: "Aston Villa", AttackStrength: 70, DefenseStrength: 65},
+		{Name: "Chelsea", AttackStrength: 80, DefenseStrength: 75},
+		{Name: "100%_Club", AttackStrength: 10, DefenseStrength: 10},
+	} {
+		team := team
+		assert.NoError(t, repo.CreateTeam(&team))
+	}
+
+	// Name filter ignores case
+	teams, total, err := repo.FindTeams(TeamFilter{NameContains: "a"}, Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	assert.Len(t, teams, 3)
+
+	// LIKE wildcards are matched literally
+	teams, total, err = repo.FindTeams(TeamFilter{NameContains: "%_"}, Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, "100%_Club", teams[0].Name)
+
+	// Strength range
+	minAttack, maxDefense := 75, 78
+	teams, total, err = repo.FindTeams(TeamFilter{MinAttack: &minAttack, MaxDefense: &maxDefense}, Page{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, "Chelsea", teams[0].Name)
+
+	// The total counts every match while the page holds only its window
+	teams, total, err = repo.FindTeams(TeamFilter{}, Page{Limit: 2, Offset: 1, Sort: "-attack_strength"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, total)
+	assert.Len(t, teams, 2)
+	assert.Equal(t, "Chelsea", teams[0].Name)
+	assert.Equal(t, "Aston Villa", teams[1].Name)
+
+	// Unknown sort fields are rejected
+	_, _, err = repo.FindTeams(TeamFilter{}, Page{Sort: "password"})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
+
+func TestTeamNamesAreUniqueInAnOrganization(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Team{}))
+	assert.NoError(t, db.Exec(models.CreateTeamNameIndex).Error)
+
+	// The index holds even for writes that skip the service's check, e.g. two requests racing each other
+	repo := NewTeamRepository(db).WithScope(Scope{OrganizationID: 1})
+	arsenal := models.Team{Name: "Arsenal"}
+	assert.NoError(t, repo.CreateTeam(&arsenal))
+	err = repo.CreateTeam(&models.Team{Name: "ARSENAL"})
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+	assert.Equal(t, "team_name_taken", apperrors.CodeOf(err))
+
+	chelsea := models.Team{Name: "Chelsea"}
+	assert.NoError(t, repo.CreateTeam(&chelsea))
+	chelsea.Name = "arsenal"
+	assert.ErrorIs(t, repo.UpdateTeam(&chelsea), apperrors.ErrConflict)
+
+	// Other organizations have names of their own, and the name of a deleted team is free again
+	assert.NoError(t, NewTeamRepository(db).WithScope(Scope{OrganizationID: 2}).CreateTeam(&models.Team{Name: "Arsenal"}))
+	assert.NoError(t, repo.DeleteTeam(arsenal.ID))
+	assert.NoError(t, repo.CreateTeam(&models.Team{Name: "Arsenal"}))
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// UnitOfWork gives access to repositories that share the same database handle.
+// WithScope returns a unit of work whose repositories, including those of its transactions, are restricted to the scope.
+// Transaction runs fn against repositories bound to a single transaction, which is committed
+// if fn returns nil and rolled back otherwise. Transactions can be nested.
+type UnitOfWork interface {
+	Leagues() LeagueRepository
+	Teams() TeamRepository
+	Matches() MatchRepository
+	Standings() StandingRepository
+	Venues() VenueRepository
+	Constraints() ConstraintRepository
+	Audit() AuditRepository
+	Schedules() ScheduleRepository
+	Webhooks() WebhookRepository
+	Transaction(fn func(tx UnitOfWork) error) error
+	WithScope(scope Scope) UnitOfWork
+}
+
+type UnitOfWorkImpl struct {
+	db    *gorm.DB
+	scope Scope
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &UnitOfWorkImpl{db: db}
+}
+
+func (u *UnitOfWorkImpl) Leagues() LeagueRepository {
+	return NewLeagueRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Teams() TeamRepository {
+	return NewTeamRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Matches() MatchRepository {
+	return NewMatchRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Standings() StandingRepository {
+	return NewStandingRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Venues() VenueRepository {
+	return NewVenueRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Constraints() ConstraintRepository {
+	return NewConstraintRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Audit() AuditRepository {
+	return NewAuditRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Schedules() ScheduleRepository {
+	return NewScheduleRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Webhooks() WebhookRepository {
+	return NewWebhookRepository(u.db).WithScope(u.scope)
+}
+
+func (u *UnitOfWorkImpl) Transaction(fn func(tx UnitOfWork) error) error {
+	// gorm uses savepoints when Transaction is called on a handle that is already inside a transaction
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&UnitOfWorkImpl{db: tx, scope: u.scope})
+	})
+}
+
+func (u *UnitOfWorkImpl) WithScope(scope Scope) UnitOfWork {
+	return &UnitOfWorkImpl{db: u.db, scope: scope}
+}
@@ -0,0 +1,63 @@
+package repositories_test
+
+import (
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.League{}, &models.Team{}, &models.Match{}, &models.Standing{})
+	assert.NoError(t, err)
+
+	uow := repositories.NewUnitOfWork(db)
+
+	// Rolled back
+	league := &models.League{Name: "Rolled Back"}
+	err = uow.Transaction(func(tx repositories.UnitOfWork) error {
+		if err := tx.Leagues().CreateLeague(league); err != nil {
+			return err
+		}
+		if err := tx.Matches().CreateMatch(&models.Match{LeagueID: league.ID, Week: 1}); err != nil {
+			return err
+		}
+		league.CurrentWeek = 1
+		if err := tx.Leagues().UpdateLeague(league); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+
+	leagues, err := uow.Leagues().GetAllLeagues()
+	assert.NoError(t, err)
+	assert.Empty(t, leagues)
+
+	matches, err := uow.Matches().GetAllMatches()
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// Committed, including a nested transaction
+	committed := &models.League{Name: "Committed"}
+	err = uow.Transaction(func(tx repositories.UnitOfWork) error {
+		if err := tx.Leagues().CreateLeague(committed); err != nil {
+			return err
+		}
+		return tx.Transaction(func(nested repositories.UnitOfWork) error {
+			return nested.Standings().CreateStanding(&models.Standing{LeagueID: committed.ID, TeamID: 1, Points: 3})
+		})
+	})
+	assert.NoError(t, err)
+
+	readLeague, err := uow.Leagues().GetLeagueByID(committed.ID)
+	assert.NoError(t, err)
+	assert.Len(t, readLeague.Standings, 1)
+}
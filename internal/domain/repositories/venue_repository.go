@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type VenueRepository interface {
+	CreateVenue(venue *models.Venue) error
+	GetVenueByID(id uint) (*models.Venue, error)
+	UpdateVenue(venue *models.Venue) error
+	DeleteVenue(id uint) error
+	FindVenues(filter VenueFilter, page Page) ([]*models.Venue, int64, error)
+	WithScope(scope Scope) VenueRepository
+}
+
+type VenueRepositoryImpl struct {
+	db    *gorm.DB
+	scope Scope
+}
+
+func NewVenueRepository(db *gorm.DB) VenueRepository {
+	return &VenueRepositoryImpl{db: db}
+}
+
+// WithScope returns a repository restricted to the venues of the scope's organization
+func (r *VenueRepositoryImpl) WithScope(scope Scope) VenueRepository {
+	return &VenueRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *VenueRepositoryImpl) scoped() *gorm.DB {
+	return r.scope.where(r.db, "venues")
+}
+
+func (r *VenueRepositoryImpl) CreateVenue(venue *models.Venue) error {
+	venue.OrganizationID = r.scope.OrganizationID
+	return r.db.Create(&venue).Error
+}
+
+func (r *VenueRepositoryImpl) GetVenueByID(id uint) (*models.Venue, error) {
+	var venue *models.Venue
+	err := r.scoped().First(&venue, id).Error
+	return venue, translateError(err, "venue", id)
+}
+
+func (r *VenueRepositoryImpl) UpdateVenue(venue *models.Venue) error {
+	// Save inserts rows it cannot update, so a venue of another organization must not reach it
+	if !r.scope.owns(venue.OrganizationID) {
+		return translateError(gorm.ErrRecordNotFound, "venue", venue.ID)
+	}
+	return r.db.Save(&venue).Error
+}
+
+func (r *VenueRepositoryImpl) DeleteVenue(id uint) error {
+	return r.scoped().Delete(&models.Venue{}, id).Error
+}
+
+// FindVenues returns one page of the venues matching the filter and the total number of matching venues
+func (r *VenueRepositoryImpl) FindVenues(filter VenueFilter, page Page) ([]*models.Venue, int64, error) {
+	query := r.scoped().Model(&models.Venue{})
+	if filter.NameContains != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, containsPattern(filter.NameContains))
+	}
+	if filter.City != "" {
+		query = query.Where("LOWER(city) = LOWER(?)", filter.City)
+	}
+	// Allow the filtered query to be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	paged, err := paginate(query, page, map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"city":       "city",
+		"capacity":   "capacity",
+		"created_at": "created_at",
+	}, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var venues []*models.Venue
+	err = paged.Find(&venues).Error
+	return venues, total, err
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestVenueRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Venue{}))
+
+	repo := NewVenueRepository(db).WithScope(Scope{OrganizationID: 1})
+	other := NewVenueRepository(db).WithScope(Scope{OrganizationID: 2})
+
+	for _, venue := range []*models.Venue{
+		{Name: "Riverside Park", City: "Leeds", Capacity: 30000},
+		{Name: "Hill Road", City: "leeds", Capacity: 12000},
+		{Name: "Harbour Stadium", City: "Hull", Capacity: 25000},
+	} {
+		assert.NoError(t, repo.CreateVenue(venue))
+	}
+	foreign := &models.Venue{Name: "Riverside Arena", City: "Leeds", Capacity: 8000}
+	assert.NoError(t, other.CreateVenue(foreign))
+
+	venues, total, err := repo.FindVenues(VenueFilter{City: "LEEDS"}, Page{Sort: "-capacity"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "Riverside Park", venues[0].Name)
+	assert.Equal(t, "Hill Road", venues[1].Name)
+
+	venues, total, err = repo.FindVenues(VenueFilter{NameContains: "river"}, Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total, "venues of other organizations are not found")
+	venue := venues[0]
+
+	venue.Capacity = 32000
+	assert.NoError(t, repo.UpdateVenue(venue))
+	stored, err := repo.GetVenueByID(venue.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 32000, stored.Capacity)
+
+	_, err = repo.GetVenueByID(foreign.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateVenue(foreign), apperrors.ErrNotFound)
+
+	assert.NoError(t, repo.DeleteVenue(venue.ID))
+	_, err = repo.GetVenueByID(venue.ID)
+	assert.Equal(t, "venue_not_found", apperrors.CodeOf(err))
+}
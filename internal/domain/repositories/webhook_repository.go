@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"LeagueManager/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository stores webhook subscriptions and the log of their deliveries
+type WebhookRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	GetSubscriptionByID(id uint) (*models.WebhookSubscription, error)
+	GetSubscriptionsByLeague(leagueID uint) ([]*models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	// DeleteSubscriptionsByLeague removes every subscription of the league, for when the league is deleted
+	DeleteSubscriptionsByLeague(leagueID uint) error
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	FindDeliveries(subscriptionID uint, page Page) ([]*models.WebhookDelivery, int64, error)
+	// DueDeliveries returns the pending deliveries of every organization whose next attempt is due at now, oldest first.
+	// It ignores the scope, it is meant for the dispatcher that sends the deliveries of all tenants.
+	DueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	WithScope(scope Scope) WebhookRepository
+}
+
+type WebhookRepositoryImpl struct {
+	db    *gorm.DB
+	scope Scope
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+// WithScope returns a repository restricted to the webhooks of the scope's organization
+func (r *WebhookRepositoryImpl) WithScope(scope Scope) WebhookRepository {
+	return &WebhookRepositoryImpl{db: r.db, scope: scope}
+}
+
+func (r *WebhookRepositoryImpl) CreateSubscription(subscription *models.WebhookSubscription) error {
+	subscription.OrganizationID = r.scope.OrganizationID
+	return r.db.Create(subscription).Error
+}
+
+func (r *WebhookRepositoryImpl) GetSubscriptionByID(id uint) (*models.WebhookSubscription, error) {
+	var subscription *models.WebhookSubscription
+	err := r.scope.where(r.db, "webhook_subscriptions").First(&subscription, id).Error
+	return subscription, translateError(err, "webhook", id)
+}
+
+func (r *WebhookRepositoryImpl) GetSubscriptionsByLeague(leagueID uint) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	err := r.scope.where(r.db, "webhook_subscriptions").Where("league_id = ?", leagueID).Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *WebhookRepositoryImpl) DeleteSubscription(id uint) error {
+	return r.scope.where(r.db, "webhook_subscriptions").Delete(&models.WebhookSubscription{}, id).Error
+}
+
+func (r *WebhookRepositoryImpl) DeleteSubscriptionsByLeague(leagueID uint) error {
+	return r.scope.where(r.db, "webhook_subscriptions").Where("league_id = ?", leagueID).Delete(&models.WebhookSubscription{}).Error
+}
+
+// CreateDelivery adds a delivery to the log, it belongs to the organization of its subscription rather than the scope's
+func (r *WebhookRepositoryImpl) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *WebhookRepositoryImpl) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// FindDeliveries returns one page of the deliveries of a subscription, newest first by default, and their total number
+func (r *WebhookRepositoryImpl) FindDeliveries(subscriptionID uint, page Page) ([]*models.WebhookDelivery, int64, error) {
+	query := r.scope.where(r.db.Model(&models.WebhookDelivery{}), "webhook_deliveries").
+		Where("subscription_id = ?", subscriptionID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	paged, err := paginate(query, page, map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	}, "id DESC")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []*models.WebhookDelivery
+	err = paged.Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *WebhookRepositoryImpl) DueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
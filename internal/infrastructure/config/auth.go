@@ -0,0 +1,90 @@
+package config
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/presentation/controllers"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// minTokenSecretLength is the shortest accepted HMAC secret, the size of the SHA-256 digest
+const minTokenSecretLength = 32
+
+// NewAuthenticator builds the request authenticator from the environment:
+//
+//	AUTH_API_KEYS      comma-separated API keys, each written name@organization:role:key, where @organization
+//	                   may be left out for the default organization
+//	AUTH_TOKEN_SECRET  secret bearer tokens are signed with, at least 32 bytes
+//	AUTH_DISABLED      true to let every request through as an admin of the default organization, for local
+//	                   development only
+//
+// The organizations named by API keys are created if they do not exist yet.
+// Starting without any credential and without AUTH_DISABLED is an error, so a misconfigured
+// server does not end up open to everyone.
+func NewAuthenticator(organizations services.OrganizationService) (*controllers.Authenticator, error) {
+	if disabled, _ := strconv.ParseBool(os.Getenv("AUTH_DISABLED")); disabled {
+		organization, err := organizations.EnsureOrganization(models.DefaultOrganizationName)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Warn("Authentication is disabled, every request is treated as an admin")
+		return controllers.DisabledAuthenticator(organization.ID), nil
+	}
+
+	keys, err := parseAPIKeys(os.Getenv("AUTH_API_KEYS"), organizations)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := []byte(os.Getenv("AUTH_TOKEN_SECRET"))
+	if len(secret) > 0 && len(secret) < minTokenSecretLength {
+		return nil, fmt.Errorf("AUTH_TOKEN_SECRET must be at least %d bytes long", minTokenSecretLength)
+	}
+
+	if len(keys) == 0 && len(secret) == 0 {
+		return nil, errors.New("no credentials configured: set AUTH_API_KEYS or AUTH_TOKEN_SECRET, or AUTH_DISABLED=true for local development")
+	}
+	return controllers.NewAuthenticator(keys, secret), nil
+}
+
+func parseAPIKeys(value string, organizations services.OrganizationService) ([]controllers.APIKey, error) {
+	var keys []controllers.APIKey
+	// Errors name an entry by its position, its text holds the key
+	for i, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("malformed AUTH_API_KEYS entry %d, want name@organization:role:key", i+1)
+		}
+
+		name, organizationName, found := strings.Cut(parts[0], "@")
+		if !found {
+			organizationName = models.DefaultOrganizationName
+		}
+		if name == "" || organizationName == "" {
+			return nil, fmt.Errorf("malformed AUTH_API_KEYS entry %d, want name@organization:role:key", i+1)
+		}
+		organization, err := organizations.EnsureOrganization(organizationName)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_API_KEYS entry %d: %w", i+1, err)
+		}
+
+		// The role is left out of the error too, it is the key when the entry has its parts in the wrong order
+		role, err := controllers.ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_API_KEYS entry %d: unknown role, want one of viewer, manager, admin", i+1)
+		}
+		keys = append(keys, controllers.APIKey{Name: name, Role: role, OrganizationID: organization.ID, Key: parts[2]})
+	}
+	return keys, nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupOrganizations(t *testing.T) (services.OrganizationService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Organization{}, &models.Team{}, &models.League{}, &models.Match{}, &models.Standing{}, &models.AuditEntry{}, &models.FixtureConstraint{},
+		&models.AdvancementSchedule{}, &models.WebhookSubscription{}))
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return services.NewOrganizationService(repositories.NewOrganizationRepository(db)), db
+}
+
+func TestNewAuthenticatorFromEnvironment(t *testing.T) {
+	organizations, _ := setupOrganizations(t)
+	t.Setenv("AUTH_DISABLED", "")
+	t.Setenv("AUTH_API_KEYS", "")
+	t.Setenv("AUTH_TOKEN_SECRET", "")
+
+	// No credentials and authentication not disabled fails closed
+	_, err := NewAuthenticator(organizations)
+	assert.Error(t, err)
+
+	t.Setenv("AUTH_API_KEYS", "scoreboard:viewer:s3cret, ops@racing:admin:t0p:secret")
+	auth, err := NewAuthenticator(organizations)
+	assert.NoError(t, err)
+	assert.NotNil(t, auth)
+
+	// Organizations named by the keys are created, keys without one belong to the default organization
+	all, err := organizations.GetAllOrganizations()
+	assert.NoError(t, err)
+	if assert.Len(t, all, 2) {
+		assert.Equal(t, models.DefaultOrganizationName, all[0].Name)
+		assert.Equal(t, "racing", all[1].Name)
+	}
+
+	t.Setenv("AUTH_API_KEYS", "scoreboard:owner:s3cret")
+	_, err = NewAuthenticator(organizations)
+	assert.ErrorContains(t, err, "unknown role")
+
+	t.Setenv("AUTH_API_KEYS", "scoreboard:viewer")
+	_, err = NewAuthenticator(organizations)
+	assert.ErrorContains(t, err, "name@organization:role:key")
+
+	// The entries are named by their position, never by their text, which holds the keys
+	for _, value := range []string{"scoreboard:viewer:s3cret, t0psecretkey", "scoreboard:viewer:s3cret, ops:t0psecretkey:admin"} {
+		t.Setenv("AUTH_API_KEYS", value)
+		_, err = NewAuthenticator(organizations)
+		assert.ErrorContains(t, err, "AUTH_API_KEYS entry 2")
+		assert.NotContains(t, err.Error(), "t0psecretkey")
+	}
+
+	t.Setenv("AUTH_API_KEYS", "scoreboard@:viewer:s3cret")
+	_, err = NewAuthenticator(organizations)
+	assert.ErrorContains(t, err, "name@organization:role:key")
+
+	t.Setenv("AUTH_API_KEYS", "")
+	t.Setenv("AUTH_TOKEN_SECRET", "too short")
+	_, err = NewAuthenticator(organizations)
+	assert.ErrorContains(t, err, "at least 32 bytes")
+
+	t.Setenv("AUTH_TOKEN_SECRET", "")
+	t.Setenv("AUTH_DISABLED", "true")
+	auth, err = NewAuthenticator(organizations)
+	assert.NoError(t, err)
+	assert.NotNil(t, auth)
+}
+
+func TestAssignToDefaultOrganization(t *testing.T) {
+	_, db := setupOrganizations(t)
+
+	// Rows stored before organizations existed have no organization
+	require.NoError(t, db.Create(&models.Team{Name: "Arsenal"}).Error)
+	require.NoError(t, db.Create(&models.League{Name: "Premier League"}).Error)
+	require.NoError(t, db.Model(&models.Team{}).Where("1 = 1").Update("organization_id", nil).Error)
+
+	require.NoError(t, assignToDefaultOrganization(db))
+	// Running the migration again keeps the same default organization
+	require.NoError(t, assignToDefaultOrganization(db))
+
+	var organizations []models.Organization
+	require.NoError(t, db.Find(&organizations).Error)
+	require.Len(t, organizations, 1)
+
+	var team models.Team
+	require.NoError(t, db.First(&team).Error)
+	assert.Equal(t, organizations[0].ID, team.OrganizationID)
+	var league models.League
+	require.NoError(t, db.First(&league).Error)
+	assert.Equal(t, organizations[0].ID, league.OrganizationID)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"LeagueManager/internal/application/events"
 	"LeagueManager/internal/application/services"
 	"LeagueManager/internal/domain/repositories"
 	"LeagueManager/internal/presentation/controllers"
@@ -18,6 +19,43 @@ type Initialization struct {
 	// Add the LeagueService and LeagueController fields
 	LeagueSvc  services.LeagueService
 	LeagueCtrl *controllers.LeagueController
+
+	OrganizationSvc  services.OrganizationService
+	OrganizationCtrl *controllers.OrganizationController
+
+	AuditSvc  services.AuditService
+	AuditCtrl *controllers.AuditController
+
+	Bus               events.Bus
+	WebhookSvc        services.WebhookService
+	WebhookCtrl       *controllers.WebhookController
+	WebhookDispatcher *services.WebhookDispatcher
+
+	StreamCtrl *controllers.StreamController
+
+	LiveSvc  services.LiveMatchService
+	LiveCtrl *controllers.LiveController
+
+	ScheduleSvc  services.ScheduleService
+	ScheduleCtrl *controllers.ScheduleController
+	Scheduler    *services.LeagueScheduler
+
+	CalendarCtrl *controllers.CalendarController
+
+	VenueSvc  services.VenueService
+	VenueCtrl *controllers.VenueController
+
+	FixtureCtrl *controllers.FixtureController
+
+	ImportSvc  services.ImportService
+	ImportCtrl *controllers.ImportController
+
+	ExportCtrl *controllers.ExportController
+
+	ArchiveSvc  services.ArchiveService
+	ArchiveCtrl *controllers.ArchiveController
+
+	Auth *controllers.Authenticator
 }
 
 func NewInitialization(
@@ -29,6 +67,30 @@ func NewInitialization(
 	teamCtrl *controllers.TeamController,
 	leagueSvc services.LeagueService,
 	leagueCtrl *controllers.LeagueController,
+	organizationSvc services.OrganizationService,
+	organizationCtrl *controllers.OrganizationController,
+	auditSvc services.AuditService,
+	auditCtrl *controllers.AuditController,
+	bus events.Bus,
+	webhookSvc services.WebhookService,
+	webhookCtrl *controllers.WebhookController,
+	webhookDispatcher *services.WebhookDispatcher,
+	streamCtrl *controllers.StreamController,
+	liveSvc services.LiveMatchService,
+	liveCtrl *controllers.LiveController,
+	scheduleSvc services.ScheduleService,
+	scheduleCtrl *controllers.ScheduleController,
+	scheduler *services.LeagueScheduler,
+	calendarCtrl *controllers.CalendarController,
+	venueSvc services.VenueService,
+	venueCtrl *controllers.VenueController,
+	fixtureCtrl *controllers.FixtureController,
+	importSvc services.ImportService,
+	importCtrl *controllers.ImportController,
+	exportCtrl *controllers.ExportController,
+	archiveSvc services.ArchiveService,
+	archiveCtrl *controllers.ArchiveController,
+	auth *controllers.Authenticator,
 ) *Initialization {
 	return &Initialization{
 		TeamRepo:     teamRepo,
@@ -39,5 +101,67 @@ func NewInitialization(
 		TeamCtrl:     teamCtrl,
 		LeagueSvc:    leagueSvc,
 		LeagueCtrl:   leagueCtrl,
+
+		OrganizationSvc:  organizationSvc,
+		OrganizationCtrl: organizationCtrl,
+
+		AuditSvc:  auditSvc,
+		AuditCtrl: auditCtrl,
+
+		Bus:               bus,
+		WebhookSvc:        webhookSvc,
+		WebhookCtrl:       webhookCtrl,
+		WebhookDispatcher: webhookDispatcher,
+
+		StreamCtrl: streamCtrl,
+
+		LiveSvc:  liveSvc,
+		LiveCtrl: liveCtrl,
+
+		ScheduleSvc:  scheduleSvc,
+		ScheduleCtrl: scheduleCtrl,
+		Scheduler:    scheduler,
+
+		CalendarCtrl: calendarCtrl,
+
+		VenueSvc:  venueSvc,
+		VenueCtrl: venueCtrl,
+
+		FixtureCtrl: fixtureCtrl,
+
+		ImportSvc:  importSvc,
+		ImportCtrl: importCtrl,
+
+		ExportCtrl: exportCtrl,
+
+		ArchiveSvc:  archiveSvc,
+		ArchiveCtrl: archiveCtrl,
+
+		Auth: auth,
+	}
+}
+
+// CLIInitialization holds what the command-line tools need: the services, without the controllers and the
+// authenticator, which only serve requests
+type CLIInitialization struct {
+	TeamSvc         services.TeamService
+	LeagueSvc       services.LeagueService
+	OrganizationSvc services.OrganizationService
+
+	// Subscribed to the bus, the changes made by a tool queue their webhook deliveries for the server to send
+	WebhookDispatcher *services.WebhookDispatcher
+}
+
+func NewCLIInitialization(
+	teamSvc services.TeamService,
+	leagueSvc services.LeagueService,
+	organizationSvc services.OrganizationService,
+	webhookDispatcher *services.WebhookDispatcher,
+) *CLIInitialization {
+	return &CLIInitialization{
+		TeamSvc:           teamSvc,
+		LeagueSvc:         leagueSvc,
+		OrganizationSvc:   organizationSvc,
+		WebhookDispatcher: webhookDispatcher,
 	}
 }
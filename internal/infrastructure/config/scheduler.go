@@ -0,0 +1,36 @@
+package config
+
+import (
+	"LeagueManager/internal/application/services"
+	"fmt"
+	"os"
+	"time"
+)
+
+// NewSchedulerSettings reads the league scheduler settings from the environment, unset variables keep their defaults:
+//
+//	SCHEDULER_POLL_INTERVAL         how often due schedules are looked for, e.g. 15s
+//	SCHEDULER_MISSED_RUN_TOLERANCE  how late a run may be and still be made by schedules that skip missed runs, e.g. 5m
+func NewSchedulerSettings() (services.SchedulerSettings, error) {
+	settings := services.DefaultSchedulerSettings()
+
+	for _, duration := range []struct {
+		name   string
+		target *time.Duration
+	}{
+		{"SCHEDULER_POLL_INTERVAL", &settings.PollInterval},
+		{"SCHEDULER_MISSED_RUN_TOLERANCE", &settings.MissedRunTolerance},
+	} {
+		value := os.Getenv(duration.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return settings, fmt.Errorf("%s must be a positive duration such as 15s, got %q", duration.name, value)
+		}
+		*duration.target = parsed
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// The other tests run the services against in-memory SQLite, these run them against the databases the server is
+// deployed with: what the SQL of the repositories relies on, LIKE escapes, NULL ordering, the check-and-set of
+// schedule runs and savepoints, behaves differently from one database to the next.
+
+func TestServicesOnSQLite(t *testing.T) {
+	settings := DefaultDatabaseSettings()
+	settings.DSN = filepath.Join(t.TempDir(), "league.db")
+	settings.JournalMode = "WAL"
+
+	db, err := ConnectToDB(settings)
+	require.NoError(t, err)
+	t.Cleanup(func() { closeDatabase(db) })
+	exerciseServices(t, db)
+}
+
+// TestServicesOnPostgres runs against the PostgreSQL database TEST_POSTGRES_DSN names, see TestConnectToPostgres. The
+// data is kept in an organization of its own, so the test can run again on the same database.
+func TestServicesOnPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := ConnectToDB(DatabaseSettings{Driver: DriverPostgres, DSN: dsn, MaxOpenConns: 4})
+	require.NoError(t, err)
+	t.Cleanup(func() { closeDatabase(db) })
+	exerciseServices(t, db)
+}
+
+// exerciseServices creates a league in a new organization, starts it, advances it and checks the standings, along
+// with the queries whose SQL differs between databases
+func exerciseServices(t *testing.T, db *gorm.DB) {
+	organization, err := services.NewOrganizationService(repositories.NewOrganizationRepository(db)).
+		EnsureOrganization(fmt.Sprintf("services-test-%d", time.Now().UnixNano()))
+	require.NoError(t, err)
+	scope := repositories.Scope{OrganizationID: organization.ID, Actor: "test"}
+
+	uow := repositories.NewUnitOfWork(db)
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	matchRepo := repositories.NewMatchRepository(db)
+	teams := services.NewTeamService(teamRepo, leagueRepo, uow).WithScope(scope)
+	leagues := services.NewLeagueService(leagueRepo, teamRepo, matchRepo, repositories.NewStandingRepository(db), uow, events.NewBus()).WithScope(scope)
+
+	var created []*models.Team
+	for i, name := range []string{"Arsenal", "Aston Villa", "Chelsea", "100%_Club"} {
+		team := &models.Team{Name: name, AttackStrength: 60 + i*10, DefenseStrength: 70}
+		require.NoError(t, teams.CreateTeam(team))
+		created = append(created, team)
+	}
+
+	t.Run("unique team names", func(t *testing.T) {
+		err := teamRepo.WithScope(scope).CreateTeam(&models.Team{Name: "ARSENAL"})
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+		assert.Equal(t, "team_name_taken", apperrors.CodeOf(err))
+	})
+
+	t.Run("name filters", func(t *testing.T) {
+		found, total, err := teams.FindTeams(repositories.TeamFilter{NameContains: "A"}, repositories.Page{})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, total, "the filter ignores case")
+		assert.Len(t, found, 3)
+
+		found, _, err = teams.FindTeams(repositories.TeamFilter{NameContains: "%_"}, repositories.Page{})
+		require.NoError(t, err)
+		if assert.Len(t, found, 1, "LIKE wildcards are matched literally") {
+			assert.Equal(t, "100%_Club", found[0].Name)
+		}
+	})
+
+	t.Run("savepoints", func(t *testing.T) {
+		err := uow.WithScope(scope).Transaction(func(tx repositories.UnitOfWork) error {
+			inner := tx.Transaction(func(inner repositories.UnitOfWork) error {
+				require.NoError(t, inner.Teams().CreateTeam(&models.Team{Name: "Rolled Back"}))
+				return errors.New("undo")
+			})
+			require.Error(t, inner)
+			return tx.Teams().CreateTeam(&models.Team{Name: "Kept"})
+		})
+		require.NoError(t, err)
+
+		_, err = teams.GetTeamByName("Kept")
+		assert.NoError(t, err)
+		_, err = teams.GetTeamByName("Rolled Back")
+		assert.ErrorIs(t, err, apperrors.ErrNotFound, "the nested transaction rolled back alone")
+	})
+
+	league := &models.League{Name: "Premier League"}
+	require.NoError(t, leagues.CreateLeague(league))
+	for _, team := range created {
+		require.NoError(t, leagues.AddTeamToLeague(league.ID, team.ID))
+	}
+	require.NoError(t, leagues.StartLeague(league.ID))
+	require.NoError(t, leagues.AdvanceWeek(league.ID))
+
+	t.Run("standings", func(t *testing.T) {
+		league, err := leagues.GetLeagueByID(league.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, league.CurrentWeek)
+
+		standings, err := leagues.GetStandings(league.ID)
+		require.NoError(t, err)
+		require.Len(t, standings, len(created))
+		goals := 0
+		for i, standing := range standings {
+			assert.Equal(t, 1, standing.Played)
+			goals += standing.GoalDifference
+			if i > 0 {
+				assert.LessOrEqual(t, standing.Points, standings[i-1].Points, "the table is ranked")
+			}
+		}
+		assert.Zero(t, goals)
+	})
+
+	t.Run("undated matches come first", func(t *testing.T) {
+		matches := matchRepo.WithScope(scope)
+		played, err := matches.GetMatchesByTeam(created[0].ID)
+		require.NoError(t, err)
+		require.Len(t, played, models.TotalWeeks)
+
+		kickoff := time.Date(2024, 8, 16, 19, 0, 0, 0, time.UTC)
+		dated := played[0]
+		dated.KickoffAt = &kickoff
+		require.NoError(t, matches.UpdateMatch(dated))
+
+		ordered, err := matches.GetMatchesByTeam(created[0].ID)
+		require.NoError(t, err)
+		require.Len(t, ordered, models.TotalWeeks)
+		assert.Nil(t, ordered[0].KickoffAt)
+		assert.Equal(t, dated.ID, ordered[len(ordered)-1].ID)
+	})
+
+	t.Run("schedule runs are claimed once", func(t *testing.T) {
+		scheduleRepo := repositories.NewScheduleRepository(db).WithScope(scope)
+		next := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		schedule := &models.AdvancementSchedule{
+			LeagueID: league.ID, Kind: models.ScheduleInterval, IntervalMinutes: 60, NextRunAt: next,
+			MissedRunPolicy: models.MissedRunsSkip,
+		}
+		require.NoError(t, scheduleRepo.SaveSchedule(schedule))
+
+		stale := *schedule
+		claimed, err := scheduleRepo.ClaimRun(schedule, next.Add(time.Hour), &next)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = scheduleRepo.ClaimRun(&stale, next.Add(time.Hour), &next)
+		require.NoError(t, err)
+		assert.False(t, claimed, "a run read before the claim cannot be claimed again")
+
+		stored, err := scheduleRepo.GetScheduleByLeague(league.ID)
+		require.NoError(t, err)
+		assert.Equal(t, schedule.Revision, stored.Revision)
+		assert.True(t, stored.NextRunAt.Equal(next.Add(time.Hour)))
+	})
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"LeagueManager/internal/application/services"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewWebhookSettings reads the webhook delivery settings from the environment, unset variables keep their defaults:
+//
+//	WEBHOOK_MAX_ATTEMPTS     attempts before a delivery is given up as failed
+//	WEBHOOK_INITIAL_BACKOFF  wait before the first retry, doubled for every further retry, e.g. 30s
+//	WEBHOOK_MAX_BACKOFF      longest wait between two attempts, e.g. 1h
+//	WEBHOOK_TIMEOUT          time a receiver has to answer, e.g. 10s
+//	WEBHOOK_ALLOWED_NETWORKS comma separated networks or addresses receivers may be in although they are loopback,
+//	                         private or link-local, e.g. 127.0.0.0/8 to test against a local receiver
+func NewWebhookSettings() (services.WebhookSettings, error) {
+	settings := services.DefaultWebhookSettings()
+
+	if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 1 {
+			return settings, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be a positive integer, got %q", value)
+		}
+		settings.MaxAttempts = attempts
+	}
+
+	for _, duration := range []struct {
+		name   string
+		target *time.Duration
+	}{
+		{"WEBHOOK_INITIAL_BACKOFF", &settings.InitialBackoff},
+		{"WEBHOOK_MAX_BACKOFF", &settings.MaxBackoff},
+		{"WEBHOOK_TIMEOUT", &settings.Timeout},
+	} {
+		value := os.Getenv(duration.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return settings, fmt.Errorf("%s must be a positive duration such as 30s, got %q", duration.name, value)
+		}
+		*duration.target = parsed
+	}
+
+	if value := os.Getenv("WEBHOOK_ALLOWED_NETWORKS"); value != "" {
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			network, err := netip.ParsePrefix(entry)
+			if err != nil {
+				addr, addrErr := netip.ParseAddr(entry)
+				if addrErr != nil {
+					return settings, fmt.Errorf("WEBHOOK_ALLOWED_NETWORKS must list networks such as 127.0.0.0/8, got %q", entry)
+				}
+				network = netip.PrefixFrom(addr, addr.BitLen())
+			}
+			settings.AllowedNetworks = append(settings.AllowedNetworks, network.Masked())
+		}
+	}
+
+	return settings, nil
+}
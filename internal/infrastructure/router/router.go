@@ -2,35 +2,146 @@ package router
 
 import (
 	"LeagueManager/internal/infrastructure/config"
+	"LeagueManager/internal/presentation/controllers"
+	"LeagueManager/internal/presentation/openapi"
 	"github.com/gin-gonic/gin"
 )
 
 func Init(init *config.Initialization) *gin.Engine {
 	router := gin.New()
-	router.Use(gin.Logger())
+	router.Use(controllers.RequestLogger())
 	router.Use(gin.Recovery())
 
-	api := router.Group("/api")
+	// Every group requires a viewer at least, routes that change data ask for more
+	authenticate := init.Auth.Authenticate()
+	viewer := controllers.RequireRole(controllers.RoleViewer)
+	manager := controllers.RequireRole(controllers.RoleManager)
+	admin := controllers.RequireRole(controllers.RoleAdmin)
+
+	// The API document and its explorer are public
+	public := router.Group("/api")
+	{
+		public.GET("/openapi.json", openapi.Spec)
+		public.GET("/docs", openapi.Explorer)
+	}
+
+	api := router.Group("/api", authenticate, viewer)
 	{
 		team := api.Group("/teams")
 		team.GET("", init.TeamCtrl.GetAllTeams)
-		team.POST("", init.TeamCtrl.AddTeam)
+		team.POST("", manager, init.TeamCtrl.AddTeam)
 		team.GET("/:teamID", init.TeamCtrl.GetTeamByID)
-		team.PUT("/:teamID", init.TeamCtrl.UpdateTeam)
-		team.DELETE("/:teamID", init.TeamCtrl.DeleteTeam)
+		team.PUT("/:teamID", manager, init.TeamCtrl.UpdateTeam)
+		team.DELETE("/:teamID", admin, init.TeamCtrl.DeleteTeam)
 
 		// Add the league routes
 		league := api.Group("/leagues")
-		league.POST("/create", init.LeagueCtrl.CreateLeague)
-		league.POST("/initialize", init.LeagueCtrl.CreateAndInitializeLeague)
-		league.POST("/start/:leagueID", init.LeagueCtrl.StartLeague)
-		league.POST("/add-team/:leagueID/:teamID", init.LeagueCtrl.AddTeamToLeague)
-		league.POST("/remove-team/:leagueID/:teamID", init.LeagueCtrl.RemoveTeamFromLeague)
-		league.POST("/advance-week/:leagueID", init.LeagueCtrl.AdvanceWeek)
+		league.GET("", init.LeagueCtrl.ListLeagues)
+		league.POST("/create", manager, init.LeagueCtrl.CreateLeague)
+		league.POST("/initialize", manager, init.LeagueCtrl.CreateAndInitializeLeague)
+		league.POST("/start/:leagueID", manager, init.LeagueCtrl.StartLeague)
+		league.POST("/add-team/:leagueID/:teamID", manager, init.LeagueCtrl.AddTeamToLeague)
+		league.POST("/remove-team/:leagueID/:teamID", manager, init.LeagueCtrl.RemoveTeamFromLeague)
+		league.POST("/advance-week/:leagueID", manager, init.LeagueCtrl.AdvanceWeek)
 		league.GET("/view-matches/:leagueID", init.LeagueCtrl.ViewMatchResults)
-		league.POST("/edit-match/:matchID", init.LeagueCtrl.EditMatchResults)
+		league.POST("/edit-match/:matchID", admin, init.LeagueCtrl.EditMatchResults)
 		league.GET("/predict-champion/:leagueID", init.LeagueCtrl.PredictChampion)
-		league.POST("/play-all-matches/:leagueID", init.LeagueCtrl.PlayAllMatches)
+		league.POST("/play-all-matches/:leagueID", manager, init.LeagueCtrl.PlayAllMatches)
+		league.GET("/check-standings/:leagueID", init.LeagueCtrl.CheckStandings)
+		league.POST("/rebuild-standings/:leagueID", admin, init.LeagueCtrl.RebuildStandings)
+		league.GET("/:leagueID/stream", init.StreamCtrl.StreamLeague)
+
+		api.GET("/matches", init.LeagueCtrl.ListMatches)
+		api.GET("/organization", init.OrganizationCtrl.GetOrganization)
+
+		adminGroup := api.Group("/admin", admin)
+		adminGroup.GET("/check-standings", init.LeagueCtrl.CheckAllStandings)
+		adminGroup.POST("/rebuild-standings", init.LeagueCtrl.RebuildAllStandings)
+	}
+
+	// The v2 routes expose the same operations as nested resources and share the v1 handlers
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), authenticate, viewer)
+	{
+		team := v2.Group("/teams")
+		team.GET("", init.TeamCtrl.GetAllTeams)
+		team.POST("", manager, init.TeamCtrl.AddTeam)
+		team.GET("/:teamID", init.TeamCtrl.GetTeamByID)
+		team.PUT("/:teamID", manager, init.TeamCtrl.UpdateTeam)
+		team.DELETE("/:teamID", admin, init.TeamCtrl.DeleteTeam)
+		team.GET("/:teamID/leagues", init.LeagueCtrl.ListTeamLeagues)
+		team.GET("/:teamID/calendar.ics", init.CalendarCtrl.GetTeamICalendar)
+
+		league := v2.Group("/leagues")
+		league.GET("", init.LeagueCtrl.ListLeagues)
+		league.POST("", manager, init.LeagueCtrl.CreateLeague)
+		league.POST("/restore", manager, init.ArchiveCtrl.RestoreArchive)
+		league.GET("/:leagueID", init.LeagueCtrl.GetLeague)
+		league.PATCH("/:leagueID", manager, init.LeagueCtrl.UpdateLeague)
+		league.DELETE("/:leagueID", admin, init.LeagueCtrl.DeleteLeague)
+		league.GET("/:leagueID/teams", init.LeagueCtrl.ListLeagueTeams)
+		league.PUT("/:leagueID/teams/:teamID", manager, init.LeagueCtrl.AddTeamToLeague)
+		league.DELETE("/:leagueID/teams/:teamID", manager, init.LeagueCtrl.RemoveTeamFromLeague)
+		league.GET("/:leagueID/matches", init.LeagueCtrl.ListLeagueMatches)
+		league.GET("/:leagueID/standings", init.LeagueCtrl.GetStandings)
+		league.GET("/:leagueID/standings/check", init.LeagueCtrl.CheckStandings)
+		league.POST("/:leagueID/standings/rebuild", admin, init.LeagueCtrl.RebuildStandings)
+		league.GET("/:leagueID/predictions", init.LeagueCtrl.PredictChampion)
+		league.POST("/:leagueID/start", manager, init.LeagueCtrl.StartLeague)
+		league.POST("/:leagueID/advance", manager, init.LeagueCtrl.AdvanceWeek)
+		league.POST("/:leagueID/week/close", manager, init.LeagueCtrl.CloseWeek)
+		league.PUT("/:leagueID/result-mode", manager, init.LeagueCtrl.SetResultMode)
+		league.POST("/:leagueID/play-all", manager, init.LeagueCtrl.PlayAllMatches)
+		league.GET("/:leagueID/stream", init.StreamCtrl.StreamLeague)
+		league.GET("/:leagueID/live", init.LiveCtrl.GetLiveWeek)
+		league.POST("/:leagueID/live", manager, init.LiveCtrl.StartLiveWeek)
+		league.DELETE("/:leagueID/live", manager, init.LiveCtrl.StopLiveWeek)
+		league.PUT("/:leagueID/calendar", manager, init.CalendarCtrl.SetLeagueCalendar)
+		league.GET("/:leagueID/calendar.ics", init.CalendarCtrl.GetLeagueICalendar)
+		league.GET("/:leagueID/constraints", init.FixtureCtrl.GetConstraints)
+		league.PUT("/:leagueID/constraints", manager, init.FixtureCtrl.SetConstraints)
+		league.GET("/:leagueID/fixtures/report", init.FixtureCtrl.GetFixtureReport)
+		league.GET("/:leagueID/export/standings", init.ExportCtrl.ExportStandings)
+		league.GET("/:leagueID/export/fixtures", init.ExportCtrl.ExportFixtures)
+		league.GET("/:leagueID/export/results", init.ExportCtrl.ExportResults)
+		league.GET("/:leagueID/archive", manager, init.ArchiveCtrl.ExportArchive)
+		league.GET("/:leagueID/schedule", init.ScheduleCtrl.GetSchedule)
+		league.PUT("/:leagueID/schedule", manager, init.ScheduleCtrl.SetSchedule)
+		league.DELETE("/:leagueID/schedule", manager, init.ScheduleCtrl.DeleteSchedule)
+		league.POST("/:leagueID/schedule/pause", manager, init.ScheduleCtrl.PauseSchedule)
+		league.POST("/:leagueID/schedule/resume", manager, init.ScheduleCtrl.ResumeSchedule)
+		league.GET("/:leagueID/audit", manager, init.AuditCtrl.ListLeagueAudit)
+		league.GET("/:leagueID/webhooks", manager, init.WebhookCtrl.ListWebhooks)
+		league.POST("/:leagueID/webhooks", manager, init.WebhookCtrl.CreateWebhook)
+		league.DELETE("/:leagueID/webhooks/:webhookID", manager, init.WebhookCtrl.DeleteWebhook)
+		league.GET("/:leagueID/webhooks/:webhookID/deliveries", manager, init.WebhookCtrl.ListWebhookDeliveries)
+		league.POST("/:leagueID/webhooks/:webhookID/ping", manager, init.WebhookCtrl.PingWebhook)
+
+		venue := v2.Group("/venues")
+		venue.GET("", init.VenueCtrl.GetAllVenues)
+		venue.POST("", manager, init.VenueCtrl.AddVenue)
+		venue.GET("/:venueID", init.VenueCtrl.GetVenueByID)
+		venue.PUT("/:venueID", manager, init.VenueCtrl.UpdateVenue)
+		venue.DELETE("/:venueID", admin, init.VenueCtrl.DeleteVenue)
+
+		match := v2.Group("/matches")
+		match.GET("", init.LeagueCtrl.ListMatches)
+		match.GET("/:matchID", init.LeagueCtrl.GetMatch)
+		match.PUT("/:matchID/result", admin, init.LeagueCtrl.EditMatchResults)
+		match.POST("/:matchID/result", manager, init.LeagueCtrl.SubmitMatchResult)
+		match.PUT("/:matchID/kickoff", manager, init.CalendarCtrl.SetMatchKickoff)
+		match.PUT("/:matchID/venue", manager, init.VenueCtrl.SetMatchVenue)
+		match.POST("/:matchID/postpone", manager, init.CalendarCtrl.PostponeMatch)
+		match.POST("/:matchID/reschedule", manager, init.CalendarCtrl.RescheduleMatch)
+		match.GET("/:matchID/audit", manager, init.AuditCtrl.ListMatchAudit)
+
+		v2.POST("/import", manager, init.ImportCtrl.Import)
+
+		v2.GET("/organization", init.OrganizationCtrl.GetOrganization)
+		v2.POST("/feed-tokens", init.Auth.IssueFeedToken)
+
+		adminGroup := v2.Group("/admin", admin)
+		adminGroup.GET("/standings/check", init.LeagueCtrl.CheckAllStandings)
+		adminGroup.POST("/standings/rebuild", init.LeagueCtrl.RebuildAllStandings)
 	}
 
 	return router
@@ -0,0 +1,136 @@
+package router
+
+import (
+	"LeagueManager/internal/infrastructure/config"
+	"LeagueManager/internal/presentation/controllers"
+	"LeagueManager/internal/presentation/openapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoutesMatchOpenAPIDocument fails when a route is registered without being documented or the
+// other way around. Routes are compared as "METHOD /api/path" with gin parameters written as {param}.
+func TestRoutesMatchOpenAPIDocument(t *testing.T) {
+	app := Init(testInitialization())
+
+	ginParam := regexp.MustCompile(`:(\w+)`)
+	var registered []string
+	for _, route := range app.Routes() {
+		registered = append(registered, route.Method+" "+ginParam.ReplaceAllString(route.Path, "{$1}"))
+	}
+	sort.Strings(registered)
+
+	var document struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openapi.Document(), &document))
+	require.NotEmpty(t, document.Servers)
+
+	var documented []string
+	for path, operations := range document.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+document.Servers[0].URL+path)
+		}
+	}
+	sort.Strings(documented)
+
+	assert.Equal(t, registered, documented, "the router and the OpenAPI document diverge, fix the @Router annotations and run go generate ./internal/presentation/openapi")
+}
+
+func testInitialization() *config.Initialization {
+	return &config.Initialization{
+		TeamCtrl:         &controllers.TeamController{},
+		LeagueCtrl:       &controllers.LeagueController{},
+		OrganizationCtrl: &controllers.OrganizationController{},
+		AuditCtrl:        &controllers.AuditController{},
+		WebhookCtrl:      &controllers.WebhookController{},
+		StreamCtrl:       &controllers.StreamController{},
+		LiveCtrl:         &controllers.LiveController{},
+		ScheduleCtrl:     &controllers.ScheduleController{},
+		CalendarCtrl:     &controllers.CalendarController{},
+		VenueCtrl:        &controllers.VenueController{},
+		FixtureCtrl:      &controllers.FixtureController{},
+		ImportCtrl:       &controllers.ImportController{},
+		ExportCtrl:       &controllers.ExportController{},
+		ArchiveCtrl:      &controllers.ArchiveController{},
+		Auth: controllers.NewAuthenticator([]controllers.APIKey{
+			{Name: "viewer", Role: controllers.RoleViewer, Key: "viewer-key"},
+			{Name: "manager", Role: controllers.RoleManager, Key: "manager-key"},
+		}, nil),
+	}
+}
+
+// TestRoutesRequireRoles checks the role each route asks for. Requests that pass authorization reach
+// handlers without services, so only the rejections are asserted.
+func TestRoutesRequireRoles(t *testing.T) {
+	app := Init(testInitialization())
+
+	adminOnly := map[string]bool{
+		"DELETE /api/teams/:teamID":                        true,
+		"POST /api/leagues/edit-match/:matchID":            true,
+		"POST /api/leagues/rebuild-standings/:leagueID":    true,
+		"GET /api/admin/check-standings":                   true,
+		"POST /api/admin/rebuild-standings":                true,
+		"DELETE /api/v2/teams/:teamID":                     true,
+		"DELETE /api/v2/leagues/:leagueID":                 true,
+		"DELETE /api/v2/venues/:venueID":                   true,
+		"POST /api/v2/leagues/:leagueID/standings/rebuild": true,
+		"PUT /api/v2/matches/:matchID/result":              true,
+		"GET /api/v2/admin/standings/check":                true,
+		"POST /api/v2/admin/standings/rebuild":             true,
+	}
+	// Reads that viewers may not make
+	managerReads := map[string]bool{
+		"GET /api/v2/leagues/:leagueID/audit": true,
+		"GET /api/v2/matches/:matchID/audit":  true,
+
+		"GET /api/v2/leagues/:leagueID/webhooks":                       true,
+		"GET /api/v2/leagues/:leagueID/webhooks/:webhookID/deliveries": true,
+	}
+	// Writes that viewers may make, they change nothing but what the viewer holds
+	viewerWrites := map[string]bool{
+		"POST /api/v2/feed-tokens": true,
+	}
+	public := map[string]bool{
+		"GET /api/openapi.json": true,
+		"GET /api/docs":         true,
+	}
+
+	status := func(method, path, key string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, strings.NewReplacer(":teamID", "1", ":leagueID", "1", ":matchID", "1", ":webhookID", "1").Replace(path), nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, route := range app.Routes() {
+		name := route.Method + " " + route.Path
+		if public[name] {
+			assert.Equal(t, http.StatusOK, status(route.Method, route.Path, ""), name)
+			continue
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, status(route.Method, route.Path, ""), name)
+		assert.Equal(t, http.StatusUnauthorized, status(route.Method, route.Path, "unknown-key"), name)
+		if (route.Method != http.MethodGet && !viewerWrites[name]) || adminOnly[name] || managerReads[name] {
+			assert.Equal(t, http.StatusForbidden, status(route.Method, route.Path, "viewer-key"), name)
+		}
+		if adminOnly[name] {
+			assert.Equal(t, http.StatusForbidden, status(route.Method, route.Path, "manager-key"), name)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package internal
 
 import (
+	"LeagueManager/internal/application/events"
 	"LeagueManager/internal/application/services"
 	"LeagueManager/internal/domain/repositories"
 	"LeagueManager/internal/infrastructure/config"
@@ -13,16 +14,73 @@ import (
 
 func Init() (*config.Initialization, error) {
 	wire.Build(
+		config.NewDatabaseSettings,
 		config.ConnectToDB,
 		repositories.NewTeamRepository,
 		repositories.NewLeagueRepository,
 		repositories.NewStandingRepository,
 		repositories.NewMatchRepository,
+		repositories.NewUnitOfWork,
+		repositories.NewOrganizationRepository,
+		repositories.NewAuditRepository,
+		repositories.NewWebhookRepository,
+		repositories.NewScheduleRepository,
+		repositories.NewVenueRepository,
+		events.NewBus,
+		events.NewBroker,
 		services.NewTeamService,
 		controllers.NewTeamController,
 		services.NewLeagueService,
 		controllers.NewLeagueController,
+		services.NewOrganizationService,
+		controllers.NewOrganizationController,
+		services.NewAuditService,
+		controllers.NewAuditController,
+		config.NewWebhookSettings,
+		services.NewWebhookDispatcher,
+		services.NewWebhookService,
+		controllers.NewWebhookController,
+		controllers.NewStreamController,
+		services.NewLiveMatchService,
+		controllers.NewLiveController,
+		config.NewSchedulerSettings,
+		services.NewLeagueScheduler,
+		services.NewScheduleService,
+		controllers.NewScheduleController,
+		controllers.NewCalendarController,
+		services.NewVenueService,
+		controllers.NewVenueController,
+		controllers.NewFixtureController,
+		services.NewImportService,
+		controllers.NewImportController,
+		controllers.NewExportController,
+		services.NewArchiveService,
+		controllers.NewArchiveController,
+		config.NewAuthenticator,
 		config.NewInitialization,
 	)
 	return &config.Initialization{}, nil
 }
+
+// InitCLI builds the services of the command-line tools, which serve no requests and need no credentials
+func InitCLI() (*config.CLIInitialization, error) {
+	wire.Build(
+		config.NewDatabaseSettings,
+		config.ConnectToDB,
+		repositories.NewTeamRepository,
+		repositories.NewLeagueRepository,
+		repositories.NewStandingRepository,
+		repositories.NewMatchRepository,
+		repositories.NewUnitOfWork,
+		repositories.NewOrganizationRepository,
+		repositories.NewWebhookRepository,
+		events.NewBus,
+		services.NewTeamService,
+		services.NewLeagueService,
+		services.NewOrganizationService,
+		config.NewWebhookSettings,
+		services.NewWebhookDispatcher,
+		config.NewCLIInitialization,
+	)
+	return &config.CLIInitialization{}, nil
+}
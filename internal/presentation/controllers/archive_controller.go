@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxArchiveBytes bounds the size of an archive to restore, which is read whole before it is checked
+const MaxArchiveBytes = 8 << 20
+
+// ArchiveController handles the backup of leagues as portable archives and their restore
+type ArchiveController struct {
+	service services.ArchiveService
+}
+
+// NewArchiveController creates a new ArchiveController
+func NewArchiveController(service services.ArchiveService) *ArchiveController {
+	return &ArchiveController{service: service}
+}
+
+func (ctrl *ArchiveController) archives(c *gin.Context) services.ArchiveService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// ExportArchive archives a league
+// @Summary Archive a league
+// @Description A versioned, self-describing archive of the league with its teams, the venues they play at, its matches, standings and settings, to keep as a backup or restore on another instance.
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} dto.LeagueArchive
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/archive [get]
+func (ctrl *ArchiveController) ExportArchive(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	archive, err := ctrl.archives(c).ExportLeague(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to archive league")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="league-%d-archive.json"`, leagueID))
+	c.JSON(http.StatusOK, archive)
+}
+
+// RestoreArchive restores an archived league
+// @Summary Restore an archived league
+// @Description The league is created anew with new IDs. Teams that exist under the same name, and venues of the same name in the same city, are used as they are instead of being created.
+// @Description The archive is checked as a whole before anything is written and its standings against its results before the restore commits; any error fails the restore with every error found.
+// @Tags League
+// @Accept json
+// @Produce json
+// @Param archive body dto.LeagueArchive true "League archive"
+// @Success 201 {object} dto.RestoreReport "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 413 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/restore [post]
+func (ctrl *ArchiveController) RestoreArchive(c *gin.Context) {
+	// Fields this version does not know are ignored, the version of the archive decides whether it can be read
+	var archive dto.LeagueArchive
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, MaxArchiveBytes)
+	if err := json.NewDecoder(body).Decode(&archive); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondProblem(c, http.StatusRequestEntityTooLarge, "archive_too_large", fmt.Sprintf("an archive is at most %d bytes", MaxArchiveBytes))
+			return
+		}
+		respondError(c, invalidBody(err), "Invalid archive")
+		return
+	}
+
+	report, err := ctrl.archives(c).RestoreLeague(&archive)
+	if err != nil {
+		respondError(c, err, "Failed to restore league")
+		return
+	}
+	respondCreated(c, fmt.Sprintf("/api/v2/leagues/%d", report.League.ID), report, report)
+}
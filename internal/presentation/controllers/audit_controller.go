@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// AuditReasonHeader carries the reason of a change, it is recorded in the audit log
+const AuditReasonHeader = "X-Audit-Reason"
+
+// AuditController handles audit log requests
+type AuditController struct {
+	service services.AuditService
+}
+
+// NewAuditController creates a new AuditController
+func NewAuditController(service services.AuditService) *AuditController {
+	return &AuditController{service: service}
+}
+
+// ListLeagueAudit retrieves one page of the audit log of a league, including the changes to its matches
+// @Summary List the audit log of a league
+// @Description Entries of deleted leagues remain available.
+// @Tags Audit
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param action query string false "Only entries of this action, e.g. match.result_edited"
+// @Param sort query string false "Sort field (id, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500"
+// @Param offset query int false "Number of entries to skip"
+// @Success 200 {object} dto.PageResponse[models.AuditEntry]
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/audit [get]
+func (ctrl *AuditController) ListLeagueAudit(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+	ctrl.listEntries(c, repositories.AuditFilter{LeagueID: uint(leagueID)})
+}
+
+// ListMatchAudit retrieves one page of the audit log of a match, to trace how its result changed
+// @Summary List the audit log of a match
+// @Tags Audit
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Param action query string false "Only entries of this action, e.g. match.result_edited"
+// @Param sort query string false "Sort field (id, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500"
+// @Param offset query int false "Number of entries to skip"
+// @Success 200 {object} dto.PageResponse[models.AuditEntry]
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID}/audit [get]
+func (ctrl *AuditController) ListMatchAudit(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+	ctrl.listEntries(c, repositories.AuditFilter{EntityType: models.AuditEntityMatch, EntityID: uint(matchID)})
+}
+
+func (ctrl *AuditController) listEntries(c *gin.Context, filter repositories.AuditFilter) {
+	params := newQueryParams(c)
+	filter.Action = c.Query("action")
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid audit query")
+		return
+	}
+
+	entries, total, err := ctrl.service.WithScope(scopeOf(c)).FindEntries(filter, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve audit log")
+		return
+	}
+	respondList(c, entries, page, total)
+}
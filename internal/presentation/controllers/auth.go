@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/repositories"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role grants access to a set of routes, every role includes the access of the roles below it
+type Role string
+
+const (
+	RoleViewer  Role = "viewer"  // Reads teams, leagues, matches and standings
+	RoleManager Role = "manager" // Also creates and runs leagues and manages teams
+	RoleAdmin   Role = "admin"   // Also deletes teams and leagues, edits results and repairs standings
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleManager:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (r Role) valid() bool {
+	return r.rank() > 0
+}
+
+// Includes reports whether the role grants the access of other
+func (r Role) Includes(other Role) bool {
+	return r.valid() && r.rank() >= other.rank()
+}
+
+// ParseRole returns the role with the given name
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if !role.valid() {
+		return "", fmt.Errorf("unknown role %q, want one of viewer, manager, admin", name)
+	}
+	return role, nil
+}
+
+// Principal is the authenticated caller of a request, acting on the data of one organization
+type Principal struct {
+	Subject        string
+	Role           Role
+	OrganizationID uint
+}
+
+// APIKey is a static credential sent in the X-API-Key header
+type APIKey struct {
+	Name           string
+	Role           Role
+	OrganizationID uint
+	Key            string
+}
+
+const principalKey = "principal"
+
+// Authenticator identifies the caller of each request from an API key or a bearer token
+type Authenticator struct {
+	keys        map[[sha256.Size]byte]Principal
+	tokenSecret []byte
+	disabled    bool
+	anonymous   Principal
+	now         func() time.Time
+}
+
+// NewAuthenticator accepts the given API keys and, if tokenSecret is not empty, the bearer tokens signed with it
+func NewAuthenticator(keys []APIKey, tokenSecret []byte) *Authenticator {
+	a := &Authenticator{keys: map[[sha256.Size]byte]Principal{}, tokenSecret: tokenSecret, now: time.Now}
+	for _, key := range keys {
+		// Keys are looked up by digest so the comparison does not leak how much of a key matched
+		a.keys[sha256.Sum256([]byte(key.Key))] = Principal{Subject: key.Name, Role: key.Role, OrganizationID: key.OrganizationID}
+	}
+	return a
+}
+
+// DisabledAuthenticator lets every request through as an anonymous admin of the organization. It is only meant
+// for local development.
+func DisabledAuthenticator(organizationID uint) *Authenticator {
+	return &Authenticator{disabled: true, anonymous: Principal{Subject: "anonymous", Role: RoleAdmin, OrganizationID: organizationID}, now: time.Now}
+}
+
+// Authenticate rejects requests without valid credentials with 401 and records the caller of the others
+func (a *Authenticator) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.disabled {
+			c.Set(principalKey, a.anonymous)
+			c.Next()
+			return
+		}
+
+		principal, err := a.principal(c)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="LeagueManager"`)
+			respondProblem(c, http.StatusUnauthorized, "unauthenticated", err.Error())
+			return
+		}
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+func (a *Authenticator) principal(c *gin.Context) (Principal, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		principal, ok := a.keys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return Principal{}, fmt.Errorf("invalid API key")
+		}
+		return principal, nil
+	}
+
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	inURL := false
+	if !found && c.Request.Method == http.MethodGet && c.Query("access_token") != "" {
+		// Calendar apps and EventSource cannot set headers, so feeds may carry a feed token in the URL (RFC 6750)
+		if !feedRoute(c) {
+			return Principal{}, fmt.Errorf("access_token is only accepted by calendar feeds and event streams, send the token in the Authorization header")
+		}
+		scheme, token, found, inURL = "Bearer", c.Query("access_token"), true, true
+	}
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, fmt.Errorf("missing credentials, send an X-API-Key header or a bearer token")
+	}
+	if len(a.tokenSecret) == 0 {
+		return Principal{}, fmt.Errorf("bearer tokens are not accepted")
+	}
+
+	claims, err := verifyToken(a.tokenSecret, strings.TrimSpace(token), a.now())
+	if err != nil {
+		return Principal{}, fmt.Errorf("invalid bearer token: %w", err)
+	}
+	if claims.Scope == TokenScopeFeed {
+		if !feedRoute(c) {
+			return Principal{}, fmt.Errorf("feed tokens only open calendar feeds and event streams")
+		}
+		return Principal{Subject: claims.Subject, Role: RoleViewer, OrganizationID: claims.OrganizationID}, nil
+	}
+	if inURL {
+		return Principal{}, fmt.Errorf("only feed tokens are accepted in access_token, issue one with POST /api/v2/feed-tokens")
+	}
+	return Principal{Subject: claims.Subject, Role: claims.Role, OrganizationID: claims.OrganizationID}, nil
+}
+
+// feedRoute reports whether the request is for a calendar feed or an event stream, the routes that accept feed tokens
+func feedRoute(c *gin.Context) bool {
+	path := c.FullPath()
+	return strings.HasSuffix(path, "/calendar.ics") || strings.HasSuffix(path, "/stream")
+}
+
+// defaultFeedTokenDays is how long a feed token lasts unless the caller chooses
+const defaultFeedTokenDays = 90
+
+// IssueFeedToken issues a feed token for the caller
+// @Summary Issue a feed token
+// @Description A read-only token for the calendar feeds and event streams of the caller's organization, to put in their access_token query parameter where apps cannot send headers. It opens no other route and grants no more than the viewer role, whatever the caller's role. Requires AUTH_TOKEN_SECRET.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.FeedTokenRequest false "Lifetime of the token"
+// @Success 201 {object} dto.FeedToken "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 501 {object} controllers.Problem
+// @Router /v2/feed-tokens [post]
+func (a *Authenticator) IssueFeedToken(c *gin.Context) {
+	var request dto.FeedTokenRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &request); err != nil {
+			respondError(c, err, "Invalid feed token request")
+			return
+		}
+	}
+	if len(a.tokenSecret) == 0 {
+		respondProblem(c, http.StatusNotImplemented, "feed_tokens_unavailable", "feed tokens need AUTH_TOKEN_SECRET to be configured")
+		return
+	}
+
+	days := defaultFeedTokenDays
+	if request.ExpiresInDays != nil {
+		days = *request.ExpiresInDays
+	}
+	principal, _ := PrincipalOf(c)
+	now := a.now().UTC()
+	expiresAt := now.Add(time.Duration(days) * 24 * time.Hour).Truncate(time.Second)
+	token, err := SignToken(a.tokenSecret, TokenClaims{
+		Subject:        principal.Subject,
+		Role:           RoleViewer,
+		OrganizationID: principal.OrganizationID,
+		Scope:          TokenScopeFeed,
+		IssuedAt:       now.Unix(),
+		ExpiresAt:      expiresAt.Unix(),
+	})
+	if err != nil {
+		respondError(c, err, "Failed to issue feed token")
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FeedToken{Token: token, ExpiresAt: expiresAt})
+}
+
+// RequireRole rejects requests whose caller lacks the role with 403. It must run after Authenticate.
+func RequireRole(role Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalOf(c)
+		if !ok {
+			respondProblem(c, http.StatusUnauthorized, "unauthenticated", "missing credentials")
+			return
+		}
+		if !principal.Role.Includes(role) {
+			respondProblem(c, http.StatusForbidden, "forbidden", fmt.Sprintf("this operation requires the %s role", role))
+			return
+		}
+		c.Next()
+	}
+}
+
+// scopeOf restricts the services to the organization of the request's caller and records the caller,
+// and the reason given in the X-Audit-Reason header, with every change
+func scopeOf(c *gin.Context) repositories.Scope {
+	principal, _ := PrincipalOf(c)
+	return repositories.Scope{
+		OrganizationID: principal.OrganizationID,
+		Actor:          principal.Subject,
+		Reason:         strings.TrimSpace(c.GetHeader(AuditReasonHeader)),
+	}
+}
+
+// PrincipalOf returns the authenticated caller of the request
+func PrincipalOf(c *gin.Context) (Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	principal, ok := value.(Principal)
+	return principal, ok
+}
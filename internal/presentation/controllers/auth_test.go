@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	dto "LeagueManager/internal/domain/dtos"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func setupAuthRouter(auth *Authenticator) *gin.Engine {
+	r := gin.New()
+	api := r.Group("/api", auth.Authenticate(), RequireRole(RoleViewer))
+	api.GET("/whoami", func(c *gin.Context) {
+		principal, _ := PrincipalOf(c)
+		c.JSON(http.StatusOK, gin.H{"subject": principal.Subject, "role": principal.Role, "organization_id": principal.OrganizationID})
+	})
+	api.DELETE("/things", RequireRole(RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	api.GET("/things/:id/calendar.ics", func(c *gin.Context) {
+		principal, _ := PrincipalOf(c)
+		c.String(http.StatusOK, string(principal.Role))
+	})
+	api.POST("/feed-tokens", auth.IssueFeedToken)
+	return r
+}
+
+func request(router *gin.Engine, method, path string, headers ...string) (*httptest.ResponseRecorder, Problem) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	return w, problem
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	router := setupAuthRouter(NewAuthenticator([]APIKey{
+		{Name: "scoreboard", Role: RoleViewer, OrganizationID: 7, Key: "viewer-key"},
+		{Name: "ops", Role: RoleAdmin, OrganizationID: 7, Key: "admin-key"},
+	}, nil))
+
+	w, problem := request(router, "GET", "/api/whoami")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "unauthenticated", problem.Code)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	w, _ = request(router, "GET", "/api/whoami", "X-API-Key", "wrong-key")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w, _ = request(router, "GET", "/api/whoami", "X-API-Key", "viewer-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject": "scoreboard", "role": "viewer", "organization_id": 7}`, w.Body.String())
+
+	// Roles below the required one are forbidden, roles above it are allowed
+	w, problem = request(router, "DELETE", "/api/things", "X-API-Key", "viewer-key")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "forbidden", problem.Code)
+
+	w, _ = request(router, "DELETE", "/api/things", "X-API-Key", "admin-key")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// Bearer tokens are rejected when no secret is configured
+	token, err := SignToken(testSecret, TokenClaims{Subject: "alice", Role: RoleAdmin, OrganizationID: 7, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	assert.NoError(t, err)
+	w, _ = request(router, "GET", "/api/whoami", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestBearerTokenAuthentication(t *testing.T) {
+	auth := NewAuthenticator(nil, testSecret)
+	router := setupAuthRouter(auth)
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	token, err := SignToken(testSecret, TokenClaims{Subject: "alice", Role: RoleManager, OrganizationID: 3, ExpiresAt: expiry})
+	assert.NoError(t, err)
+
+	w, _ := request(router, "GET", "/api/whoami", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject": "alice", "role": "manager", "organization_id": 3}`, w.Body.String())
+
+	w, _ = request(router, "DELETE", "/api/things", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Tokens that carry the caller's role stay out of URLs, even for feeds
+	w, _ = request(router, "GET", "/api/whoami?access_token="+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = request(router, "GET", "/api/things/1/calendar.ics?access_token="+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = request(router, "DELETE", "/api/things?access_token="+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Tokens signed with another secret are rejected
+	forged, _ := SignToken([]byte("another secret of thirty-two bytes"), TokenClaims{Subject: "alice", Role: RoleAdmin, OrganizationID: 3, ExpiresAt: expiry})
+	w, _ = request(router, "GET", "/api/whoami", "Authorization", "Bearer "+forged)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Changing the claims breaks the signature
+	parts := strings.Split(token, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice","role":"admin","org":3,"exp":` + jsonNumber(expiry) + `}`))
+	w, _ = request(router, "GET", "/api/whoami", "Authorization", "Bearer "+strings.Join(parts, "."))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Unsigned tokens are rejected
+	parts[0] = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	w, _ = request(router, "GET", "/api/whoami", "Authorization", "Bearer "+parts[0]+"."+parts[1]+".")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Expired tokens are rejected
+	auth.now = func() time.Time { return time.Unix(expiry, 0) }
+	w, problem := request(router, "GET", "/api/whoami", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, problem.Detail, "expired")
+}
+
+func TestFeedTokens(t *testing.T) {
+	auth := NewAuthenticator([]APIKey{{Name: "ops", Role: RoleAdmin, OrganizationID: 3, Key: "admin-key"}}, testSecret)
+	router := setupAuthRouter(auth)
+
+	w, _ := request(router, "POST", "/api/feed-tokens", "X-API-Key", "admin-key")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var issued dto.FeedToken
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	assert.WithinDuration(t, time.Now().Add(defaultFeedTokenDays*24*time.Hour), issued.ExpiresAt, time.Minute)
+
+	// A feed token reads the feeds as a viewer, whatever the role of the caller who issued it
+	w, _ = request(router, "GET", "/api/things/1/calendar.ics?access_token="+issued.Token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "viewer", w.Body.String())
+	w, _ = request(router, "GET", "/api/things/1/calendar.ics", "Authorization", "Bearer "+issued.Token)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// and opens nothing else
+	w, _ = request(router, "GET", "/api/whoami?access_token="+issued.Token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = request(router, "GET", "/api/whoami", "Authorization", "Bearer "+issued.Token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = request(router, "POST", "/api/feed-tokens", "Authorization", "Bearer "+issued.Token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Without a token secret there is nothing to sign feed tokens with
+	w, problem := request(setupAuthRouter(NewAuthenticator([]APIKey{{Name: "ops", Role: RoleAdmin, OrganizationID: 3, Key: "admin-key"}}, nil)), "POST", "/api/feed-tokens", "X-API-Key", "admin-key")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Equal(t, "feed_tokens_unavailable", problem.Code)
+}
+
+func TestRedactQuery(t *testing.T) {
+	assert.Equal(t, "/api/v2/leagues/1/stream", redactQuery("/api/v2/leagues/1/stream"))
+	assert.Equal(t, "/api/v2/leagues/1/calendar.ics?access_token=REDACTED&lang=en", redactQuery("/api/v2/leagues/1/calendar.ics?access_token=eyJ.abc.def&lang=en"))
+	assert.Equal(t, "/x?a=1&access_token=REDACTED", redactQuery("/x?a=1&access_token=secret"))
+}
+
+func TestDisabledAuthenticator(t *testing.T) {
+	router := setupAuthRouter(DisabledAuthenticator(1))
+
+	w, _ := request(router, "DELETE", "/api/things")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w, _ = request(router, "GET", "/api/whoami")
+	assert.JSONEq(t, `{"subject": "anonymous", "role": "admin", "organization_id": 1}`, w.Body.String())
+}
+
+func TestSignTokenRequiresClaims(t *testing.T) {
+	_, err := SignToken(testSecret, TokenClaims{Subject: "alice", Role: "owner", OrganizationID: 1, ExpiresAt: 1})
+	assert.Error(t, err)
+	_, err = SignToken(testSecret, TokenClaims{Subject: "alice", Role: RoleAdmin, OrganizationID: 1})
+	assert.Error(t, err)
+	_, err = SignToken(testSecret, TokenClaims{Subject: "alice", Role: RoleAdmin, ExpiresAt: 1})
+	assert.Error(t, err)
+
+	role, err := ParseRole(" Manager ")
+	assert.NoError(t, err)
+	assert.Equal(t, RoleManager, role)
+	_, err = ParseRole("owner")
+	assert.Error(t, err)
+}
+
+func jsonNumber(n int64) string {
+	out, _ := json.Marshal(n)
+	return string(out)
+}
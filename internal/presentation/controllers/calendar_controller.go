@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarController handles the kickoff times and postponements of matches and the calendar feeds of leagues and
+// teams
+type CalendarController struct {
+	service services.LeagueService
+}
+
+// NewCalendarController creates a new CalendarController
+func NewCalendarController(service services.LeagueService) *CalendarController {
+	return &CalendarController{service: service}
+}
+
+func (ctrl *CalendarController) leagues(c *gin.Context) services.LeagueService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// SetLeagueCalendar dates the weeks of a league
+// @Summary Set the calendar of a league
+// @Description Week 1 kicks off on start_date at kickoff_time in timezone, UTC by default, and every further week matchday_interval_days later, 7 by default.
+// @Description The kickoffs of the unplayed matches move along, except those set for a single match. An empty start_date removes the calendar.
+// @Tags League
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param calendar body dto.CalendarRequest true "Calendar of the league"
+// @Success 200 {object} models.League
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/calendar [put]
+func (ctrl *CalendarController) SetLeagueCalendar(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	var request dto.CalendarRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid calendar")
+		return
+	}
+
+	league, err := ctrl.leagues(c).SetCalendar(leagueID, &request, expected)
+	if err != nil {
+		respondError(c, err, "Failed to set calendar")
+		return
+	}
+
+	c.Header("ETag", leagueETag(league))
+	c.JSON(http.StatusOK, league)
+}
+
+// SetMatchKickoff moves the kickoff of a single match
+// @Summary Move the kickoff of a match
+// @Description Only unplayed matches can be moved. A null kickoff_at puts the match back on its league's calendar.
+// @Tags Match
+// @Accept json
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Param kickoff body dto.KickoffRequest true "Kickoff of the match"
+// @Success 200 {object} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID}/kickoff [put]
+func (ctrl *CalendarController) SetMatchKickoff(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+
+	var request dto.KickoffRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid kickoff")
+		return
+	}
+
+	match, err := ctrl.leagues(c).SetMatchKickoff(uint(matchID), request.KickoffAt)
+	if err != nil {
+		respondError(c, err, "Failed to move kickoff")
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// PostponeMatch calls off a single match until it is rescheduled
+// @Summary Postpone a match
+// @Description A postponed match is left unplayed when the league advances past its week, until it is rescheduled.
+// @Tags Match
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Success 200 {object} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID}/postpone [post]
+func (ctrl *CalendarController) PostponeMatch(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+
+	match, err := ctrl.leagues(c).PostponeMatch(uint(matchID))
+	if err != nil {
+		respondError(c, err, "Failed to postpone match")
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// RescheduleMatch moves an unplayed match into another week
+// @Summary Reschedule a match
+// @Description Moves a postponed or scheduled match into a week from the league's current week on, where it is played with the matches of that week.
+// @Description Without a week the match goes into the week of kickoff_at on the league's calendar, without a kickoff_at it kicks off with its new week.
+// @Tags Match
+// @Accept json
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Param reschedule body dto.RescheduleRequest true "New week or kickoff of the match"
+// @Success 200 {object} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID}/reschedule [post]
+func (ctrl *CalendarController) RescheduleMatch(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+
+	var request dto.RescheduleRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid reschedule")
+		return
+	}
+
+	match, err := ctrl.leagues(c).RescheduleMatch(uint(matchID), &request)
+	if err != nil {
+		respondError(c, err, "Failed to reschedule match")
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// GetLeagueICalendar serves the fixtures of a league as an iCalendar feed
+// @Summary Subscribe to the fixtures of a league
+// @Description An iCalendar (RFC 5545) feed with an event for every match that has a kickoff time, showing the score once it is played. Calendar apps that cannot send headers can pass a feed token in the access_token parameter.
+// @Tags League
+// @Produce text/calendar
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} string "iCalendar feed"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/calendar.ics [get]
+func (ctrl *CalendarController) GetLeagueICalendar(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	fixtures, err := ctrl.leagues(c).GetLeagueFixtures(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve fixtures")
+		return
+	}
+
+	respondICalendar(c, fixtures)
+}
+
+// GetTeamICalendar serves the fixtures of a team in all of its leagues as an iCalendar feed
+// @Summary Subscribe to the fixtures of a team
+// @Description An iCalendar (RFC 5545) feed with an event for every match of the team that has a kickoff time, in all of its leagues. Calendar apps that cannot send headers can pass a feed token in the access_token parameter.
+// @Tags Team
+// @Produce text/calendar
+// @Param teamID path int true "Team ID"
+// @Success 200 {object} string "iCalendar feed"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/teams/{teamID}/calendar.ics [get]
+func (ctrl *CalendarController) GetTeamICalendar(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
+		return
+	}
+
+	fixtures, err := ctrl.leagues(c).GetTeamFixtures(uint(teamID))
+	if err != nil {
+		respondError(c, err, "Failed to retrieve fixtures")
+		return
+	}
+
+	respondICalendar(c, fixtures)
+}
+
+func respondICalendar(c *gin.Context, fixtures *dto.Fixtures) {
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(renderICalendar(fixtures, c.Request.Host)))
+}
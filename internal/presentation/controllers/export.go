@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Export formats, chosen with the format parameter or the Accept header
+const (
+	ExportCSV      = "csv"
+	ExportJSON     = "json"
+	ExportMarkdown = "markdown"
+	ExportText     = "text"
+	ExportHTML     = "html"
+)
+
+// exportMediaTypes maps the media types an export can be served as to their format, JSON first as the default
+var exportMediaTypes = []struct {
+	mediaType string
+	format    string
+}{
+	{"application/json", ExportJSON},
+	{"text/csv", ExportCSV},
+	{"text/markdown", ExportMarkdown},
+	{"text/plain", ExportText},
+	{"text/html", ExportHTML},
+}
+
+// exportColumn is a column of an exported table. Key names it in CSV headers and JSON, Header in the tables meant
+// for reading. Numeric columns are aligned to the right.
+type exportColumn struct {
+	Key     string
+	Header  string
+	Numeric bool
+}
+
+// exportTable is what is exported, each row holding a string, an int or nil for every column
+type exportTable struct {
+	Title   string
+	Columns []exportColumn
+	Rows    [][]interface{}
+}
+
+// cell renders a value of the table as text, empty for nil
+func (t *exportTable) cell(row, column int) string {
+	value := t.Rows[row][column]
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// exportRow marshals a row as a JSON object with its keys in column order
+type exportRow struct {
+	columns []exportColumn
+	values  []interface{}
+}
+
+func (r exportRow) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, column := range r.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(column.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// renderExport writes the table in the format
+func renderExport(table *exportTable, format string) ([]byte, error) {
+	switch format {
+	case ExportCSV:
+		return renderExportCSV(table)
+	case ExportJSON:
+		rows := make([]exportRow, len(table.Rows))
+		for i, values := range table.Rows {
+			rows[i] = exportRow{columns: table.Columns, values: values}
+		}
+		body, err := json.MarshalIndent(rows, "", "  ")
+		return append(body, '\n'), err
+	case ExportMarkdown:
+		return renderExportMarkdown(table), nil
+	case ExportText:
+		return renderExportText(table), nil
+	case ExportHTML:
+		return renderExportHTML(table), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+func renderExportCSV(table *exportTable) ([]byte, error) {
+	var b bytes.Buffer
+	writer := csv.NewWriter(&b)
+	record := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		record[i] = column.Key
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+	for row := range table.Rows {
+		for column := range table.Columns {
+			record[column] = table.cell(row, column)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return b.Bytes(), writer.Error()
+}
+
+// markdownEscaper escapes the characters that would end a cell or a line of a Markdown table
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ")
+
+func renderExportMarkdown(table *exportTable) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## %s\n\n", markdownEscaper.Replace(table.Title))
+	writeRow := func(cells func(column int) string) {
+		b.WriteString("|")
+		for column := range table.Columns {
+			b.WriteString(" " + cells(column) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(func(column int) string { return markdownEscaper.Replace(table.Columns[column].Header) })
+	writeRow(func(column int) string {
+		if table.Columns[column].Numeric {
+			return "---:"
+		}
+		return "---"
+	})
+	for row := range table.Rows {
+		writeRow(func(column int) string { return markdownEscaper.Replace(table.cell(row, column)) })
+	}
+	return b.Bytes()
+}
+
+// renderExportText aligns the table in columns of spaces, for monospaced fonts
+func renderExportText(table *exportTable) []byte {
+	widths := make([]int, len(table.Columns))
+	for column, header := range table.Columns {
+		widths[column] = utf8.RuneCountInString(header.Header)
+		for row := range table.Rows {
+			widths[column] = max(widths[column], utf8.RuneCountInString(table.cell(row, column)))
+		}
+	}
+
+	var b bytes.Buffer
+	writeRow := func(cells func(column int) string) {
+		var line strings.Builder
+		for column := range table.Columns {
+			if column > 0 {
+				line.WriteString("  ")
+			}
+			text := strings.ReplaceAll(cells(column), "\n", " ")
+			padding := strings.Repeat(" ", widths[column]-utf8.RuneCountInString(text))
+			if table.Columns[column].Numeric {
+				line.WriteString(padding + text)
+			} else {
+				line.WriteString(text + padding)
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	b.WriteString(table.Title + "\n\n")
+	writeRow(func(column int) string { return table.Columns[column].Header })
+	writeRow(func(column int) string { return strings.Repeat("-", widths[column]) })
+	for row := range table.Rows {
+		writeRow(func(column int) string { return table.cell(row, column) })
+	}
+	return b.Bytes()
+}
+
+// renderExportHTML writes a standalone page holding the table
+func renderExportHTML(table *exportTable) []byte {
+	var b bytes.Buffer
+	title := html.EscapeString(table.Title)
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", title)
+	b.WriteString("<style>\n" +
+		"body { font-family: sans-serif; margin: 2em; }\n" +
+		"table { border-collapse: collapse; }\n" +
+		"th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }\n" +
+		"th { background: #f4f4f4; }\n" +
+		"td.numeric { text-align: right; }\n" +
+		"</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<table>\n<thead>\n<tr>", title)
+	for _, column := range table.Columns {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(column.Header))
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for row := range table.Rows {
+		b.WriteString("<tr>")
+		for column := range table.Columns {
+			if table.Columns[column].Numeric {
+				b.WriteString(`<td class="numeric">`)
+			} else {
+				b.WriteString("<td>")
+			}
+			b.WriteString(html.EscapeString(table.cell(row, column)) + "</td>")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+	return b.Bytes()
+}
@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportController serves the standings, fixtures and results of leagues in formats meant for reports and chats
+type ExportController struct {
+	service services.LeagueService
+}
+
+// NewExportController creates a new ExportController
+func NewExportController(service services.LeagueService) *ExportController {
+	return &ExportController{service: service}
+}
+
+func (ctrl *ExportController) leagues(c *gin.Context) services.LeagueService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// ExportStandings exports the standings of a league
+// @Summary Export the standings of a league
+// @Description The format is taken from the format parameter, else from the Accept header: application/json, text/csv, text/markdown, text/plain (an aligned table) or text/html (a standalone page). JSON is the default.
+// @Tags Export
+// @Produce json,text/csv,text/markdown,text/plain,text/html
+// @Param leagueID path int true "League ID"
+// @Param format query string false "Export format: csv, json, markdown, text or html"
+// @Success 200 {object} string "Standings, ranked"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 406 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/export/standings [get]
+func (ctrl *ExportController) ExportStandings(c *gin.Context) {
+	ctrl.export(c, "standings", func(leagues services.LeagueService, leagueID uint) (*exportTable, error) {
+		fixtures, err := leagues.GetLeagueFixtures(leagueID)
+		if err != nil {
+			return nil, err
+		}
+		standings, err := leagues.GetStandings(leagueID)
+		if err != nil {
+			return nil, err
+		}
+		return standingsTable(fixtures, standings), nil
+	})
+}
+
+// ExportFixtures exports the unplayed matches of a league
+// @Summary Export the fixtures of a league
+// @Description The matches still to play, postponed ones included, by week. The format is chosen as for the standings export.
+// @Tags Export
+// @Produce json,text/csv,text/markdown,text/plain,text/html
+// @Param leagueID path int true "League ID"
+// @Param format query string false "Export format: csv, json, markdown, text or html"
+// @Success 200 {object} string "Fixtures"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 406 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/export/fixtures [get]
+func (ctrl *ExportController) ExportFixtures(c *gin.Context) {
+	ctrl.export(c, "fixtures", func(leagues services.LeagueService, leagueID uint) (*exportTable, error) {
+		fixtures, err := leagues.GetLeagueFixtures(leagueID)
+		if err != nil {
+			return nil, err
+		}
+		return fixturesTable(fixtures), nil
+	})
+}
+
+// ExportResults exports the played matches of a league
+// @Summary Export the results of a league
+// @Description The matches played so far, by week. The format is chosen as for the standings export.
+// @Tags Export
+// @Produce json,text/csv,text/markdown,text/plain,text/html
+// @Param leagueID path int true "League ID"
+// @Param format query string false "Export format: csv, json, markdown, text or html"
+// @Success 200 {object} string "Results"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 406 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/export/results [get]
+func (ctrl *ExportController) ExportResults(c *gin.Context) {
+	ctrl.export(c, "results", func(leagues services.LeagueService, leagueID uint) (*exportTable, error) {
+		fixtures, err := leagues.GetLeagueFixtures(leagueID)
+		if err != nil {
+			return nil, err
+		}
+		return resultsTable(fixtures), nil
+	})
+}
+
+// export negotiates the format, builds the table of the league and writes it
+func (ctrl *ExportController) export(c *gin.Context, name string, build func(services.LeagueService, uint) (*exportTable, error)) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+	format, mediaType, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	table, err := build(ctrl.leagues(c), leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to export "+name)
+		return
+	}
+	body, err := renderExport(table, format)
+	if err != nil {
+		respondError(c, err, "Failed to export "+name)
+		return
+	}
+
+	extension := map[string]string{ExportCSV: "csv", ExportJSON: "json", ExportMarkdown: "md", ExportText: "txt", ExportHTML: "html"}[format]
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="league-%d-%s.%s"`, leagueID, name, extension))
+	c.Data(http.StatusOK, mediaType+"; charset=utf-8", body)
+}
+
+// exportFormat returns the format asked for by the format parameter or, without one, the Accept header, responding
+// with a problem if there is none the export can be served in
+func exportFormat(c *gin.Context) (string, string, bool) {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		for _, offered := range exportMediaTypes {
+			if offered.format == format {
+				return offered.format, offered.mediaType, true
+			}
+		}
+		respondProblem(c, http.StatusBadRequest, "invalid_format", fmt.Sprintf("Unknown format %q, expected csv, json, markdown, text or html", format))
+		return "", "", false
+	}
+
+	offered := make([]string, len(exportMediaTypes))
+	for i, mediaType := range exportMediaTypes {
+		offered[i] = mediaType.mediaType
+	}
+	negotiated := c.NegotiateFormat(offered...)
+	for _, mediaType := range exportMediaTypes {
+		if mediaType.mediaType == negotiated {
+			return mediaType.format, mediaType.mediaType, true
+		}
+	}
+	respondProblem(c, http.StatusNotAcceptable, "not_acceptable", "Exports are served as "+strings.Join(offered, ", "))
+	return "", "", false
+}
+
+func standingsTable(fixtures *dto.Fixtures, standings []*models.Standing) *exportTable {
+	table := &exportTable{
+		Title: fixtures.Name + " standings",
+		Columns: []exportColumn{
+			{Key: "position", Header: "#", Numeric: true},
+			{Key: "team", Header: "Team"},
+			{Key: "played", Header: "P", Numeric: true},
+			{Key: "wins", Header: "W", Numeric: true},
+			{Key: "draws", Header: "D", Numeric: true},
+			{Key: "losses", Header: "L", Numeric: true},
+			{Key: "goal_difference", Header: "GD", Numeric: true},
+			{Key: "points", Header: "Pts", Numeric: true},
+		},
+		Rows: [][]interface{}{},
+	}
+	for i, standing := range standings {
+		table.Rows = append(table.Rows, []interface{}{
+			i + 1, fixtures.TeamNames[standing.TeamID], standing.Played, standing.Wins, standing.Draws, standing.Losses,
+			standing.GoalDifference, standing.Points,
+		})
+	}
+	return table
+}
+
+func fixturesTable(fixtures *dto.Fixtures) *exportTable {
+	table := &exportTable{
+		Title: fixtures.Name + " fixtures",
+		Columns: []exportColumn{
+			{Key: "week", Header: "Week", Numeric: true},
+			{Key: "kickoff_at", Header: "Kickoff"},
+			{Key: "home_team", Header: "Home"},
+			{Key: "away_team", Header: "Away"},
+			{Key: "venue", Header: "Venue"},
+			{Key: "status", Header: "Status"},
+		},
+		Rows: [][]interface{}{},
+	}
+	for _, match := range exportMatches(fixtures, false) {
+		var kickoff interface{}
+		if match.KickoffAt != nil {
+			kickoff = match.KickoffAt.UTC().Format(time.RFC3339)
+		}
+		table.Rows = append(table.Rows, []interface{}{
+			match.Week, kickoff, fixtures.TeamNames[match.HomeTeamID], fixtures.TeamNames[match.AwayTeamID],
+			exportVenue(fixtures, match), match.Status,
+		})
+	}
+	return table
+}
+
+func resultsTable(fixtures *dto.Fixtures) *exportTable {
+	table := &exportTable{
+		Title: fixtures.Name + " results",
+		Columns: []exportColumn{
+			{Key: "week", Header: "Week", Numeric: true},
+			{Key: "home_team", Header: "Home"},
+			{Key: "home_team_score", Header: "Home score", Numeric: true},
+			{Key: "away_team_score", Header: "Away score", Numeric: true},
+			{Key: "away_team", Header: "Away"},
+			{Key: "venue", Header: "Venue"},
+		},
+		Rows: [][]interface{}{},
+	}
+	for _, match := range exportMatches(fixtures, true) {
+		table.Rows = append(table.Rows, []interface{}{
+			match.Week, fixtures.TeamNames[match.HomeTeamID], match.HomeTeamScore, match.AwayTeamScore,
+			fixtures.TeamNames[match.AwayTeamID], exportVenue(fixtures, match),
+		})
+	}
+	return table
+}
+
+// exportMatches returns the played or the unplayed matches of the fixtures, by week
+func exportMatches(fixtures *dto.Fixtures, played bool) []*models.Match {
+	var matches []*models.Match
+	for _, match := range fixtures.Matches {
+		if (match.Status == models.MatchStatusPlayed) == played {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Week != matches[j].Week {
+			return matches[i].Week < matches[j].Week
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// exportVenue names the venue of a match, nil if it has none or it has been deleted
+func exportVenue(fixtures *dto.Fixtures, match *models.Match) interface{} {
+	if match.VenueID == nil {
+		return nil
+	}
+	if venue, ok := fixtures.Venues[*match.VenueID]; ok {
+		return venue.Name
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FixtureController handles the constraints the fixtures of a league are drawn from
+type FixtureController struct {
+	service services.LeagueService
+}
+
+// NewFixtureController creates a new FixtureController
+func NewFixtureController(service services.LeagueService) *FixtureController {
+	return &FixtureController{service: service}
+}
+
+func (ctrl *FixtureController) leagues(c *gin.Context) services.LeagueService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// GetConstraints lists the fixture constraints of a league
+// @Summary List the fixture constraints of a league
+// @Description Teams sharing a ground also have a shared_venue constraint, which is not listed.
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {array} models.FixtureConstraint
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/constraints [get]
+func (ctrl *FixtureController) GetConstraints(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	constraints, err := ctrl.leagues(c).GetFixtureConstraints(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve constraints")
+		return
+	}
+	c.JSON(http.StatusOK, constraints)
+}
+
+// SetConstraints replaces the fixture constraints of a league
+// @Summary Replace the fixture constraints of a league
+// @Description shared_venue: team_id and other_team_id are never both at home in the same week. blackout: team_id does not play at home in week. derby: team_id and other_team_id meet in week.
+// @Description The unplayed fixtures of a started league are drawn again, losing the kickoffs and venues set for single matches. The report lists the constraints the fixtures cannot satisfy.
+// @Tags League
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param constraints body dto.ConstraintsRequest true "Every constraint of the league"
+// @Success 200 {object} dto.FixtureReport
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/constraints [put]
+func (ctrl *FixtureController) SetConstraints(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	var request dto.ConstraintsRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid constraints")
+		return
+	}
+	constraints := make([]models.FixtureConstraint, len(request.Constraints))
+	for i, constraint := range request.Constraints {
+		constraints[i] = models.FixtureConstraint{
+			Kind:        constraint.Kind,
+			TeamID:      constraint.TeamID,
+			OtherTeamID: constraint.OtherTeamID,
+			Week:        constraint.Week,
+		}
+	}
+
+	report, err := ctrl.leagues(c).SetFixtureConstraints(leagueID, constraints, expected)
+	if err != nil {
+		respondError(c, err, "Failed to set constraints")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetFixtureReport tells which constraints the fixtures of a league do not satisfy
+// @Summary Check the fixtures of a league against its constraints
+// @Description Counts the breaks, a team playing at home or away two weeks in a row, and lists the constraints the fixtures do not satisfy. Before the league starts, the report is of the fixtures it would get if it started now.
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} dto.FixtureReport
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/fixtures/report [get]
+func (ctrl *FixtureController) GetFixtureReport(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	report, err := ctrl.leagues(c).GetFixtureReport(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to check fixtures")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
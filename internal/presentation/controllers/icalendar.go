@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// matchDuration is the length of the calendar events of matches, including half time
+const matchDuration = 105 * time.Minute
+
+const icalendarTime = "20060102T150405Z"
+
+// icalendarEscaper escapes the characters RFC 5545 reserves in text values
+var icalendarEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// renderICalendar writes the fixtures with a kickoff time as an RFC 5545 calendar, one event per match. Event UIDs
+// stay the same across requests so calendar apps update the events they already have.
+func renderICalendar(fixtures *dto.Fixtures, host string) string {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeICalendarLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//LeagueManager//Fixtures//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icalendarEscaper.Replace(fixtures.Name))
+	for _, match := range fixtures.Matches {
+		if match.KickoffAt == nil {
+			continue
+		}
+		home, away := fixtures.TeamNames[match.HomeTeamID], fixtures.TeamNames[match.AwayTeamID]
+		summary, status := home+" vs "+away, "CONFIRMED"
+		switch match.Status {
+		case models.MatchStatusPlayed:
+			summary = fmt.Sprintf("%s %d-%d %s", home, match.HomeTeamScore, match.AwayTeamScore, away)
+		case models.MatchStatusPostponed:
+			// Calendar apps keep the event, marked as called off, until the match is rescheduled
+			summary, status = summary+" (postponed)", "CANCELLED"
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", fmt.Sprintf("match-%d@%s", match.ID, host))
+		line("DTSTAMP", match.UpdatedAt.UTC().Format(icalendarTime))
+		line("LAST-MODIFIED", match.UpdatedAt.UTC().Format(icalendarTime))
+		line("DTSTART", match.KickoffAt.UTC().Format(icalendarTime))
+		line("DTEND", match.KickoffAt.Add(matchDuration).UTC().Format(icalendarTime))
+		line("SUMMARY", icalendarEscaper.Replace(summary))
+		line("DESCRIPTION", icalendarEscaper.Replace(fmt.Sprintf("%s, week %d", fixtures.LeagueNames[match.LeagueID], match.Week)))
+		if match.VenueID != nil {
+			if venue, ok := fixtures.Venues[*match.VenueID]; ok {
+				line("LOCATION", icalendarEscaper.Replace(strings.TrimSuffix(venue.Name+", "+venue.City, ", ")))
+				if venue.Latitude != 0 || venue.Longitude != 0 {
+					line("GEO", fmt.Sprintf("%.6f;%.6f", venue.Latitude, venue.Longitude))
+				}
+			}
+		}
+		line("STATUS", status)
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+// writeICalendarLine ends a content line with CRLF, folding it so no line exceeds 75 octets. Lines are only
+// split between UTF-8 characters.
+func writeICalendarLine(b *strings.Builder, content string) {
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space, which counts towards their length
+		limit = 74
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
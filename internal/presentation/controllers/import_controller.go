@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxImportBytes bounds the size of an import, which is read whole before its entries are counted
+const MaxImportBytes = 8 << 20
+
+// ImportController handles bulk imports of teams, leagues and results
+type ImportController struct {
+	service services.ImportService
+}
+
+// NewImportController creates a new ImportController
+func NewImportController(service services.ImportService) *ImportController {
+	return &ImportController{service: service}
+}
+
+// Import creates teams and leagues in bulk
+// @Summary Import teams, leagues and results
+// @Description The body is JSON or, with Content-Type text/csv, lines of team,name,attack,defense[,home venue id] / league,name[,result mode] / member,league,team / result,league,week,home team,away team,home score,away score.
+// @Description Leagues name their teams, imported or existing. A league with results starts with them as its first weeks and continues after the last one.
+// @Description The import is checked as a whole first: any error fails it with every error found, each naming its entry or CSV line, and nothing is imported.
+// @Tags Import
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param import body dto.ImportRequest true "Teams and leagues to import"
+// @Success 201 {object} dto.ImportReport "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 413 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/import [post]
+func (ctrl *ImportController) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportBytes)
+
+	var request *dto.ImportRequest
+	var err error
+	if c.ContentType() == "text/csv" {
+		request, err = parseImportCSV(c.Request.Body)
+	} else {
+		request = &dto.ImportRequest{}
+		err = bindJSON(c, request)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondProblem(c, http.StatusRequestEntityTooLarge, "import_too_large", fmt.Sprintf("an import is at most %d bytes", MaxImportBytes))
+			return
+		}
+		respondError(c, err, "Invalid import")
+		return
+	}
+
+	report, err := ctrl.service.WithScope(scopeOf(c)).Import(request)
+	if err != nil {
+		respondError(c, err, "Failed to import")
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseImportCSV reads an import from CSV. Each line starts with the kind of entry it holds, empty lines and lines
+// starting with # are skipped:
+//
+//	team,<name>,<attack strength>,<defense strength>[,<home venue id>]
+//	league,<name>[,<result mode>]
+//	member,<league>,<team>
+//	result,<league>,<week>,<home team>,<away team>,<home score>,<away score>
+//
+// Leagues are named before their members and results. Every line that cannot be read is reported, named lines[N]
+// after its line number.
+func parseImportCSV(body io.Reader) (*dto.ImportRequest, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	request := &dto.ImportRequest{}
+	leagues := map[string]int{}
+	var fields []apperrors.FieldError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseError *csv.ParseError
+			if errors.As(err, &parseError) {
+				fields = append(fields, apperrors.FieldError{Field: fmt.Sprintf("lines[%d]", parseError.Line), Message: parseError.Err.Error()})
+				break
+			}
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("lines[%d]", line)
+		fail := func(message string, args ...any) {
+			fields = append(fields, apperrors.FieldError{Field: source, Message: fmt.Sprintf(message, args...)})
+		}
+		number := func(field, value string) int {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				fail("%s must be a whole number, got %q", field, value)
+			}
+			return n
+		}
+		league := func(name string) (*dto.ImportLeague, bool) {
+			i, ok := leagues[strings.ToLower(strings.TrimSpace(name))]
+			if !ok {
+				fail("league %q is not declared on an earlier line", name)
+				return nil, false
+			}
+			return &request.Leagues[i], true
+		}
+
+		switch kind := strings.ToLower(strings.TrimSpace(record[0])); kind {
+		case "team":
+			if len(record) != 4 && len(record) != 5 {
+				fail("a team line has 4 or 5 fields, got %d", len(record))
+				continue
+			}
+			attack, defense := number("attack strength", record[2]), number("defense strength", record[3])
+			team := dto.ImportTeam{Name: record[1], AttackStrength: &attack, DefenseStrength: &defense, Source: source}
+			if len(record) == 5 && strings.TrimSpace(record[4]) != "" {
+				venueID := uint(number("home venue id", record[4]))
+				team.HomeVenueID = &venueID
+			}
+			request.Teams = append(request.Teams, team)
+		case "league":
+			if len(record) != 2 && len(record) != 3 {
+				fail("a league line has 2 or 3 fields, got %d", len(record))
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(record[1]))
+			if _, ok := leagues[key]; ok {
+				fail("league %q is declared twice", record[1])
+				continue
+			}
+			imported := dto.ImportLeague{Name: record[1], Source: source}
+			if len(record) == 3 {
+				imported.ResultMode = strings.TrimSpace(record[2])
+			}
+			leagues[key] = len(request.Leagues)
+			request.Leagues = append(request.Leagues, imported)
+		case "member":
+			if len(record) != 3 {
+				fail("a member line has 3 fields, got %d", len(record))
+				continue
+			}
+			if imported, ok := league(record[1]); ok {
+				imported.Teams = append(imported.Teams, record[2])
+				imported.TeamSources = append(imported.TeamSources, source)
+			}
+		case "result":
+			if len(record) != 7 {
+				fail("a result line has 7 fields, got %d", len(record))
+				continue
+			}
+			result := dto.ImportResult{
+				Week:          number("week", record[2]),
+				HomeTeam:      record[3],
+				AwayTeam:      record[4],
+				HomeTeamScore: number("home score", record[5]),
+				AwayTeamScore: number("away score", record[6]),
+				Source:        source,
+			}
+			if imported, ok := league(record[1]); ok {
+				imported.Results = append(imported.Results, result)
+			}
+		default:
+			fail("unknown kind %q, expected team, league, member or result", record[0])
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, apperrors.Validation("validation_failed", "the CSV has %d unreadable lines, nothing was imported", len(fields)).WithFields(fields...)
+	}
+	return request, nil
+}
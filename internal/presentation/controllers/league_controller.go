@@ -2,7 +2,13 @@ package controllers
 
 import (
 	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
+	dto "LeagueManager/internal/domain/dtos"
 	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -23,28 +29,245 @@ func NewLeagueController(leagueService services.LeagueService, teamService servi
 	}
 }
 
+// leagues returns the league service restricted to the organization of the caller
+func (lc *LeagueController) leagues(c *gin.Context) services.LeagueService {
+	return lc.leagueService.WithScope(scopeOf(c))
+}
+
+// teams returns the team service restricted to the organization of the caller
+func (lc *LeagueController) teams(c *gin.Context) services.TeamService {
+	return lc.teamService.WithScope(scopeOf(c))
+}
+
+// ListLeagues retrieves one page of the leagues matching the query filters
+// @Summary List leagues
+// @Tags League
+// @Produce json
+// @Param name query string false "Only leagues whose name contains this text, ignoring case"
+// @Param status query string false "Only leagues in this state (pending, active, finished)"
+// @Param team_id query int false "Only leagues the team plays in"
+// @Param sort query string false "Sort field (id, name, current_week, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500. v1 lists every league unless limit or offset is given"
+// @Param offset query int false "Number of leagues to skip"
+// @Success 200 {array} models.League
+// @Header 200 {integer} X-Total-Count "Number of matching leagues"
+// @Header 200 {string} Link "Links to the next and previous pages"
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues [get]
+// @Router /v2/leagues [get]
+func (lc *LeagueController) ListLeagues(c *gin.Context) {
+	params := newQueryParams(c)
+	filter := repositories.LeagueFilter{
+		NameContains: c.Query("name"),
+		Status:       c.Query("status"),
+		TeamID:       params.id("team_id"),
+	}
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid league query")
+		return
+	}
+
+	leagues, total, err := lc.leagues(c).FindLeagues(filter, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve leagues")
+		return
+	}
+	respondList(c, leagues, page, total)
+}
+
+// ListMatches retrieves one page of the matches matching the query filters
+// @Summary List matches
+// @Tags Match
+// @Produce json
+// @Param league_id query int false "Only matches of this league"
+// @Param team_id query int false "Only matches the team plays in, home or away"
+// @Param venue_id query int false "Only matches played or to be played at this venue"
+// @Param week_from query int false "First week to include"
+// @Param week_to query int false "Last week to include"
+// @Param status query string false "Only matches in this state (scheduled, postponed, played)"
+// @Param sort query string false "Sort field (id, week, league_id, kickoff_at, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500. v1 lists every match unless limit or offset is given"
+// @Param offset query int false "Number of matches to skip"
+// @Success 200 {array} models.Match
+// @Header 200 {integer} X-Total-Count "Number of matching matches"
+// @Header 200 {string} Link "Links to the next and previous pages"
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /matches [get]
+// @Router /v2/matches [get]
+func (lc *LeagueController) ListMatches(c *gin.Context) {
+	params := newQueryParams(c)
+	filter := repositories.MatchFilter{
+		LeagueID: params.id("league_id"),
+		TeamID:   params.id("team_id"),
+		VenueID:  params.id("venue_id"),
+		WeekFrom: params.int("week_from", 1),
+		WeekTo:   params.int("week_to", 1),
+		Status:   c.Query("status"),
+	}
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid match query")
+		return
+	}
+
+	matches, total, err := lc.leagues(c).FindMatches(filter, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve matches")
+		return
+	}
+	respondList(c, matches, page, total)
+}
+
+// ListLeagueMatches retrieves one page of the matches of a league
+// @Summary List the matches of a league
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param week query int false "Only matches of this week"
+// @Param team_id query int false "Only matches the team plays in, home or away"
+// @Param status query string false "Only matches in this state (scheduled, postponed, played)"
+// @Param sort query string false "Sort field (id, week, league_id, kickoff_at, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500"
+// @Param offset query int false "Number of matches to skip"
+// @Success 200 {object} dto.PageResponse[models.Match]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/matches [get]
+func (lc *LeagueController) ListLeagueMatches(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	params := newQueryParams(c)
+	week := params.int("week", 1)
+	filter := repositories.MatchFilter{
+		LeagueID: uint(leagueID),
+		TeamID:   params.id("team_id"),
+		WeekFrom: week,
+		WeekTo:   week,
+		Status:   c.Query("status"),
+	}
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid match query")
+		return
+	}
+
+	if _, err := lc.leagues(c).GetLeagueByID(uint(leagueID)); err != nil {
+		respondError(c, err, "Failed to retrieve league")
+		return
+	}
+
+	matches, total, err := lc.leagues(c).FindMatches(filter, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve matches")
+		return
+	}
+	respondList(c, matches, page, total)
+}
+
+// ListTeamLeagues retrieves one page of the leagues a team plays in
+// @Summary List the leagues of a team
+// @Tags Team
+// @Produce json
+// @Param teamID path int true "Team ID"
+// @Param status query string false "Only leagues in this state (pending, active, finished)"
+// @Param sort query string false "Sort field (id, name, current_week, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500"
+// @Param offset query int false "Number of leagues to skip"
+// @Success 200 {object} dto.PageResponse[models.League]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/teams/{teamID}/leagues [get]
+func (lc *LeagueController) ListTeamLeagues(c *gin.Context) {
+	teamID, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
+		return
+	}
+
+	params := newQueryParams(c)
+	filter := repositories.LeagueFilter{TeamID: uint(teamID), Status: c.Query("status")}
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid league query")
+		return
+	}
+
+	if _, err := lc.teams(c).GetTeamByID(uint(teamID)); err != nil {
+		respondError(c, err, "Failed to retrieve team")
+		return
+	}
+
+	leagues, total, err := lc.leagues(c).FindLeagues(filter, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve leagues")
+		return
+	}
+	respondList(c, leagues, page, total)
+}
+
+// GetMatch retrieves a match by its ID
+// @Summary Get a match by ID
+// @Tags Match
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Success 200 {object} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID} [get]
+func (lc *LeagueController) GetMatch(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+
+	match, err := lc.leagues(c).GetMatchByID(uint(matchID))
+	if err != nil {
+		respondError(c, err, "Failed to retrieve match")
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
 // CreateLeague creates a league with no teams
 // @Summary Create a league with no teams
 // @Tags League
 // @Accept json
 // @Produce json
+// @Param league body dto.CreateLeagueRequest true "League to create"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
-// @Router api/leagues/create [post]
+// @Success 201 {object} models.League "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/create [post]
+// @Router /v2/leagues [post]
 func (lc *LeagueController) CreateLeague(c *gin.Context) {
-	var league models.League
-	if err := c.ShouldBindJSON(&league); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	var request dto.CreateLeagueRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid league")
 		return
 	}
 
-	err := lc.leagueService.CreateLeague(&league)
+	league := models.League{Name: strings.TrimSpace(request.Name), ResultMode: request.ResultMode}
+	err := lc.leagues(c).CreateLeague(&league)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create league"})
+		respondError(c, err, "Failed to create league")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "League created successfully", "league_id": league.ID})
+	respondCreated(c, fmt.Sprintf("/api/v2/leagues/%d", league.ID), league,
+		gin.H{"message": "League created successfully", "league_id": league.ID})
 }
 
 // CreateAndInitializeLeague creates a league and initializes it with Premier League teams
@@ -53,7 +276,7 @@ func (lc *LeagueController) CreateLeague(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
+// @Failure 500 {object} controllers.Problem
 // @Router /leagues/initialize [post]
 func (lc *LeagueController) CreateAndInitializeLeague(c *gin.Context) {
 	teams := []models.Team{
@@ -63,36 +286,179 @@ func (lc *LeagueController) CreateAndInitializeLeague(c *gin.Context) {
 		{Name: "Manchester City", AttackStrength: 92, DefenseStrength: 88},
 	}
 
+	// Team names are unique, so teams left over from an earlier initialization are reused
+	league := &models.League{Name: "Premier League"}
 	for _, team := range teams {
-		err := lc.teamService.CreateTeam(&team)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create teams"})
+		existing, err := lc.teams(c).GetTeamByName(team.Name)
+		if err == nil {
+			league.Teams = append(league.Teams, *existing)
+			continue
+		}
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			respondError(c, err, "Failed to retrieve teams")
 			return
 		}
+
+		if err := lc.teams(c).CreateTeam(&team); err != nil {
+			respondError(c, err, "Failed to create teams")
+			return
+		}
+		league.Teams = append(league.Teams, team)
 	}
 
-	allTeams, err := lc.teamService.GetAllTeams()
-	if err != nil || len(allTeams) < 4 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve created teams"})
+	if err := lc.leagues(c).CreateLeague(league); err != nil {
+		respondError(c, err, "Failed to create league")
 		return
 	}
 
-	// inside allTeam search for Arsenal, Chelsea, Liverpool, Manchester City and add them to the league
-	league := &models.League{Name: "Premier League"}
-	for _, team := range allTeams {
-		if team.Name == "Arsenal" || team.Name == "Chelsea" || team.Name == "Liverpool" || team.Name == "Manchester City" {
-			league.Teams = append(league.Teams, *team)
-		}
+	c.JSON(http.StatusOK, gin.H{"message": "League created and initialized successfully", "league_id": league.ID})
+}
 
+// GetLeague retrieves a league with its teams
+// @Summary Get a league by ID
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} models.League
+// @Header 200 {string} ETag "League version, to be sent back in If-Match"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID} [get]
+func (lc *LeagueController) GetLeague(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
 	}
 
-	err = lc.leagueService.CreateLeague(league)
+	league, err := lc.leagues(c).GetLeagueByID(uint(leagueID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create league"})
+		respondError(c, err, "Failed to retrieve league")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "League created and initialized successfully", "league_id": league.ID})
+	c.Header("ETag", leagueETag(league))
+	c.JSON(http.StatusOK, league)
+}
+
+// UpdateLeague renames a league
+// @Summary Rename a league
+// @Tags League
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param league body dto.UpdateLeagueRequest true "New league name"
+// @Success 200 {object} models.League
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID} [patch]
+func (lc *LeagueController) UpdateLeague(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	var request dto.UpdateLeagueRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid league")
+		return
+	}
+
+	league, err := lc.leagues(c).RenameLeague(uint(leagueID), request.Name, expected)
+	if err != nil {
+		respondError(c, err, "Failed to update league")
+		return
+	}
+
+	c.Header("ETag", leagueETag(league))
+	c.JSON(http.StatusOK, league)
+}
+
+// DeleteLeague deletes a league
+// @Summary Delete a league by ID
+// @Tags League
+// @Param leagueID path int true "League ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID} [delete]
+func (lc *LeagueController) DeleteLeague(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	if err := lc.leagues(c).DeleteLeague(uint(leagueID)); err != nil {
+		respondError(c, err, "Failed to delete league")
+		return
+	}
+
+	respondDeleted(c, gin.H{"message": "League deleted"})
+}
+
+// ListLeagueTeams retrieves the teams playing in a league
+// @Summary List the teams of a league
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {array} models.Team
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/teams [get]
+func (lc *LeagueController) ListLeagueTeams(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	league, err := lc.leagues(c).GetLeagueByID(uint(leagueID))
+	if err != nil {
+		respondError(c, err, "Failed to retrieve league")
+		return
+	}
+
+	c.JSON(http.StatusOK, league.Teams)
+}
+
+// GetStandings retrieves the league table
+// @Summary Get the standings of a league
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {array} models.Standing
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/standings [get]
+func (lc *LeagueController) GetStandings(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	standings, err := lc.leagues(c).GetStandings(uint(leagueID))
+	if err != nil {
+		respondError(c, err, "Failed to retrieve standings")
+		return
+	}
+
+	c.JSON(http.StatusOK, standings)
 }
 
 // StartLeague starts the league by setting up the initial matches
@@ -101,19 +467,33 @@ func (lc *LeagueController) CreateAndInitializeLeague(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param expected_version query int false "Expected league version"
+// @Param expected_week query int false "Expected current week"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
 // @Router /leagues/start/{leagueID} [post]
+// @Router /v2/leagues/{leagueID}/start [post]
 func (lc *LeagueController) StartLeague(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 
-	err = lc.leagueService.StartLeague(uint(leagueID))
+	expected, err := leaguePrecondition(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start league: " + err.Error()})
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	err = lc.leagues(c).StartLeague(uint(leagueID), expected)
+	if err != nil {
+		respondError(c, err, "Failed to start league")
 		return
 	}
 
@@ -127,24 +507,37 @@ func (lc *LeagueController) StartLeague(c *gin.Context) {
 // @Produce json
 // @Param leagueID path int true "League ID"
 // @Param teamID path int true "Team ID"
+// @Param If-Match header string false "Expected league version"
+// @Param expected_version query int false "Expected league version"
+// @Param expected_week query int false "Expected current week"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
-// @Router api/leagues/add-team/{leagueID}/{teamID} [post]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/add-team/{leagueID}/{teamID} [post]
+// @Router /v2/leagues/{leagueID}/teams/{teamID} [put]
 func (lc *LeagueController) AddTeamToLeague(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 	teamID, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
 		return
 	}
 
-	err = lc.leagueService.AddTeamToLeague(uint(leagueID), uint(teamID))
+	err = lc.leagues(c).AddTeamToLeague(uint(leagueID), uint(teamID), expected)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add team to league: " + err.Error()})
+		respondError(c, err, "Failed to add team to league")
 		return
 	}
 
@@ -158,73 +551,184 @@ func (lc *LeagueController) AddTeamToLeague(c *gin.Context) {
 // @Produce json
 // @Param leagueID path int true "League ID"
 // @Param teamID path int true "Team ID"
+// @Param If-Match header string false "Expected league version"
+// @Param expected_version query int false "Expected league version"
+// @Param expected_week query int false "Expected current week"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
-// @Router api/leagues/remove-team/{leagueID}/{teamID} [post]
+// @Success 204 "Removed"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/remove-team/{leagueID}/{teamID} [post]
+// @Router /v2/leagues/{leagueID}/teams/{teamID} [delete]
 func (lc *LeagueController) RemoveTeamFromLeague(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 	teamID, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
 		return
 	}
 
-	err = lc.leagueService.RemoveTeamFromLeague(uint(leagueID), uint(teamID))
+	err = lc.leagues(c).RemoveTeamFromLeague(uint(leagueID), uint(teamID), expected)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove team from league: " + err.Error()})
+		respondError(c, err, "Failed to remove team from league")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Team removed from league successfully"})
+	respondDeleted(c, gin.H{"message": "Team removed from league successfully"})
 }
 
 // AdvanceWeek advances the league by one week
 // @Summary Advance the league by one week
+// @Description A league with manual results is not simulated, it only advances once every match of the week has a result.
 // @Tags League
 // @Accept json
 // @Produce json
 // @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param expected_version query int false "Expected league version"
+// @Param expected_week query int false "Expected current week"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
-// @Router api/leagues/advance-week/{leagueID} [post]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/advance-week/{leagueID} [post]
+// @Router /v2/leagues/{leagueID}/advance [post]
 func (lc *LeagueController) AdvanceWeek(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 
-	if err := lc.leagueService.AdvanceWeek(uint(leagueID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to advance week: " + err.Error()})
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	if err := lc.leagues(c).AdvanceWeek(uint(leagueID), expected); err != nil {
+		respondError(c, err, "Failed to advance week")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Week advanced successfully"})
 }
 
+// CloseWeek closes the current week of a league with manual results
+// @Summary Close the current week of a league with manual results
+// @Description The league advances even though some matches of the week have no result, those are postponed.
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/week/close [post]
+func (lc *LeagueController) CloseWeek(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	if err := lc.leagues(c).CloseWeek(uint(leagueID), expected); err != nil {
+		respondError(c, err, "Failed to close week")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Week closed successfully"})
+}
+
+// SetResultMode chooses how the results of a league come about
+// @Summary Set the result mode of a league
+// @Description The results of a simulated league are decided when a week is played. Those of a manual league are submitted match by match, and its weeks only advance once every result is in or when closed.
+// @Tags League
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param mode body dto.ResultModeRequest true "Result mode of the league"
+// @Success 200 {object} models.League
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/result-mode [put]
+func (lc *LeagueController) SetResultMode(c *gin.Context) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	expected, err := leaguePrecondition(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	var request dto.ResultModeRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid result mode")
+		return
+	}
+
+	league, err := lc.leagues(c).SetResultMode(uint(leagueID), request.ResultMode, expected)
+	if err != nil {
+		respondError(c, err, "Failed to set result mode")
+		return
+	}
+
+	c.Header("ETag", leagueETag(league))
+	c.JSON(http.StatusOK, league)
+}
+
 // ViewMatchResults returns the match results for the current week
 // @Summary View match results for the current week
 // @Tags League
 // @Accept json
 // @Produce json
 // @Param leagueID path int true "League ID"
-// @Success 200 {object} []models.Match
-// @Failure 500 {object} gin.H
-// @Router api/leagues/view-matches/{leagueID} [get]
+// @Success 200 {array} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/view-matches/{leagueID} [get]
 func (lc *LeagueController) ViewMatchResults(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 
-	matches, err := lc.leagueService.ViewMatchResults(uint(leagueID))
+	matches, err := lc.leagues(c).ViewMatchResults(uint(leagueID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to view match results: " + err.Error()})
+		respondError(c, err, "Failed to view match results")
 		return
 	}
 
@@ -237,51 +741,95 @@ func (lc *LeagueController) ViewMatchResults(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param matchID path int true "Match ID"
-// @Param match body models.Match true "Updated Match"
+// @Param result body dto.MatchResultRequest true "New score of the match"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
-// @Router api/leagues/edit-match/{matchID} [post]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/edit-match/{matchID} [post]
+// @Router /v2/matches/{matchID}/result [put]
 func (lc *LeagueController) EditMatchResults(c *gin.Context) {
 	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
 		return
 	}
 
-	var updatedMatch models.Match
-	if err := c.ShouldBindJSON(&updatedMatch); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	var result dto.MatchResultRequest
+	if err := bindJSON(c, &result); err != nil {
+		respondError(c, err, "Invalid match result")
 		return
 	}
 
-	err = lc.leagueService.EditMatchResults(uint(matchID), &updatedMatch)
+	err = lc.leagues(c).EditMatchResults(uint(matchID), &result)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit match results: " + err.Error()})
+		respondError(c, err, "Failed to edit match results")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Match results edited successfully"})
 }
 
+// SubmitMatchResult enters the result of a match of a league with manual results
+// @Summary Submit the result of a match
+// @Description Only matches of the current week of a league with manual results take a result. The standings move with it, and the week closes with its last result.
+// @Tags Match
+// @Accept json
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Param result body dto.MatchResultRequest true "Score of the match"
+// @Success 200 {object} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID}/result [post]
+func (lc *LeagueController) SubmitMatchResult(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+
+	var result dto.MatchResultRequest
+	if err := bindJSON(c, &result); err != nil {
+		respondError(c, err, "Invalid match result")
+		return
+	}
+
+	match, err := lc.leagues(c).SubmitMatchResult(uint(matchID), &result)
+	if err != nil {
+		respondError(c, err, "Failed to submit match result")
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
 // PredictChampion predicts the champion of the league
 // @Summary Predict the champion of the league
 // @Tags League
 // @Accept json
 // @Produce json
 // @Param leagueID path int true "League ID"
-// @Success 200 {object} []dto.TeamPrediction
-// @Failure 500 {object} gin.H
-// @Router api/leagues/predict-champion/{leagueID} [get]
+// @Success 200 {array} dto.TeamPrediction
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/predict-champion/{leagueID} [get]
+// @Router /v2/leagues/{leagueID}/predictions [get]
 func (lc *LeagueController) PredictChampion(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 
-	predictions, err := lc.leagueService.PredictChampion(uint(leagueID))
+	predictions, err := lc.leagues(c).PredictChampion(uint(leagueID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to predict champion: " + err.Error()})
+		respondError(c, err, "Failed to predict champion")
 		return
 	}
 
@@ -294,21 +842,149 @@ func (lc *LeagueController) PredictChampion(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param leagueID path int true "League ID"
+// @Param If-Match header string false "Expected league version"
+// @Param expected_version query int false "Expected league version"
+// @Param expected_week query int false "Expected current week"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
-// @Router api/leagues/play-all-matches/{leagueID} [post]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/play-all-matches/{leagueID} [post]
+// @Router /v2/leagues/{leagueID}/play-all [post]
 func (lc *LeagueController) PlayAllMatches(c *gin.Context) {
 	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid league ID"})
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
 		return
 	}
 
-	err = lc.leagueService.PlayAllMatches(uint(leagueID))
+	expected, err := leaguePrecondition(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to play all matches: " + err.Error()})
+		respondProblem(c, http.StatusBadRequest, "invalid_precondition", err.Error())
+		return
+	}
+
+	err = lc.leagues(c).PlayAllMatches(uint(leagueID), expected)
+	if err != nil {
+		respondError(c, err, "Failed to play all matches")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "All matches played successfully"})
 }
+
+// CheckStandings compares the stored standings of a league with the standings rebuilt from its matches
+// @Summary Check the standings of a league against its matches
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} dto.StandingsReport
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/check-standings/{leagueID} [get]
+// @Router /v2/leagues/{leagueID}/standings/check [get]
+func (lc *LeagueController) CheckStandings(c *gin.Context) {
+	lc.checkStandings(c, false)
+}
+
+// RebuildStandings rebuilds the standings of a league from its matches and repairs the stored rows
+// @Summary Rebuild the standings of a league from its matches
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} dto.StandingsReport
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/rebuild-standings/{leagueID} [post]
+// @Router /v2/leagues/{leagueID}/standings/rebuild [post]
+func (lc *LeagueController) RebuildStandings(c *gin.Context) {
+	lc.checkStandings(c, true)
+}
+
+// CheckAllStandings runs the standings check for every league
+// @Summary Check the standings of every league against their matches
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} dto.StandingsReport
+// @Failure 500 {object} controllers.Problem
+// @Router /admin/check-standings [get]
+// @Router /v2/admin/standings/check [get]
+func (lc *LeagueController) CheckAllStandings(c *gin.Context) {
+	lc.checkAllStandings(c, false)
+}
+
+// RebuildAllStandings rebuilds and repairs the standings of every league
+// @Summary Rebuild the standings of every league from their matches
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} dto.StandingsReport
+// @Failure 500 {object} controllers.Problem
+// @Router /admin/rebuild-standings [post]
+// @Router /v2/admin/standings/rebuild [post]
+func (lc *LeagueController) RebuildAllStandings(c *gin.Context) {
+	lc.checkAllStandings(c, true)
+}
+
+func (lc *LeagueController) checkStandings(c *gin.Context, repair bool) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return
+	}
+
+	report, err := lc.leagues(c).CheckStandings(uint(leagueID), repair)
+	if err != nil {
+		respondError(c, err, "Failed to check standings")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (lc *LeagueController) checkAllStandings(c *gin.Context, repair bool) {
+	reports, err := lc.leagues(c).CheckAllStandings(repair)
+	if err != nil {
+		respondError(c, err, "Failed to check standings")
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// leagueETag renders the version of a league as a weak entity tag, which If-Match accepts back
+func leagueETag(league *models.League) string {
+	return fmt.Sprintf(`W/"%d"`, league.Version)
+}
+
+// leaguePrecondition reads the league state the client expects from the If-Match header (the league version)
+// or from the expected_version and expected_week query parameters
+func leaguePrecondition(c *gin.Context) (dto.LeaguePrecondition, error) {
+	var expected dto.LeaguePrecondition
+
+	version := c.Query("expected_version")
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		version = strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	}
+	if version != "" {
+		parsed, err := strconv.ParseUint(version, 10, 64)
+		if err != nil {
+			return expected, errors.New("invalid expected league version")
+		}
+		v := uint(parsed)
+		expected.Version = &v
+	}
+
+	if week := c.Query("expected_week"); week != "" {
+		parsed, err := strconv.Atoi(week)
+		if err != nil {
+			return expected, errors.New("invalid expected league week")
+		}
+		expected.Week = &parsed
+	}
+
+	return expected, nil
+}
@@ -1,20 +1,28 @@
 package controllers_test
 
 import (
+	"LeagueManager/internal/application/events"
 	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
 	"LeagueManager/internal/presentation/controllers"
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTest() (*gorm.DB, *gin.Engine) {
@@ -23,15 +31,16 @@ func setupTest() (*gorm.DB, *gin.Engine) {
 		panic("failed to connect to the database")
 	}
 
-	db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{})
+	db.AutoMigrate(&models.Team{}, &models.League{}, &models.Match{}, &models.Standing{}, &models.AuditEntry{}, &models.FixtureConstraint{},
+		&models.AdvancementSchedule{}, &models.WebhookSubscription{})
 
 	teamRepo := repositories.NewTeamRepository(db)
 	leagueRepo := repositories.NewLeagueRepository(db)
 	matchRepo := repositories.NewMatchRepository(db)
 	standingRepo := repositories.NewStandingRepository(db)
 
-	leagueService := services.NewLeagueService(leagueRepo, teamRepo, matchRepo, standingRepo)
-	teamService := services.NewTeamService(teamRepo, leagueRepo)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, matchRepo, standingRepo, repositories.NewUnitOfWork(db), events.NewBus())
+	teamService := services.NewTeamService(teamRepo, leagueRepo, repositories.NewUnitOfWork(db))
 
 	leagueController := controllers.NewLeagueController(leagueService, teamService)
 	teamController := controllers.NewTeamController(teamService)
@@ -48,6 +57,7 @@ func setupTest() (*gorm.DB, *gin.Engine) {
 		team.DELETE("/:teamID", teamController.DeleteTeam)
 
 		league := api.Group("/leagues")
+		league.GET("", leagueController.ListLeagues)
 		league.POST("/create", leagueController.CreateLeague)
 		league.POST("/initialize", leagueController.CreateAndInitializeLeague)
 		league.POST("/add-team/:leagueID/:teamID", leagueController.AddTeamToLeague)
@@ -58,6 +68,41 @@ func setupTest() (*gorm.DB, *gin.Engine) {
 		league.GET("/predict-champion/:leagueID", leagueController.PredictChampion)
 		league.POST("/play-all-matches/:leagueID", leagueController.PlayAllMatches)
 		league.POST("/start/:leagueID", leagueController.StartLeague)
+		league.GET("/check-standings/:leagueID", leagueController.CheckStandings)
+		league.POST("/rebuild-standings/:leagueID", leagueController.RebuildStandings)
+
+		api.GET("/matches", leagueController.ListMatches)
+
+		admin := api.Group("/admin")
+		admin.GET("/check-standings", leagueController.CheckAllStandings)
+		admin.POST("/rebuild-standings", leagueController.RebuildAllStandings)
+	}
+
+	v2 := router.Group("/api/v2", controllers.APIVersion(2))
+	{
+		team := v2.Group("/teams")
+		team.GET("", teamController.GetAllTeams)
+		team.POST("", teamController.AddTeam)
+		team.DELETE("/:teamID", teamController.DeleteTeam)
+		team.GET("/:teamID/leagues", leagueController.ListTeamLeagues)
+
+		league := v2.Group("/leagues")
+		league.GET("", leagueController.ListLeagues)
+		league.POST("", leagueController.CreateLeague)
+		league.GET("/:leagueID", leagueController.GetLeague)
+		league.PATCH("/:leagueID", leagueController.UpdateLeague)
+		league.DELETE("/:leagueID", leagueController.DeleteLeague)
+		league.GET("/:leagueID/teams", leagueController.ListLeagueTeams)
+		league.PUT("/:leagueID/teams/:teamID", leagueController.AddTeamToLeague)
+		league.DELETE("/:leagueID/teams/:teamID", leagueController.RemoveTeamFromLeague)
+		league.GET("/:leagueID/matches", leagueController.ListLeagueMatches)
+		league.GET("/:leagueID/standings", leagueController.GetStandings)
+		league.POST("/:leagueID/start", leagueController.StartLeague)
+		league.POST("/:leagueID/advance", leagueController.AdvanceWeek)
+
+		match := v2.Group("/matches")
+		match.GET("/:matchID", leagueController.GetMatch)
+		match.PUT("/:matchID/result", leagueController.EditMatchResults)
 	}
 
 	return db, router
@@ -319,3 +364,1523 @@ func TestStartLeague(t *testing.T) {
 
 	assert.Equal(t, "League started successfully", response2["message"])
 }
+
+func TestCheckStandings(t *testing.T) {
+	db, router := setupTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/leagues/initialize", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var created map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	leagueID := strconv.Itoa(int(created["league_id"].(float64)))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/start/"+leagueID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/advance-week/"+leagueID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Tamper with the standings directly in the database
+	err = db.Model(&models.Standing{}).Where("league_id = ?", leagueID).Update("points", 100).Error
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/leagues/check-standings/"+leagueID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report dto.StandingsReport
+	err = json.Unmarshal(w.Body.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent)
+	assert.Equal(t, 4, len(report.Discrepancies))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/admin/rebuild-standings", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/admin/check-standings", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var reports []dto.StandingsReport
+	err = json.Unmarshal(w.Body.Bytes(), &reports)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.True(t, reports[0].Consistent)
+}
+
+func TestAdvanceWeekConflict(t *testing.T) {
+	_, router := setupTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/leagues/initialize", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var created map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	leagueID := strconv.Itoa(int(created["league_id"].(float64)))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/start/"+leagueID+"?expected_week=0", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/advance-week/"+leagueID+"?expected_week=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A second client that also saw week 1 must not play it again
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/advance-week/"+leagueID+"?expected_week=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/advance-week/"+leagueID, nil)
+	req.Header.Set("If-Match", `"1"`)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/advance-week/"+leagueID+"?expected_version=abc", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestErrorResponses(t *testing.T) {
+	db, router := setupTest()
+
+	problemOf := func(w *httptest.ResponseRecorder) controllers.Problem {
+		assert.Equal(t, controllers.ProblemContentType, w.Header().Get("Content-Type"))
+		var problem controllers.Problem
+		err := json.Unmarshal(w.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		assert.Equal(t, w.Code, problem.Status)
+		return problem
+	}
+
+	// Unknown league
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/leagues/advance-week/999", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "league_not_found", problemOf(w).Code)
+
+	// Malformed ID
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/advance-week/abc", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_league_id", problemOf(w).Code)
+
+	// League without teams cannot be started
+	leagueID := strconv.Itoa(int(createLeague(t, router)))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/start/"+leagueID, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "league_team_count", problemOf(w).Code)
+
+	// Starting an active league is a conflict
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/initialize", nil)
+	router.ServeHTTP(w, req)
+	var created map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	initializedID := strconv.Itoa(int(created["league_id"].(float64)))
+
+	for _, expected := range []int{http.StatusOK, http.StatusConflict} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/leagues/start/"+initializedID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code)
+	}
+	assert.Equal(t, "league_already_active", problemOf(w).Code)
+
+	// Unknown team
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/teams/999", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "team_not_found", problemOf(w).Code)
+
+	// A database outage is an internal error, not a missing team
+	sqlDB, _ := db.DB()
+	assert.NoError(t, sqlDB.Close())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/teams/1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	problem := problemOf(w)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, problem.Detail, "database is closed")
+}
+
+func TestEditMatchResultsValidation(t *testing.T) {
+	db, router := setupTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/leagues/initialize", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var created map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	leagueID := strconv.Itoa(int(created["league_id"].(float64)))
+
+	for _, path := range []string{"/api/leagues/start/", "/api/leagues/advance-week/"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", path+leagueID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	var match models.Match
+	assert.NoError(t, db.First(&match).Error)
+	matchPath := "/api/leagues/edit-match/" + strconv.Itoa(int(match.ID))
+
+	editMatch := func(body string) (*httptest.ResponseRecorder, controllers.Problem) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", matchPath, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		var problem controllers.Problem
+		json.Unmarshal(w.Body.Bytes(), &problem)
+		return w, problem
+	}
+
+	w, problem := editMatch(`{"home_team_score": -1, "away_team_score": 2}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "home_team_score", problem.Errors[0].Field)
+
+	// Fields of the match that cannot be edited are rejected
+	w, problem = editMatch(`{"home_team_score": 1, "away_team_score": 2, "week": 5}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "week", problem.Errors[0].Field)
+
+	w, _ = editMatch(`{"home_team_score": 1, "away_team_score": 2}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A second initialization reuses the existing teams
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/leagues/initialize", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestListLeaguesAndMatches(t *testing.T) {
+	_, router := setupTest()
+
+	createLeague(t, router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/leagues/initialize", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	leagueID := strconv.Itoa(int(response["league_id"].(float64)))
+
+	for _, path := range []string{"/api/leagues/start/", "/api/leagues/advance-week/", "/api/leagues/advance-week/"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", path+leagueID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// Only the started league is active
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/leagues?status=active", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+
+	var leagues []models.League
+	json.Unmarshal(w.Body.Bytes(), &leagues)
+	assert.Len(t, leagues, 1)
+	assert.Len(t, leagues[0].Teams, 4)
+
+	// Every league is listed without filters
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/leagues", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+
+	// Matches of one team, one per played week
+	teamID := strconv.Itoa(int(leagues[0].Teams[0].ID))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/matches?league_id="+leagueID+"&team_id="+teamID+"&status=played", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var matches []models.Match
+	json.Unmarshal(w.Body.Bytes(), &matches)
+	assert.NotEmpty(t, matches)
+	assert.Equal(t, strconv.Itoa(len(matches)), w.Header().Get("X-Total-Count"))
+	for i, match := range matches {
+		assert.Equal(t, i+1, match.Week)
+		assert.Equal(t, models.MatchStatusPlayed, match.Status)
+	}
+
+	// A page of one match links to the next one
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/matches?league_id="+leagueID+"&limit=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Contains(t, w.Header().Get("Link"), `rel="next"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/leagues?status=paused", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestV2API(t *testing.T) {
+	_, router := setupTest()
+
+	send := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Creating a league answers 201 with the league and its location
+	w := send("POST", "/api/v2/leagues", `{"name":"Test League"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var league models.League
+	json.Unmarshal(w.Body.Bytes(), &league)
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID))
+	assert.Equal(t, leaguePath, w.Header().Get("Location"))
+
+	var teamIDs []string
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		w = send("POST", "/api/v2/teams", `{"name":"`+name+`","attack_strength":70,"defense_strength":70}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var team models.Team
+		json.Unmarshal(w.Body.Bytes(), &team)
+		teamIDs = append(teamIDs, strconv.Itoa(int(team.ID)))
+
+		// Adding a team is idempotent
+		for i := 0; i < 2; i++ {
+			w = send("PUT", leaguePath+"/teams/"+teamIDs[len(teamIDs)-1], "")
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+	}
+
+	w = send("GET", leaguePath+"/teams", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var teams []models.Team
+	json.Unmarshal(w.Body.Bytes(), &teams)
+	assert.Len(t, teams, 4)
+
+	// The ETag of a league guards renames
+	w = send("GET", leaguePath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w = send("PATCH", leaguePath, `{"name":"Renamed League"}`, "If-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &league)
+	assert.Equal(t, "Renamed League", league.Name)
+
+	w = send("PATCH", leaguePath, `{"name":"Stale League"}`, "If-Match", etag)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Play two weeks and read them back through the nested resources
+	assert.Equal(t, http.StatusOK, send("POST", leaguePath+"/start", "").Code)
+	assert.Equal(t, http.StatusOK, send("POST", leaguePath+"/advance", "").Code)
+	assert.Equal(t, http.StatusOK, send("POST", leaguePath+"/advance", "").Code)
+
+	w = send("GET", leaguePath+"/matches?week=1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page dto.PageResponse[models.Match]
+	json.Unmarshal(w.Body.Bytes(), &page)
+	assert.EqualValues(t, 2, page.Total)
+	assert.Len(t, page.Items, 2)
+	assert.Empty(t, page.Next)
+	for _, match := range page.Items {
+		assert.Equal(t, 1, match.Week)
+	}
+
+	w = send("GET", leaguePath+"/matches?limit=1", "")
+	json.Unmarshal(w.Body.Bytes(), &page)
+	assert.Equal(t, leaguePath+"/matches?limit=1&offset=1", page.Next)
+
+	matchPath := "/api/v2/matches/" + strconv.Itoa(int(page.Items[0].ID))
+	w = send("PUT", matchPath+"/result", `{"home_team_score":3,"away_team_score":0}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send("GET", matchPath, "")
+	var match models.Match
+	json.Unmarshal(w.Body.Bytes(), &match)
+	assert.Equal(t, 3, match.HomeTeamScore)
+
+	// Standings come ranked
+	w = send("GET", leaguePath+"/standings", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var standings []models.Standing
+	json.Unmarshal(w.Body.Bytes(), &standings)
+	assert.Len(t, standings, 4)
+	for i := 1; i < len(standings); i++ {
+		assert.GreaterOrEqual(t, standings[i-1].Points, standings[i].Points)
+	}
+
+	w = send("GET", "/api/v2/teams/"+teamIDs[0]+"/leagues", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var leagues dto.PageResponse[models.League]
+	json.Unmarshal(w.Body.Bytes(), &leagues)
+	assert.EqualValues(t, 1, leagues.Total)
+
+	// Deleting answers 204 and the league is gone
+	w = send("DELETE", leaguePath, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, send("GET", leaguePath, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", leaguePath+"/standings", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v2/teams/999/leagues", "").Code)
+}
+
+func TestOrganizationIsolation(t *testing.T) {
+	db, _ := setupTest()
+	db.AutoMigrate(&models.Organization{})
+
+	organizations := services.NewOrganizationService(repositories.NewOrganizationRepository(db))
+	red, _ := organizations.EnsureOrganization("red")
+	blue, _ := organizations.EnsureOrganization("blue")
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, repositories.NewUnitOfWork(db))
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), repositories.NewUnitOfWork(db), events.NewBus())
+	teamController := controllers.NewTeamController(teamService)
+	leagueController := controllers.NewLeagueController(leagueService, teamService)
+	organizationController := controllers.NewOrganizationController(organizations)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "red-admin", Role: controllers.RoleAdmin, OrganizationID: red.ID, Key: "red-key"},
+		{Name: "blue-admin", Role: controllers.RoleAdmin, OrganizationID: blue.ID, Key: "blue-key"},
+	}, nil)
+
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/organization", organizationController.GetOrganization)
+	v2.GET("/teams", teamController.GetAllTeams)
+	v2.POST("/teams", teamController.AddTeam)
+	v2.GET("/teams/:teamID", teamController.GetTeamByID)
+	v2.POST("/leagues", leagueController.CreateLeague)
+	v2.GET("/leagues/:leagueID", leagueController.GetLeague)
+	v2.PUT("/leagues/:leagueID/teams/:teamID", leagueController.AddTeamToLeague)
+
+	send := func(key, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("red-key", "GET", "/api/v2/organization", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var organization models.Organization
+	json.Unmarshal(w.Body.Bytes(), &organization)
+	assert.Equal(t, "red", organization.Name)
+
+	w = send("red-key", "POST", "/api/v2/teams", `{"name":"Arsenal","attack_strength":80,"defense_strength":70}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var redTeam models.Team
+	json.Unmarshal(w.Body.Bytes(), &redTeam)
+	assert.Equal(t, red.ID, redTeam.OrganizationID)
+
+	// Both organizations can use the same team name
+	w = send("blue-key", "POST", "/api/v2/teams", `{"name":"Arsenal","attack_strength":60,"defense_strength":60}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = send("blue-key", "POST", "/api/v2/leagues", `{"name":"Blue League"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var blueLeague models.League
+	json.Unmarshal(w.Body.Bytes(), &blueLeague)
+
+	// Blue sees its own team only and cannot reach red's team or use it in its league
+	w = send("blue-key", "GET", "/api/v2/teams", "")
+	var page dto.PageResponse[models.Team]
+	json.Unmarshal(w.Body.Bytes(), &page)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, blue.ID, page.Items[0].OrganizationID)
+
+	w = send("blue-key", "GET", "/api/v2/teams/"+strconv.Itoa(int(redTeam.ID)), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(blueLeague.ID))
+	w = send("blue-key", "PUT", leaguePath+"/teams/"+strconv.Itoa(int(redTeam.ID)), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Red cannot see blue's league
+	w = send("red-key", "GET", leaguePath, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuditEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	leagueController := controllers.NewLeagueController(leagueService, teamService)
+	auditController := controllers.NewAuditController(services.NewAuditService(repositories.NewAuditRepository(db)))
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "ops", Role: controllers.RoleAdmin, OrganizationID: 1, Key: "ops-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.POST("/leagues/:leagueID/play-all", leagueController.PlayAllMatches)
+	v2.PUT("/matches/:matchID/result", leagueController.EditMatchResults)
+	v2.GET("/leagues/:leagueID/audit", auditController.ListLeagueAudit)
+	v2.GET("/matches/:matchID/audit", auditController.ListMatchAudit)
+
+	send := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "ops-key")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Audited League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID))
+
+	w := send("POST", leaguePath+"/play-all", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	matches, _, _ := leagueService.WithScope(scope).FindMatches(repositories.MatchFilter{LeagueID: league.ID}, repositories.Page{Limit: 1})
+	matchPath := "/api/v2/matches/" + strconv.Itoa(int(matches[0].ID))
+	w = send("PUT", matchPath+"/result", `{"home_team_score":3,"away_team_score":3}`, controllers.AuditReasonHeader, "Scorer dispute #12")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", matchPath+"/audit", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page dto.PageResponse[models.AuditEntry]
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if assert.Len(t, page.Items, 1) {
+		entry := page.Items[0]
+		assert.Equal(t, "match.result_edited", entry.Action)
+		assert.Equal(t, "ops", entry.Actor)
+		assert.Equal(t, "Scorer dispute #12", entry.Reason)
+		assert.Contains(t, string(entry.After), `"home_team_score":3`)
+	}
+
+	// The league log lists the system's setup, the caller's changes and the match edit, newest first on request
+	w = send("GET", leaguePath+"/audit?sort=-id&limit=2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &page)
+	assert.Equal(t, int64(4), page.Total)
+	if assert.Len(t, page.Items, 2) {
+		assert.Equal(t, "match.result_edited", page.Items[0].Action)
+		assert.Equal(t, "league.all_matches_played", page.Items[1].Action)
+		assert.Equal(t, "ops", page.Items[1].Actor)
+	}
+
+	w = send("GET", leaguePath+"/audit?action=league.created", "")
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, repositories.SystemActor, page.Items[0].Actor)
+	}
+
+	w = send("GET", "/api/v2/matches/abc/audit", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookEndpoints(t *testing.T) {
+	db, _ := setupTest()
+	assert.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}))
+
+	var received []*http.Request
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r)
+	}))
+	defer receiver.Close()
+
+	leagueRepo := repositories.NewLeagueRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	settings := services.DefaultWebhookSettings()
+	settings.AllowedNetworks = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+	dispatcher := services.NewWebhookDispatcher(webhookRepo, events.NewBus(), settings)
+	webhookController := controllers.NewWebhookController(services.NewWebhookService(webhookRepo, leagueRepo, dispatcher))
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "ops", Role: controllers.RoleManager, OrganizationID: 1, Key: "ops-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/leagues/:leagueID/webhooks", webhookController.ListWebhooks)
+	v2.POST("/leagues/:leagueID/webhooks", webhookController.CreateWebhook)
+	v2.DELETE("/leagues/:leagueID/webhooks/:webhookID", webhookController.DeleteWebhook)
+	v2.GET("/leagues/:leagueID/webhooks/:webhookID/deliveries", webhookController.ListWebhookDeliveries)
+	v2.POST("/leagues/:leagueID/webhooks/:webhookID/ping", webhookController.PingWebhook)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "ops-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	league := &models.League{Name: "Hooked League"}
+	assert.NoError(t, leagueRepo.WithScope(repositories.Scope{OrganizationID: 1}).CreateLeague(league))
+	webhooksPath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID)) + "/webhooks"
+
+	w := send("POST", webhooksPath, `{"url":"not a url","events":["league.started"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("POST", webhooksPath, `{"url":"`+receiver.URL+`","events":["league.started","league.season_finished"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created dto.CreatedWebhook
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, []string{"league.started", "league.season_finished"}, created.Events)
+	webhookPath := webhooksPath + "/" + strconv.Itoa(int(created.ID))
+	assert.Equal(t, webhookPath, w.Header().Get("Location"))
+
+	w = send("GET", webhooksPath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret)
+
+	w = send("POST", webhookPath+"/ping", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var delivery models.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivery))
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, "webhook.ping", received[0].Header.Get(services.WebhookEventHeader))
+	}
+
+	w = send("GET", webhookPath+"/deliveries", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page dto.PageResponse[models.WebhookDelivery]
+	json.Unmarshal(w.Body.Bytes(), &page)
+	assert.Equal(t, int64(1), page.Total)
+
+	w = send("DELETE", webhookPath, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = send("GET", webhookPath+"/deliveries", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStreamLeague(t *testing.T) {
+	db, _ := setupTest()
+
+	bus := events.NewBus()
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, bus)
+	streamController := controllers.NewStreamController(events.NewBroker(bus), leagueService)
+	streamController.Heartbeat = 50 * time.Millisecond
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "board", Role: controllers.RoleViewer, OrganizationID: 1, Key: "board-key"},
+	}, nil)
+	router := gin.New()
+	router.GET("/api/leagues/:leagueID/stream", auth.Authenticate(), streamController.StreamLeague)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Streamed League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	streamPath := server.URL + "/api/leagues/" + strconv.Itoa(int(league.ID)) + "/stream"
+
+	// connect opens the stream and returns its lines as they arrive
+	connect := func(ctx context.Context, path, lastEventID string) (*http.Response, <-chan string) {
+		req, _ := http.NewRequestWithContext(ctx, "GET", path, nil)
+		req.Header.Set("X-API-Key", "board-key")
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		lines := make(chan string, 100)
+		go func() {
+			defer close(lines)
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(nil, 1<<20)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+		return resp, lines
+	}
+	// next returns the next line starting with prefix
+	next := func(lines <-chan string, prefix string) string {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case line, open := <-lines:
+				if !open {
+					t.Fatalf("stream closed while waiting for %q", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return line
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %q", prefix)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, lines := connect(ctx, streamPath, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	next(lines, "retry:")
+
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	assert.NoError(t, leagueService.WithScope(scope).AdvanceWeek(league.ID))
+
+	startedID := strings.TrimPrefix(next(lines, "id: "), "id: ")
+	assert.Equal(t, "event: league.started", next(lines, "event: "))
+	next(lines, "id: ")
+	assert.Equal(t, "event: league.week_advanced", next(lines, "event: "))
+	var week struct {
+		Data struct {
+			Week      int               `json:"week"`
+			Matches   []models.Match    `json:"matches"`
+			Standings []models.Standing `json:"standings"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(next(lines, "data: "), "data: ")), &week))
+	assert.Equal(t, 1, week.Data.Week)
+	assert.Len(t, week.Data.Matches, 2)
+	assert.Len(t, week.Data.Standings, 4)
+
+	next(lines, ": heartbeat")
+	cancel()
+
+	// A client that saw the first event resumes with the second one
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	_, lines = connect(ctx, streamPath, startedID)
+	assert.Equal(t, "event: league.week_advanced", next(lines, "event: "))
+
+	_, lines = connect(ctx, streamPath, "stale-1")
+	assert.Equal(t, "event: reset", next(lines, "event: "))
+
+	resp, _ = connect(ctx, server.URL+"/api/leagues/999/stream", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestLiveWeekEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	bus := events.NewBus()
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, bus)
+	liveController := controllers.NewLiveController(services.NewLiveMatchService(leagueService, bus))
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "presenter", Role: controllers.RoleManager, OrganizationID: 1, Key: "presenter-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/leagues/:leagueID/live", liveController.GetLiveWeek)
+	v2.POST("/leagues/:leagueID/live", liveController.StartLiveWeek)
+	v2.DELETE("/leagues/:leagueID/live", liveController.StopLiveWeek)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "presenter-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Live League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	livePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID)) + "/live"
+
+	w := send("GET", livePath, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send("POST", livePath, `{"duration_seconds":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("POST", livePath, `{"duration_seconds":3600}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var week dto.LiveWeek
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &week))
+	assert.Equal(t, dto.LiveWeekInProgress, week.Status)
+	assert.Equal(t, time.Hour, week.EndsAt.Sub(week.StartedAt))
+
+	w = send("POST", livePath, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = send("GET", livePath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &week))
+	assert.Equal(t, 0, week.Minute)
+	assert.Len(t, week.Matches, 2)
+
+	w = send("DELETE", livePath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &week))
+	assert.Equal(t, dto.LiveWeekCancelled, week.Status)
+	w = send("DELETE", livePath, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestScheduleEndpoints(t *testing.T) {
+	db, _ := setupTest()
+	assert.NoError(t, db.AutoMigrate(&models.AdvancementSchedule{}))
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	scheduleRepo := repositories.NewScheduleRepository(db)
+	scheduler := services.NewLeagueScheduler(scheduleRepo, leagueService, services.DefaultSchedulerSettings())
+	scheduleController := controllers.NewScheduleController(services.NewScheduleService(scheduleRepo, leagueRepo, scheduler))
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/leagues/:leagueID/schedule", scheduleController.GetSchedule)
+	v2.PUT("/leagues/:leagueID/schedule", scheduleController.SetSchedule)
+	v2.DELETE("/leagues/:leagueID/schedule", scheduleController.DeleteSchedule)
+	v2.POST("/leagues/:leagueID/schedule/pause", scheduleController.PauseSchedule)
+	v2.POST("/leagues/:leagueID/schedule/resume", scheduleController.ResumeSchedule)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	league := &models.League{Name: "Scheduled League"}
+	assert.NoError(t, leagueService.WithScope(repositories.Scope{OrganizationID: 1}).CreateLeague(league))
+	schedulePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID)) + "/schedule"
+
+	w := send("GET", schedulePath, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send("PUT", schedulePath, `{"kind":"daily"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("PUT", schedulePath, `{"kind":"weekly","weekday":"saturday","time":"3pm"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"time"`)
+
+	w = send("PUT", schedulePath, `{"kind":"weekly","weekday":"saturday","time":"15:00","timezone":"Europe/London","missed_run_policy":"skip"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var schedule models.AdvancementSchedule
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &schedule))
+	assert.Equal(t, "15:00", schedule.TimeOfDay)
+	assert.Equal(t, models.MissedRunsSkip, schedule.MissedRunPolicy)
+	assert.Equal(t, time.Saturday, schedule.NextRunAt.In(time.UTC).Weekday())
+
+	w = send("POST", schedulePath+"/pause", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"paused":true`)
+
+	w = send("POST", schedulePath+"/resume", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"paused":false`)
+
+	w = send("DELETE", schedulePath, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = send("POST", schedulePath+"/pause", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCalendarEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	calendarController := controllers.NewCalendarController(leagueService)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.PUT("/leagues/:leagueID/calendar", calendarController.SetLeagueCalendar)
+	v2.GET("/leagues/:leagueID/calendar.ics", calendarController.GetLeagueICalendar)
+	v2.GET("/teams/:teamID/calendar.ics", calendarController.GetTeamICalendar)
+	v2.PUT("/matches/:matchID/kickoff", calendarController.SetMatchKickoff)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Rovers, North", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Sunday League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID))
+
+	w := send("PUT", leaguePath+"/calendar", `{"start_date":"2024-08-18","kickoff_time":"25:00"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"kickoff_time"`)
+
+	w = send("PUT", leaguePath+"/calendar", `{"start_date":"2024-08-18","kickoff_time":"10:30","timezone":"Europe/Berlin"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"calendar":{"start_date":"2024-08-18","kickoff_time":"10:30","timezone":"Europe/Berlin","matchday_interval_days":7}`)
+
+	var first models.Match
+	assert.NoError(t, db.Where("league_id = ? AND week = 1", league.ID).Order("id").First(&first).Error)
+	w = send("PUT", "/api/v2/matches/"+strconv.Itoa(int(first.ID))+"/kickoff", `{"kickoff_at":"2024-08-18T18:00:00+02:00"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kickoff_at":"2024-08-18T16:00:00Z","kickoff_fixed":true`)
+
+	w = send("GET", leaguePath+"/calendar.ics", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	feed := w.Body.String()
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2*models.TotalWeeks, strings.Count(feed, "BEGIN:VEVENT"))
+	assert.Contains(t, feed, "X-WR-CALNAME:Sunday League\r\n")
+	assert.Contains(t, feed, "SUMMARY:Rovers\\, North vs Team B\r\n")
+	assert.Contains(t, feed, "DTSTART:20240818T160000Z\r\nDTEND:20240818T174500Z\r\n")
+	assert.Contains(t, feed, "DTSTART:20240825T083000Z\r\n", "week 2 follows the calendar")
+
+	w = send("GET", "/api/v2/teams/"+strconv.Itoa(int(teams[1].ID))+"/calendar.ics", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.TotalWeeks, strings.Count(w.Body.String(), "BEGIN:VEVENT"))
+	assert.Contains(t, w.Body.String(), "DESCRIPTION:Sunday League\\, week 1\r\n")
+
+	w = send("GET", "/api/v2/teams/999/calendar.ics", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVenueEndpoints(t *testing.T) {
+	db, _ := setupTest()
+	assert.NoError(t, db.AutoMigrate(&models.Venue{}))
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	venueController := controllers.NewVenueController(services.NewVenueService(repositories.NewVenueRepository(db), uow), leagueService)
+	teamController := controllers.NewTeamController(teamService)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/venues", venueController.GetAllVenues)
+	v2.POST("/venues", venueController.AddVenue)
+	v2.GET("/venues/:venueID", venueController.GetVenueByID)
+	v2.PUT("/venues/:venueID", venueController.UpdateVenue)
+	v2.DELETE("/venues/:venueID", venueController.DeleteVenue)
+	v2.PUT("/teams/:teamID", teamController.UpdateTeam)
+	v2.PUT("/matches/:matchID/venue", venueController.SetMatchVenue)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/v2/venues", `{"name":"Riverside Park","city":"Leeds","capacity":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"capacity"`)
+
+	w = send("POST", "/api/v2/venues", `{"name":"Riverside Park","city":"Leeds","capacity":30000,"latitude":53.78,"longitude":-1.57}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var ground models.Venue
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &ground))
+	assert.Equal(t, "/api/v2/venues/"+strconv.Itoa(int(ground.ID)), w.Header().Get("Location"))
+	w = send("POST", "/api/v2/venues", `{"name":"National Stadium","city":"London","capacity":60000}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var neutral models.Venue
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &neutral))
+
+	w = send("GET", "/api/v2/venues?city=leeds", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+
+	w = send("PUT", "/api/v2/venues/"+strconv.Itoa(int(neutral.ID)), `{"name":"National Stadium","city":"London","capacity":90000}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"capacity":90000`)
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	teamPath := "/api/v2/teams/" + strconv.Itoa(int(teams[0].ID))
+	w = send("PUT", teamPath, `{"name":"Team A","attack_strength":70,"defense_strength":70,"home_venue_id":999}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"home_venue_id"`)
+	w = send("PUT", teamPath, fmt.Sprintf(`{"name":"Team A","attack_strength":70,"defense_strength":70,"home_venue_id":%d}`, ground.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"home_venue_id":%d`, ground.ID))
+
+	league := &models.League{Name: "Cup", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	var final models.Match
+	assert.NoError(t, db.Where("league_id = ? AND home_team_id = ?", league.ID, teams[0].ID).First(&final).Error)
+	assert.Equal(t, ground.ID, *final.VenueID)
+
+	w = send("PUT", "/api/v2/matches/"+strconv.Itoa(int(final.ID))+"/venue", fmt.Sprintf(`{"venue_id":%d}`, neutral.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"venue_id":%d,"venue_fixed":true`, neutral.ID))
+
+	w = send("DELETE", "/api/v2/venues/"+strconv.Itoa(int(neutral.ID)), "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"venue_in_use"`)
+
+	w = send("GET", "/api/v2/venues/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFixtureConstraintEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	fixtureController := controllers.NewFixtureController(leagueService)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/leagues/:leagueID/constraints", fixtureController.GetConstraints)
+	v2.PUT("/leagues/:leagueID/constraints", fixtureController.SetConstraints)
+	v2.GET("/leagues/:leagueID/fixtures/report", fixtureController.GetFixtureReport)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Derby League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID))
+
+	w := send("PUT", leaguePath+"/constraints", `{"constraints":[{"kind":"holiday","team_id":1}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("PUT", leaguePath+"/constraints", fmt.Sprintf(`{"constraints":[{"kind":"blackout","team_id":%d,"week":0}]}`, teams[0].ID))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"constraints[0].week"`)
+
+	w = send("PUT", leaguePath+"/constraints", fmt.Sprintf(`{"constraints":[{"kind":"derby","team_id":%d,"other_team_id":%d,"week":1},{"kind":"derby","team_id":%d,"other_team_id":%d,"week":1}]}`,
+		teams[0].ID, teams[2].ID, teams[0].ID, teams[3].ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report dto.FixtureReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.False(t, report.Drawn)
+	assert.Equal(t, 2, report.Constraints)
+	assert.Len(t, report.Unsatisfied, 1, "team A meets one team a week")
+
+	w = send("GET", leaguePath+"/constraints", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, strings.Count(w.Body.String(), `"kind":"derby"`))
+
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	w = send("GET", leaguePath+"/fixtures/report", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"drawn":true`)
+	assert.Contains(t, w.Body.String(), `"reason":"the teams do not meet that week"`)
+
+	w = send("GET", "/api/v2/leagues/999/fixtures/report", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPostponementEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	calendarController := controllers.NewCalendarController(leagueService)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.PUT("/leagues/:leagueID/calendar", calendarController.SetLeagueCalendar)
+	v2.GET("/leagues/:leagueID/calendar.ics", calendarController.GetLeagueICalendar)
+	v2.POST("/matches/:matchID/postpone", calendarController.PostponeMatch)
+	v2.POST("/matches/:matchID/reschedule", calendarController.RescheduleMatch)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Sunday League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID))
+	w := send("PUT", leaguePath+"/calendar", `{"start_date":"2024-08-17","kickoff_time":"15:00"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var first models.Match
+	assert.NoError(t, db.Where("league_id = ? AND week = 1", league.ID).Order("id").First(&first).Error)
+	matchPath := "/api/v2/matches/" + strconv.Itoa(int(first.ID))
+
+	w = send("POST", matchPath+"/postpone", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"postponed"`)
+	assert.Contains(t, w.Body.String(), `"original_week":1`)
+	w = send("POST", matchPath+"/postpone", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"match_already_postponed"`)
+
+	w = send("GET", leaguePath+"/calendar.ics", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Team A vs Team B (postponed)\r\n")
+	assert.Contains(t, w.Body.String(), "STATUS:CANCELLED\r\n")
+
+	w = send("POST", matchPath+"/reschedule", `{"week":40}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("POST", matchPath+"/reschedule", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"week"`)
+
+	w = send("POST", matchPath+"/reschedule", `{"kickoff_at":"2024-09-04T19:45:00Z"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"week":4`)
+	assert.Contains(t, w.Body.String(), `"status":"scheduled"`)
+	assert.Contains(t, w.Body.String(), `"kickoff_at":"2024-09-04T19:45:00Z","kickoff_fixed":true`)
+
+	w = send("POST", "/api/v2/matches/999/postpone", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestManualResultEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	leagueController := controllers.NewLeagueController(leagueService, teamService)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.POST("/leagues", leagueController.CreateLeague)
+	v2.PUT("/leagues/:leagueID/teams/:teamID", leagueController.AddTeamToLeague)
+	v2.POST("/leagues/:leagueID/start", leagueController.StartLeague)
+	v2.POST("/leagues/:leagueID/advance", leagueController.AdvanceWeek)
+	v2.POST("/leagues/:leagueID/week/close", leagueController.CloseWeek)
+	v2.PUT("/leagues/:leagueID/result-mode", leagueController.SetResultMode)
+	v2.POST("/matches/:matchID/result", leagueController.SubmitMatchResult)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/v2/leagues", `{"name":"Sunday League","result_mode":"refereed"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("POST", "/api/v2/leagues", `{"name":"Sunday League","result_mode":"manual"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"result_mode":"manual"`)
+	var league models.League
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &league))
+	leaguePath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID))
+
+	scope := repositories.Scope{OrganizationID: 1}
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		assert.Equal(t, http.StatusOK, send("PUT", leaguePath+"/teams/"+strconv.Itoa(int(team.ID)), "").Code)
+	}
+	assert.Equal(t, http.StatusOK, send("POST", leaguePath+"/start", "").Code)
+
+	w = send("POST", leaguePath+"/advance", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"week_incomplete"`)
+
+	var fixtures []models.Match
+	assert.NoError(t, db.Where("league_id = ? AND week = 1", league.ID).Order("id").Find(&fixtures).Error)
+	matchPath := "/api/v2/matches/" + strconv.Itoa(int(fixtures[0].ID))
+	w = send("POST", matchPath+"/result", `{"home_team_score":2}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("POST", matchPath+"/result", `{"home_team_score":2,"away_team_score":1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"home_team_score":2,"away_team_score":1`)
+	assert.Contains(t, w.Body.String(), `"status":"played"`)
+
+	w = send("POST", leaguePath+"/week/close", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var closed models.Match
+	assert.NoError(t, db.First(&closed, fixtures[1].ID).Error)
+	assert.Equal(t, models.MatchStatusPostponed, closed.Status)
+
+	w = send("PUT", leaguePath+"/result-mode", `{"result_mode":"simulated"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"current_week":2,"result_mode":"simulated"`)
+	assert.Equal(t, http.StatusOK, send("POST", leaguePath+"/advance", "").Code)
+}
+
+func TestImportEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	uow := repositories.NewUnitOfWork(db)
+	importController := controllers.NewImportController(services.NewImportService(uow, events.NewBus()))
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.POST("/import", importController.Import)
+
+	send := func(contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v2/import", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("application/json", `{"teams":[{"name":"North","attack_strength":70,"defense_strength":70}],"leagues":[{"name":"Cup","teams":["North"]}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var report dto.ImportReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Len(t, report.Teams, 1)
+	if assert.Len(t, report.Leagues, 1) {
+		assert.Equal(t, uint(1), report.Leagues[0].OrganizationID)
+		assert.Len(t, report.Leagues[0].Teams, 1)
+	}
+
+	// Unreadable lines fail the whole CSV, each named by its line
+	w = send("text/csv", "# teams\nteam,South,70,seventy\nmember,Nowhere League,South\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem controllers.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	if assert.Len(t, problem.Errors, 2) {
+		assert.Equal(t, "lines[2]", problem.Errors[0].Field)
+		assert.Equal(t, "lines[3]", problem.Errors[1].Field)
+	}
+
+	csv := strings.Join([]string{
+		"team,South,70,70",
+		"team,East,65,60",
+		"team,West,60,65",
+		"league,Spring League",
+		"member,Spring League,North",
+		"member,Spring League,South",
+		"member,Spring League,East",
+		"member,Spring League,West",
+		"result,Spring League,1,North,South,2,1",
+		"result,Spring League,1,East,West,0,0",
+	}, "\n")
+	w = send("text/csv", strings.Replace(csv, "East,West,0,0", "East,Nowhere,0,0", 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"lines[10].away_team"`)
+
+	w = send("text/csv", csv)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Len(t, report.Teams, 3)
+	assert.Equal(t, 2, report.Results)
+	if assert.Len(t, report.Leagues, 1) {
+		assert.Equal(t, 2, report.Leagues[0].CurrentWeek)
+	}
+
+	// The body is bounded in size before it is read, whatever its format
+	oversized := strings.Repeat("a", controllers.MaxImportBytes)
+	for contentType, body := range map[string]string{
+		"application/json": `{"teams":[{"name":"` + oversized + `"}]}`,
+		"text/csv":         "team," + oversized + ",70,70\n",
+	} {
+		w = send(contentType, body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, contentType)
+		assert.Contains(t, w.Body.String(), `"code":"import_too_large"`, contentType)
+	}
+}
+
+func TestExportEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	exportController := controllers.NewExportController(leagueService)
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "viewer", Role: controllers.RoleViewer, OrganizationID: 1, Key: "viewer-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.GET("/leagues/:leagueID/export/standings", exportController.ExportStandings)
+	v2.GET("/leagues/:leagueID/export/fixtures", exportController.ExportFixtures)
+	v2.GET("/leagues/:leagueID/export/results", exportController.ExportResults)
+
+	send := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("X-API-Key", "viewer-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Rovers, North", "Team B", "Team C", "Team <D>"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Sunday | League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	assert.NoError(t, leagueService.WithScope(scope).AdvanceWeek(league.ID))
+	exportPath := "/api/v2/leagues/" + strconv.Itoa(int(league.ID)) + "/export/"
+
+	// JSON unless asked otherwise
+	w := send(exportPath+"standings", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	var standings []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &standings))
+	if assert.Len(t, standings, 4) {
+		assert.Equal(t, float64(1), standings[0]["position"])
+		assert.Equal(t, float64(1), standings[0]["played"])
+	}
+	assert.True(t, strings.HasPrefix(w.Body.String(), "[\n  {\n    \"position\": 1,\n    \"team\":"), "pretty and in column order")
+
+	w = send(exportPath+"standings?format=csv", "text/html")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, fmt.Sprintf(`inline; filename="league-%d-standings.csv"`, league.ID), w.Header().Get("Content-Disposition"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "position,team,played,wins,draws,losses,goal_difference,points", lines[0])
+	assert.Contains(t, w.Body.String(), `"Rovers, North"`)
+
+	w = send(exportPath+"results", "text/markdown")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	lines = strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, `## Sunday \| League results`, lines[0])
+	assert.Equal(t, "| ---: | --- | ---: | ---: | --- | --- |", lines[3])
+	assert.Len(t, lines, 6, "a title, a header, a separator and the 2 matches of week 1")
+
+	w = send(exportPath+"fixtures", "text/plain")
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines = strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, "Sunday | League fixtures", lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "Week  Kickoff  Home"), lines[2])
+	assert.True(t, strings.HasPrefix(lines[4], "   2  "), "numbers are aligned right: %q", lines[4])
+
+	w = send(exportPath+"standings", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "<!DOCTYPE html>"))
+	assert.Contains(t, w.Body.String(), "Team &lt;D&gt;")
+	assert.NotContains(t, w.Body.String(), "<D>")
+
+	assert.Equal(t, http.StatusBadRequest, send(exportPath+"standings?format=pdf", "").Code)
+	assert.Equal(t, http.StatusNotAcceptable, send(exportPath+"standings", "application/pdf").Code)
+	assert.Equal(t, http.StatusNotFound, send("/api/v2/leagues/999/export/results", "").Code)
+}
+
+func TestArchiveEndpoints(t *testing.T) {
+	db, _ := setupTest()
+
+	teamRepo := repositories.NewTeamRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db)
+	uow := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepo, leagueRepo, uow)
+	leagueService := services.NewLeagueService(leagueRepo, teamRepo, repositories.NewMatchRepository(db), repositories.NewStandingRepository(db), uow, events.NewBus())
+	archiveController := controllers.NewArchiveController(services.NewArchiveService(uow))
+
+	auth := controllers.NewAuthenticator([]controllers.APIKey{
+		{Name: "manager", Role: controllers.RoleManager, OrganizationID: 1, Key: "manager-key"},
+	}, nil)
+	router := gin.New()
+	v2 := router.Group("/api/v2", controllers.APIVersion(2), auth.Authenticate())
+	v2.POST("/leagues/restore", archiveController.RestoreArchive)
+	v2.GET("/leagues/:leagueID/archive", archiveController.ExportArchive)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "manager-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	scope := repositories.Scope{OrganizationID: 1}
+	var teams []models.Team
+	for _, name := range []string{"Team A", "Team B", "Team C", "Team D"} {
+		team := models.Team{Name: name, AttackStrength: 70, DefenseStrength: 70}
+		assert.NoError(t, teamService.WithScope(scope).CreateTeam(&team))
+		teams = append(teams, team)
+	}
+	league := &models.League{Name: "Sunday League", Teams: teams}
+	assert.NoError(t, leagueService.WithScope(scope).CreateLeague(league))
+	assert.NoError(t, leagueService.WithScope(scope).StartLeague(league.ID))
+	assert.NoError(t, leagueService.WithScope(scope).AdvanceWeek(league.ID))
+
+	w := send("GET", "/api/v2/leagues/"+strconv.Itoa(int(league.ID))+"/archive", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, fmt.Sprintf(`attachment; filename="league-%d-archive.json"`, league.ID), w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Body.String(), `"format":"leaguemanager/league-archive","version":1`)
+	archive := w.Body.String()
+
+	w = send("POST", "/api/v2/leagues/restore", archive)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var report dto.RestoreReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.NotEqual(t, league.ID, report.League.ID)
+	assert.Equal(t, "/api/v2/leagues/"+strconv.Itoa(int(report.League.ID)), w.Header().Get("Location"))
+	assert.Len(t, report.Teams, 4, "the teams of the same organization are matched")
+	for _, team := range report.Teams {
+		assert.True(t, team.Matched)
+	}
+
+	w = send("POST", "/api/v2/leagues/restore", strings.Replace(archive, `"version":1`, `"version":7`, 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"unsupported_archive_version"`)
+	w = send("POST", "/api/v2/leagues/restore", strings.Replace(archive, `"name":"Sunday League"`, `"name":"Monday League"`, 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"archive_checksum_mismatch"`)
+
+	// Archives are bounded in size and in entries, like imports
+	w = send("POST", "/api/v2/leagues/restore", `{"format":"`+strings.Repeat("a", controllers.MaxArchiveBytes)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"archive_too_large"`)
+	teamsJSON := strings.TrimSuffix(strings.Repeat(`{},`, services.MaxArchiveEntries+1), ",")
+	w = send("POST", "/api/v2/leagues/restore", `{"format":"leaguemanager/league-archive","version":1,"teams":[`+teamsJSON+`]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "at most 5000 entries")
+}
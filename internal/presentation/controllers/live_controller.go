@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LiveController handles the weeks played live
+type LiveController struct {
+	service services.LiveMatchService
+}
+
+// NewLiveController creates a new LiveController
+func NewLiveController(service services.LiveMatchService) *LiveController {
+	return &LiveController{service: service}
+}
+
+// StartLiveWeek starts playing the current week of a league live
+// @Summary Play the current week of a league live
+// @Description The matches of the week last duration_seconds of wall-clock time, 90 by default, instead of being resolved at once. Goals are revealed as their minute is reached and published as match.goal events on the league stream and webhooks.
+// @Description The results are only saved, and the league only advances, when the week is over. The week fails if the league was advanced in the meantime.
+// @Tags League
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param week body dto.LiveWeekRequest false "Duration of the week"
+// @Success 202 {object} dto.LiveWeek "Accepted"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/live [post]
+func (ctrl *LiveController) StartLiveWeek(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request dto.LiveWeekRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &request); err != nil {
+			respondError(c, err, "Invalid live week")
+			return
+		}
+	}
+	duration := services.DefaultLiveWeekDuration
+	if request.DurationSeconds != nil {
+		duration = time.Duration(*request.DurationSeconds) * time.Second
+	}
+
+	week, err := ctrl.service.WithScope(scopeOf(c)).StartLiveWeek(leagueID, duration)
+	if err != nil {
+		respondError(c, err, "Failed to start live week")
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path)
+	c.JSON(http.StatusAccepted, week)
+}
+
+// GetLiveWeek retrieves the running scores of the week a league is playing live
+// @Summary Get the live week of a league
+// @Description Shows the goals scored up to the current minute. Once the week is over it shows the final scores until the next live week starts.
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} dto.LiveWeek
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/live [get]
+func (ctrl *LiveController) GetLiveWeek(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	week, err := ctrl.service.WithScope(scopeOf(c)).GetLiveWeek(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve live week")
+		return
+	}
+
+	c.JSON(http.StatusOK, week)
+}
+
+// StopLiveWeek stops the week a league is playing live
+// @Summary Stop the live week of a league
+// @Description The week is cancelled at its current minute. Its results are not saved and the league stays at the week it was at, ready to play it again.
+// @Tags League
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} dto.LiveWeek
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/live [delete]
+func (ctrl *LiveController) StopLiveWeek(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	week, err := ctrl.service.WithScope(scopeOf(c)).StopLiveWeek(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to stop live week")
+		return
+	}
+
+	c.JSON(http.StatusOK, week)
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// OrganizationController handles organization-related requests
+type OrganizationController struct {
+	service services.OrganizationService
+}
+
+// NewOrganizationController creates a new OrganizationController
+func NewOrganizationController(service services.OrganizationService) *OrganizationController {
+	return &OrganizationController{service: service}
+}
+
+// GetOrganization retrieves the organization of the caller, which owns every team, league and match the caller sees
+// @Summary Get the caller's organization
+// @Tags Organization
+// @Produce json
+// @Success 200 {object} models.Organization
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /organization [get]
+// @Router /v2/organization [get]
+func (ctrl *OrganizationController) GetOrganization(c *gin.Context) {
+	organization, err := ctrl.service.GetOrganizationByID(scopeOf(c).OrganizationID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve organization")
+		return
+	}
+	c.JSON(http.StatusOK, organization)
+}
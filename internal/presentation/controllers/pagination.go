@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"LeagueManager/internal/domain/repositories"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes of the list endpoints
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// queryParams parses optional query parameters, collecting every malformed one so they can be reported together
+type queryParams struct {
+	c      *gin.Context
+	fields []apperrors.FieldError
+}
+
+func newQueryParams(c *gin.Context) *queryParams {
+	return &queryParams{c: c}
+}
+
+// int returns the named parameter, or nil if it is absent. Values below min are rejected.
+func (q *queryParams) int(name string, min int) *int {
+	raw := q.c.Query(name)
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		q.fields = append(q.fields, apperrors.FieldError{Field: name, Message: "must be an integer"})
+		return nil
+	}
+	if value < min {
+		q.fields = append(q.fields, apperrors.FieldError{Field: name, Message: fmt.Sprintf("must be at least %d", min)})
+		return nil
+	}
+	return &value
+}
+
+// id returns the named ID parameter, or zero if it is absent
+func (q *queryParams) id(name string) uint {
+	raw := q.c.Query(name)
+	if raw == "" {
+		return 0
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || value == 0 {
+		q.fields = append(q.fields, apperrors.FieldError{Field: name, Message: "must be a positive integer"})
+		return 0
+	}
+	return uint(value)
+}
+
+// page returns the limit, offset and sort parameters, applying the default and maximum page sizes. v1 lists were
+// never paged, they stay whole unless the request asks for a page with limit or offset.
+func (q *queryParams) page() repositories.Page {
+	page := repositories.Page{Sort: q.c.Query("sort")}
+	if apiVersion(q.c) >= 2 || q.c.Query("limit") != "" || q.c.Query("offset") != "" {
+		page.Limit = DefaultPageLimit
+	}
+	if limit := q.int("limit", 1); limit != nil {
+		page.Limit = *limit
+		if page.Limit > MaxPageLimit {
+			q.fields = append(q.fields, apperrors.FieldError{Field: "limit", Message: fmt.Sprintf("must be at most %d", MaxPageLimit)})
+		}
+	}
+	if offset := q.int("offset", 0); offset != nil {
+		page.Offset = *offset
+	}
+	return page
+}
+
+// err returns a validation error listing every malformed parameter, or nil
+func (q *queryParams) err() error {
+	if len(q.fields) == 0 {
+		return nil
+	}
+	return apperrors.Validation("validation_failed", "invalid query parameters").WithFields(q.fields...)
+}
+
+// setPageHeaders reports the total number of matching items in X-Total-Count and links the neighbouring
+// pages in a Link header (RFC 8288), keeping every other query parameter of the request
+func setPageHeaders(c *gin.Context, page repositories.Page, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	if page.Limit == 0 {
+		// The whole list was returned, there is no other page
+		return
+	}
+
+	var links []string
+	if next := nextPageURL(c, page, total); next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, next))
+	}
+	if page.Offset > 0 {
+		prev := page.Offset - page.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(c, page.Limit, prev)))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// nextPageURL returns the URL of the page after the given one, or "" if it is the last page
+func nextPageURL(c *gin.Context, page repositories.Page, total int64) string {
+	if page.Limit == 0 || int64(page.Offset+page.Limit) >= total {
+		return ""
+	}
+	return pageURL(c, page.Limit, page.Offset+page.Limit)
+}
+
+func pageURL(c *gin.Context, limit, offset int) string {
+	query := c.Request.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	return (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String()
+}
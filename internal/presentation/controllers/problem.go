@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ProblemContentType is the media type of every error response (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Code     string `json:"code"`
+	Instance string `json:"instance"`
+
+	// Errors lists every offending field when the request was rejected as invalid
+	Errors []apperrors.FieldError `json:"errors,omitempty"`
+}
+
+// statusFor maps the kind of a domain error to an HTTP status, errors of unknown kind are internal errors
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperrors.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperrors.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, apperrors.ErrPreconditionFailed):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondError writes a service error as a problem, message describes the operation that failed.
+// The details of internal errors are logged instead of being returned to the client.
+func respondError(c *gin.Context, err error, message string) {
+	status := statusFor(err)
+	if status == http.StatusInternalServerError {
+		logrus.WithError(err).Error(message)
+		respondProblem(c, status, "internal_error", message)
+		return
+	}
+
+	writeProblem(c, status, apperrors.CodeOf(err), message+": "+err.Error(), apperrors.FieldsOf(err))
+}
+
+// respondProblem writes a problem with the given status, code and detail and aborts the request
+func respondProblem(c *gin.Context, status int, code, detail string) {
+	writeProblem(c, status, code, detail, nil)
+}
+
+func writeProblem(c *gin.Context, status int, code, detail string, fields []apperrors.FieldError) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Code:     code,
+		Instance: c.Request.URL.Path,
+		Errors:   fields,
+	})
+}
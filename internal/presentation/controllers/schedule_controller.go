@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleController handles the schedules that advance leagues automatically
+type ScheduleController struct {
+	service services.ScheduleService
+}
+
+// NewScheduleController creates a new ScheduleController
+func NewScheduleController(service services.ScheduleService) *ScheduleController {
+	return &ScheduleController{service: service}
+}
+
+func (ctrl *ScheduleController) schedules(c *gin.Context) services.ScheduleService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// SetSchedule creates or replaces the advancement schedule of a league
+// @Summary Advance a league automatically
+// @Description The league advances one week on every run of the schedule: weekly on a weekday at a time of day in a time zone, UTC by default, or every interval_minutes minutes.
+// @Description missed_run_policy decides what happens to the runs missed while the server was down: "once" (the default) advances once, "catch_up" once per missed run and "skip" only if the latest run is barely late.
+// @Description A schedule that is replaced starts from its first run after now and keeps its paused state.
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param schedule body dto.ScheduleRequest true "Schedule of the league"
+// @Success 200 {object} models.AdvancementSchedule
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/schedule [put]
+func (ctrl *ScheduleController) SetSchedule(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ScheduleRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid schedule")
+		return
+	}
+
+	schedule, err := ctrl.schedules(c).SetSchedule(leagueID, &request)
+	if err != nil {
+		respondError(c, err, "Failed to set schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetSchedule retrieves the advancement schedule of a league
+// @Summary Get the schedule of a league
+// @Description last_error holds why the latest run failed to advance the league, it is empty after a successful run.
+// @Tags Schedules
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} models.AdvancementSchedule
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/schedule [get]
+func (ctrl *ScheduleController) GetSchedule(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := ctrl.schedules(c).GetSchedule(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule stops advancing a league automatically
+// @Summary Delete the schedule of a league
+// @Tags Schedules
+// @Param leagueID path int true "League ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/schedule [delete]
+func (ctrl *ScheduleController) DeleteSchedule(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.schedules(c).DeleteSchedule(leagueID); err != nil {
+		respondError(c, err, "Failed to delete schedule")
+		return
+	}
+
+	respondDeleted(c, gin.H{"message": "Schedule deleted"})
+}
+
+// PauseSchedule stops the runs of a schedule until it is resumed
+// @Summary Pause the schedule of a league
+// @Description A schedule is also paused automatically once its league has ended.
+// @Tags Schedules
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} models.AdvancementSchedule
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/schedule/pause [post]
+func (ctrl *ScheduleController) PauseSchedule(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := ctrl.schedules(c).PauseSchedule(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to pause schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// ResumeSchedule restarts a paused schedule
+// @Summary Resume the schedule of a league
+// @Description The schedule restarts from its first run after now; the runs missed while it was paused are never made.
+// @Tags Schedules
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {object} models.AdvancementSchedule
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/schedule/resume [post]
+func (ctrl *ScheduleController) ResumeSchedule(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := ctrl.schedules(c).ResumeSchedule(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to resume schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/events"
+	"LeagueManager/internal/application/services"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultStreamHeartbeat is how often an idle stream sends a comment so proxies and clients keep it open
+const DefaultStreamHeartbeat = 15 * time.Second
+
+// streamRetry is the reconnection delay suggested to clients, in milliseconds
+const streamRetry = 3000
+
+// StreamController streams the events of leagues as Server-Sent Events
+type StreamController struct {
+	broker  *events.Broker
+	service services.LeagueService
+
+	// Heartbeat is the interval of the keep-alive comments, DefaultStreamHeartbeat unless changed
+	Heartbeat time.Duration
+}
+
+// NewStreamController creates a new StreamController
+func NewStreamController(broker *events.Broker, service services.LeagueService) *StreamController {
+	return &StreamController{broker: broker, service: service, Heartbeat: DefaultStreamHeartbeat}
+}
+
+// StreamLeague streams the events of a league as they happen
+// @Summary Stream the events of a league
+// @Description Server-Sent Events carrying the same events as webhooks: every message is named after the event type and holds the event as JSON, with the played matches and the updated standings in its data.
+// @Description Reconnecting clients send the ID of the last message in the Last-Event-ID header, or the last_event_id parameter, to receive what they missed. When that is no longer possible a "reset" message asks them to reload the league.
+// @Description A comment is sent every 15 seconds while the stream is idle.
+// @Description Browsers' EventSource cannot send headers, it can pass a feed token in the access_token parameter.
+// @Tags League
+// @Produce text/event-stream
+// @Param leagueID path int true "League ID"
+// @Param Last-Event-ID header string false "ID of the last message received, to resume after it"
+// @Param last_event_id query string false "Same as the Last-Event-ID header, for clients that cannot set headers"
+// @Success 200 {object} string "Event stream"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /leagues/{leagueID}/stream [get]
+// @Router /v2/leagues/{leagueID}/stream [get]
+func (ctrl *StreamController) StreamLeague(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	scope := scopeOf(c)
+	if _, err := ctrl.service.WithScope(scope).GetLeagueByID(leagueID); err != nil {
+		respondError(c, err, "Failed to stream league")
+		return
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	subscription := ctrl.broker.Subscribe(scope.OrganizationID, leagueID, lastID)
+	defer subscription.Cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+	if subscription.Reset {
+		writeStreamEvent(c.Writer, "", "reset", []byte(`{"reason":"events since the last event ID are no longer available, reload the league"}`))
+	}
+	for _, message := range subscription.Backlog {
+		writeStreamMessage(c.Writer, message)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(ctrl.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case message, open := <-subscription.Messages:
+			if !open {
+				// Dropped for falling behind, the client resumes from its last message
+				return
+			}
+			writeStreamMessage(c.Writer, message)
+		case now := <-heartbeat.C:
+			fmt.Fprintf(c.Writer, ": heartbeat %s\n\n", now.UTC().Format(time.RFC3339))
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeStreamMessage(w io.Writer, message events.Message) {
+	data, err := json.Marshal(message.Event)
+	if err != nil {
+		data = []byte(`{}`)
+	}
+	writeStreamEvent(w, message.ID, message.Event.Type, data)
+}
+
+// writeStreamEvent writes one Server-Sent Event, splitting the data over several data lines if needed
+func writeStreamEvent(w io.Writer, id, event string, data []byte) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\n", event)
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
@@ -2,10 +2,14 @@ package controllers
 
 import (
 	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
 	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // TeamController handles team-related requests
@@ -18,27 +22,36 @@ func NewTeamController(service services.TeamService) *TeamController {
 	return &TeamController{service: service}
 }
 
+// teams returns the team service restricted to the organization of the caller
+func (ctrl *TeamController) teams(c *gin.Context) services.TeamService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
 // AddTeam adds a new team to the database
 // @Summary Add a new team
 // @Tags Team
 // @Accept json
 // @Produce json
-// @Param team body models.Team true "Team to add"
+// @Param team body dto.TeamRequest true "Team to add"
 // @Success 200 {object} models.Team
-// @Failure 400 {object} gin.H
-// @Failure 500 {object} gin.H
+// @Success 201 {object} models.Team "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
 // @Router /teams [post]
+// @Router /v2/teams [post]
 func (ctrl *TeamController) AddTeam(c *gin.Context) {
-	var team *models.Team
-	if err := c.ShouldBindJSON(&team); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request dto.TeamRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid team")
 		return
 	}
-	if err := ctrl.service.CreateTeam(team); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	team := teamFromRequest(&request)
+	if err := ctrl.teams(c).CreateTeam(team); err != nil {
+		respondError(c, err, "Failed to create team")
 		return
 	}
-	c.JSON(http.StatusOK, team)
+	respondCreated(c, fmt.Sprintf("/api/v2/teams/%d", team.ID), team, team)
 }
 
 // GetTeamByID retrieves a team by its ID
@@ -47,13 +60,20 @@ func (ctrl *TeamController) AddTeam(c *gin.Context) {
 // @Produce json
 // @Param teamID path int true "Team ID"
 // @Success 200 {object} models.Team
-// @Failure 404 {object} gin.H
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
 // @Router /teams/{teamID} [get]
+// @Router /v2/teams/{teamID} [get]
 func (ctrl *TeamController) GetTeamByID(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("teamID"))
-	team, err := ctrl.service.GetTeamByID(uint(id))
+	id, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
+		return
+	}
+	team, err := ctrl.teams(c).GetTeamByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		respondError(c, err, "Failed to retrieve team")
 		return
 	}
 	c.JSON(http.StatusOK, team)
@@ -65,21 +85,28 @@ func (ctrl *TeamController) GetTeamByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param teamID path int true "Team ID"
-// @Param team body models.Team true "Updated team"
+// @Param team body dto.TeamRequest true "Updated team"
 // @Success 200 {object} models.Team
-// @Failure 400 {object} gin.H
-// @Failure 500 {object} gin.H
+// @Failure 404 {object} controllers.Problem
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
 // @Router /teams/{teamID} [put]
+// @Router /v2/teams/{teamID} [put]
 func (ctrl *TeamController) UpdateTeam(c *gin.Context) {
-	var team *models.Team
-	if err := c.ShouldBindJSON(&team); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
 		return
 	}
-	id, _ := strconv.Atoi(c.Param("teamID"))
+	var request dto.TeamRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid team")
+		return
+	}
+	team := teamFromRequest(&request)
 	team.ID = uint(id)
-	if err := ctrl.service.UpdateTeam(team); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := ctrl.teams(c).UpdateTeam(team); err != nil {
+		respondError(c, err, "Failed to update team")
 		return
 	}
 	c.JSON(http.StatusOK, team)
@@ -91,29 +118,76 @@ func (ctrl *TeamController) UpdateTeam(c *gin.Context) {
 // @Produce json
 // @Param teamID path int true "Team ID"
 // @Success 200 {object} gin.H
-// @Failure 500 {object} gin.H
+// @Success 204 "Deleted"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
 // @Router /teams/{teamID} [delete]
+// @Router /v2/teams/{teamID} [delete]
 func (ctrl *TeamController) DeleteTeam(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("teamID"))
-	if err := ctrl.service.DeleteTeam(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	id, err := strconv.ParseUint(c.Param("teamID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_team_id", "Invalid team ID")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Team deleted"})
+	if err := ctrl.teams(c).DeleteTeam(uint(id)); err != nil {
+		respondError(c, err, "Failed to delete team")
+		return
+	}
+	respondDeleted(c, gin.H{"message": "Team deleted"})
 }
 
-// GetAllTeams retrieves all teams
-// @Summary Get all teams
+// GetAllTeams retrieves one page of the teams matching the query filters
+// @Summary List teams
 // @Tags Team
 // @Produce json
+// @Param name query string false "Only teams whose name contains this text, ignoring case"
+// @Param min_attack query int false "Minimum attack strength"
+// @Param max_attack query int false "Maximum attack strength"
+// @Param min_defense query int false "Minimum defense strength"
+// @Param max_defense query int false "Maximum defense strength"
+// @Param venue_id query int false "Only teams whose home ground is this venue"
+// @Param sort query string false "Sort field (id, name, attack_strength, defense_strength, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500. v1 lists every team unless limit or offset is given"
+// @Param offset query int false "Number of teams to skip"
 // @Success 200 {array} models.Team
-// @Failure 500 {object} gin.H
+// @Header 200 {integer} X-Total-Count "Number of matching teams"
+// @Header 200 {string} Link "Links to the next and previous pages"
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
 // @Router /teams [get]
+// @Router /v2/teams [get]
 func (ctrl *TeamController) GetAllTeams(c *gin.Context) {
-	teams, err := ctrl.service.GetAllTeams()
+	params := newQueryParams(c)
+	filter := repositories.TeamFilter{
+		NameContains: c.Query("name"),
+		MinAttack:    params.int("min_attack", models.MinStrength),
+		MaxAttack:    params.int("max_attack", models.MinStrength),
+		MinDefense:   params.int("min_defense", models.MinStrength),
+		MaxDefense:   params.int("max_defense", models.MinStrength),
+		VenueID:      params.id("venue_id"),
+	}
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid team query")
+		return
+	}
+
+	teams, total, err := ctrl.teams(c).FindTeams(filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, "Failed to retrieve teams")
 		return
 	}
-	c.JSON(http.StatusOK, teams)
+	respondList(c, teams, page, total)
+}
+
+// teamFromRequest maps a validated team request onto a new team model
+func teamFromRequest(request *dto.TeamRequest) *models.Team {
+	return &models.Team{
+		Name:            strings.TrimSpace(request.Name),
+		AttackStrength:  *request.AttackStrength,
+		DefenseStrength: *request.DefenseStrength,
+		HomeVenueID:     request.HomeVenueID,
+	}
 }
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"LeagueManager/internal/application/services"
+	"LeagueManager/internal/domain/apperrors"
 	"LeagueManager/internal/domain/models"
 	"LeagueManager/internal/domain/repositories"
 	"encoding/json"
@@ -18,7 +19,7 @@ import (
 
 func setupRouter() *gin.Engine {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	err := db.AutoMigrate(&models.Team{}, &models.League{})
+	err := db.AutoMigrate(&models.Team{}, &models.League{}, &models.AuditEntry{})
 	if err != nil {
 		return nil
 	}
@@ -26,7 +27,7 @@ func setupRouter() *gin.Engine {
 	repo := repositories.NewTeamRepository(db)
 	repoLeague := repositories.NewLeagueRepository(db)
 
-	service := services.NewTeamService(repo, repoLeague)
+	service := services.NewTeamService(repo, repoLeague, repositories.NewUnitOfWork(db))
 	controller := NewTeamController(service)
 
 	r := gin.Default()
@@ -111,3 +112,135 @@ func TestTeamController(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestTeamValidation(t *testing.T) {
+	router := setupRouter()
+	assert.NotNil(t, router)
+
+	postTeam := func(body string) (*httptest.ResponseRecorder, Problem) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/teams", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		var problem Problem
+		json.Unmarshal(w.Body.Bytes(), &problem)
+		return w, problem
+	}
+
+	// Every failing field is reported
+	w, problem := postTeam(`{"name": "", "attack_strength": -5, "defense_strength": 10000}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.ElementsMatch(t, []apperrors.FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "attack_strength", Message: "must be at least 0"},
+		{Field: "defense_strength", Message: "must be at most 100"},
+	}, problem.Errors)
+
+	// Unknown fields are rejected instead of being ignored
+	w, problem = postTeam(`{"name": "Team A", "attack_strength": 50, "defense_strength": 50, "id": 7}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []apperrors.FieldError{{Field: "id", Message: "is not accepted"}}, problem.Errors)
+
+	// Names are unique
+	w, _ = postTeam(`{"name": "Team A", "attack_strength": 50, "defense_strength": 50}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, problem = postTeam(`{"name": "Team A", "attack_strength": 60, "defense_strength": 60}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "team_name_taken", problem.Code)
+	assert.Equal(t, []apperrors.FieldError{{Field: "name", Message: "must be unique"}}, problem.Errors)
+}
+
+func TestGetAllTeamsPagination(t *testing.T) {
+	router := setupRouter()
+	assert.NotNil(t, router)
+
+	for i := 1; i <= 5; i++ {
+		w := httptest.NewRecorder()
+		reqBody := `{"name": "Team ` + strconv.Itoa(i) + `", "attack_strength": ` + strconv.Itoa(i*10) + `, "defense_strength": 50}`
+		req, _ := http.NewRequest("POST", "/api/teams", strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// A middle page links both of its neighbours and keeps the filters
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/teams?min_attack=20&sort=-attack_strength&limit=2&offset=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "4", w.Header().Get("X-Total-Count"))
+	assert.Equal(t,
+		`</api/teams?limit=2&min_attack=20&offset=3&sort=-attack_strength>; rel="next", `+
+			`</api/teams?limit=2&min_attack=20&offset=0&sort=-attack_strength>; rel="prev"`,
+		w.Header().Get("Link"))
+
+	var teams []models.Team
+	json.Unmarshal(w.Body.Bytes(), &teams)
+	assert.Len(t, teams, 2)
+	assert.Equal(t, "Team 4", teams[0].Name)
+	assert.Equal(t, "Team 3", teams[1].Name)
+
+	// The last page has no next link
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/teams", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Empty(t, w.Header().Get("Link"))
+
+	// Malformed parameters are reported together
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/teams?limit=1000&offset=-1&min_attack=strong", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	assert.ElementsMatch(t, []apperrors.FieldError{
+		{Field: "min_attack", Message: "must be an integer"},
+		{Field: "limit", Message: "must be at most 500"},
+		{Field: "offset", Message: "must be at least 0"},
+	}, problem.Errors)
+
+	// Unknown sort fields are rejected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/teams?sort=secret", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAllTeamsV1ListsEveryTeam(t *testing.T) {
+	router := setupRouter()
+	assert.NotNil(t, router)
+
+	count := DefaultPageLimit + 10
+	for i := 1; i <= count; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/teams", strings.NewReader(`{"name": "Team `+strconv.Itoa(i)+`", "attack_strength": 50, "defense_strength": 50}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// v1 clients read the bare array, which holds every team unless they ask for a page
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/teams", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var teams []models.Team
+	json.Unmarshal(w.Body.Bytes(), &teams)
+	assert.Len(t, teams, count)
+	assert.Equal(t, strconv.Itoa(count), w.Header().Get("X-Total-Count"))
+	assert.Empty(t, w.Header().Get("Link"))
+
+	// An offset alone asks for a page of the default size
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/teams?offset=5", nil)
+	router.ServeHTTP(w, req)
+	teams = nil
+	json.Unmarshal(w.Body.Bytes(), &teams)
+	assert.Len(t, teams, DefaultPageLimit)
+	assert.Equal(t, `</api/teams?limit=50&offset=55>; rel="next", </api/teams?limit=50&offset=0>; rel="prev"`, w.Header().Get("Link"))
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// TokenClaims are the claims of a bearer token. Tokens are JWTs signed with HMAC-SHA256 (HS256),
+// name the organization the caller acts for and must expire.
+type TokenClaims struct {
+	Subject        string `json:"sub"`
+	Role           Role   `json:"role"`
+	OrganizationID uint   `json:"org"`
+	Scope          string `json:"scope,omitempty"` // TokenScopeFeed limits the token to the feed routes
+	IssuedAt       int64  `json:"iat,omitempty"`
+	ExpiresAt      int64  `json:"exp"`
+}
+
+// TokenScopeFeed marks the tokens that only read calendar feeds and event streams. They are the only tokens
+// accepted in a URL, which ends up in logs and browser history.
+const TokenScopeFeed = "feed"
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// SignToken issues a bearer token for the claims, signed with secret
+func SignToken(secret []byte, claims TokenClaims) (string, error) {
+	if claims.Subject == "" || !claims.Role.valid() || claims.OrganizationID == 0 || claims.ExpiresAt == 0 {
+		return "", errors.New("a token needs a subject, a known role, an organization and an expiry")
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + tokenSignature(secret, signed), nil
+}
+
+// verifyToken checks the signature and expiry of a bearer token and returns its claims
+func verifyToken(secret []byte, token string, now time.Time) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	// Only HS256 is accepted, so a token cannot downgrade itself to "none" or another algorithm
+	if err := json.Unmarshal(rawHeader, &header); err != nil || header.Alg != "HS256" {
+		return nil, errors.New("unsupported token algorithm")
+	}
+
+	expected := tokenSignature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+
+	switch {
+	case claims.ExpiresAt == 0 || now.Unix() >= claims.ExpiresAt:
+		return nil, errors.New("token has expired")
+	case claims.Subject == "" || !claims.Role.valid() || claims.OrganizationID == 0:
+		return nil, errors.New("token lacks a subject, a known role or an organization")
+	}
+	return &claims, nil
+}
+
+func tokenSignature(secret []byte, signed string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"LeagueManager/internal/domain/apperrors"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("notblank", validators.NotBlank)
+
+		// Report fields by their JSON names, which is what clients send
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// bindJSON decodes the request body into obj, rejecting fields obj does not declare, and validates it
+// against its binding tags. The returned error lists every offending field.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return invalidBody(err)
+	}
+
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		return invalidBody(err)
+	}
+	return nil
+}
+
+// invalidBody turns a decoding or validation error into a validation error with the offending fields
+func invalidBody(err error) error {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]apperrors.FieldError, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fields = append(fields, apperrors.FieldError{Field: fieldError.Field(), Message: describeFieldError(fieldError)})
+		}
+		return apperrors.Validation("validation_failed", "invalid request body").WithFields(fields...)
+	case errors.As(err, &typeError):
+		return apperrors.Validation("validation_failed", "invalid request body").
+			WithFields(apperrors.FieldError{Field: typeError.Field, Message: "must be of type " + typeError.Type.String()})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperrors.Validation("validation_failed", "invalid request body").
+			WithFields(apperrors.FieldError{Field: field, Message: "is not accepted"})
+	case errors.Is(err, io.EOF):
+		return apperrors.Validation("invalid_request_body", "request body is empty")
+	default:
+		return apperrors.Validation("invalid_request_body", "request body is not valid JSON").Wrap(err)
+	}
+}
+
+// describeFieldError renders a single failed binding rule as a short sentence
+func describeFieldError(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "min":
+		return "must be at least " + fieldError.Param()
+	case "max":
+		if fieldError.Kind() == reflect.String {
+			return "must be at most " + fieldError.Param() + " characters long"
+		}
+		return "must be at most " + fieldError.Param()
+	default:
+		return "must satisfy " + fieldError.Tag()
+	}
+}
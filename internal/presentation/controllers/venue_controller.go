@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/models"
+	"LeagueManager/internal/domain/repositories"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VenueController handles venues and the venues of single matches
+type VenueController struct {
+	service       services.VenueService
+	leagueService services.LeagueService
+}
+
+// NewVenueController creates a new VenueController
+func NewVenueController(service services.VenueService, leagueService services.LeagueService) *VenueController {
+	return &VenueController{service: service, leagueService: leagueService}
+}
+
+// venues returns the venue service restricted to the organization of the caller
+func (ctrl *VenueController) venues(c *gin.Context) services.VenueService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// venueIDParam parses the venueID path parameter, responding with a problem if it is invalid
+func venueIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("venueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_venue_id", "Invalid venue ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// AddVenue adds a new venue
+// @Summary Add a new venue
+// @Tags Venue
+// @Accept json
+// @Produce json
+// @Param venue body dto.VenueRequest true "Venue to add"
+// @Success 201 {object} models.Venue "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/venues [post]
+func (ctrl *VenueController) AddVenue(c *gin.Context) {
+	var request dto.VenueRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid venue")
+		return
+	}
+	venue := venueFromRequest(&request)
+	if err := ctrl.venues(c).CreateVenue(venue); err != nil {
+		respondError(c, err, "Failed to create venue")
+		return
+	}
+	respondCreated(c, fmt.Sprintf("/api/v2/venues/%d", venue.ID), venue, venue)
+}
+
+// GetVenueByID retrieves a venue by its ID
+// @Summary Get a venue by ID
+// @Tags Venue
+// @Produce json
+// @Param venueID path int true "Venue ID"
+// @Success 200 {object} models.Venue
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/venues/{venueID} [get]
+func (ctrl *VenueController) GetVenueByID(c *gin.Context) {
+	id, ok := venueIDParam(c)
+	if !ok {
+		return
+	}
+	venue, err := ctrl.venues(c).GetVenueByID(id)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve venue")
+		return
+	}
+	c.JSON(http.StatusOK, venue)
+}
+
+// UpdateVenue updates an existing venue
+// @Summary Update an existing venue
+// @Tags Venue
+// @Accept json
+// @Produce json
+// @Param venueID path int true "Venue ID"
+// @Param venue body dto.VenueRequest true "Updated venue"
+// @Success 200 {object} models.Venue
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/venues/{venueID} [put]
+func (ctrl *VenueController) UpdateVenue(c *gin.Context) {
+	id, ok := venueIDParam(c)
+	if !ok {
+		return
+	}
+	var request dto.VenueRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid venue")
+		return
+	}
+	venue := venueFromRequest(&request)
+	venue.ID = id
+	if err := ctrl.venues(c).UpdateVenue(venue); err != nil {
+		respondError(c, err, "Failed to update venue")
+		return
+	}
+	c.JSON(http.StatusOK, venue)
+}
+
+// DeleteVenue deletes a venue by its ID
+// @Summary Delete a venue by ID
+// @Description A venue that is the home ground of a team or hosts an unplayed match cannot be deleted.
+// @Tags Venue
+// @Param venueID path int true "Venue ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 409 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/venues/{venueID} [delete]
+func (ctrl *VenueController) DeleteVenue(c *gin.Context) {
+	id, ok := venueIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.venues(c).DeleteVenue(id); err != nil {
+		respondError(c, err, "Failed to delete venue")
+		return
+	}
+	respondDeleted(c, gin.H{"message": "Venue deleted"})
+}
+
+// GetAllVenues retrieves one page of the venues matching the query filters
+// @Summary List venues
+// @Tags Venue
+// @Produce json
+// @Param name query string false "Only venues whose name contains this text, ignoring case"
+// @Param city query string false "Only venues in this city, ignoring case"
+// @Param sort query string false "Sort field (id, name, city, capacity, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500"
+// @Param offset query int false "Number of venues to skip"
+// @Success 200 {array} models.Venue
+// @Header 200 {integer} X-Total-Count "Number of matching venues"
+// @Header 200 {string} Link "Links to the next and previous pages"
+// @Failure 400 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/venues [get]
+func (ctrl *VenueController) GetAllVenues(c *gin.Context) {
+	params := newQueryParams(c)
+	filter := repositories.VenueFilter{
+		NameContains: c.Query("name"),
+		City:         strings.TrimSpace(c.Query("city")),
+	}
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid venue query")
+		return
+	}
+
+	venues, total, err := ctrl.venues(c).FindVenues(filter, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve venues")
+		return
+	}
+	respondList(c, venues, page, total)
+}
+
+// SetMatchVenue moves a single match to a venue
+// @Summary Move a match to a venue
+// @Description Only unplayed matches can be moved, e.g. to a neutral venue. The home team only has its home advantage at its own ground. A null venue_id moves the match back to the home team's ground.
+// @Tags Match
+// @Accept json
+// @Produce json
+// @Param matchID path int true "Match ID"
+// @Param venue body dto.MatchVenueRequest true "Venue of the match"
+// @Success 200 {object} models.Match
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 422 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/matches/{matchID}/venue [put]
+func (ctrl *VenueController) SetMatchVenue(c *gin.Context) {
+	matchID, err := strconv.ParseUint(c.Param("matchID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_match_id", "Invalid match ID")
+		return
+	}
+
+	var request dto.MatchVenueRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid venue")
+		return
+	}
+
+	match, err := ctrl.leagueService.WithScope(scopeOf(c)).SetMatchVenue(uint(matchID), request.VenueID)
+	if err != nil {
+		respondError(c, err, "Failed to move match")
+		return
+	}
+	c.JSON(http.StatusOK, match)
+}
+
+// venueFromRequest maps a validated venue request onto a new venue model
+func venueFromRequest(request *dto.VenueRequest) *models.Venue {
+	venue := &models.Venue{
+		Name:     strings.TrimSpace(request.Name),
+		City:     strings.TrimSpace(request.City),
+		Capacity: *request.Capacity,
+	}
+	if request.Latitude != nil {
+		venue.Latitude = *request.Latitude
+	}
+	if request.Longitude != nil {
+		venue.Longitude = *request.Longitude
+	}
+	return venue
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	dto "LeagueManager/internal/domain/dtos"
+	"LeagueManager/internal/domain/repositories"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiVersionKey = "api_version"
+
+// APIVersion marks the requests of a route group with the version of the API they belong to. Handlers are
+// shared between versions and only differ in the shape of their responses; unmarked requests are v1.
+func APIVersion(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+func apiVersion(c *gin.Context) int {
+	if version, ok := c.Get(apiVersionKey); ok {
+		return version.(int)
+	}
+	return 1
+}
+
+// respondList writes one page of a list. v1 returns the bare array, v2 wraps it in a dto.PageResponse;
+// both carry the X-Total-Count and Link headers.
+func respondList[T any](c *gin.Context, items []T, page repositories.Page, total int64) {
+	setPageHeaders(c, page, total)
+	if apiVersion(c) < 2 {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
+	if items == nil {
+		items = []T{}
+	}
+	c.JSON(http.StatusOK, dto.PageResponse[T]{Items: items, Total: total, Next: nextPageURL(c, page, total)})
+}
+
+// respondCreated writes a newly created resource. v2 answers 201 with the resource and its location,
+// v1 keeps answering 200 with its original body.
+func respondCreated(c *gin.Context, location string, resource, v1Body interface{}) {
+	if apiVersion(c) < 2 {
+		c.JSON(http.StatusOK, v1Body)
+		return
+	}
+	c.Header("Location", location)
+	c.JSON(http.StatusCreated, resource)
+}
+
+// respondDeleted confirms a deletion. v2 answers 204 without a body, v1 keeps answering 200 with its original body.
+func respondDeleted(c *gin.Context, v1Body interface{}) {
+	if apiVersion(c) < 2 {
+		c.JSON(http.StatusOK, v1Body)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
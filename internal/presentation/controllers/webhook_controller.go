@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"LeagueManager/internal/application/services"
+	dto "LeagueManager/internal/domain/dtos"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookController handles the webhook subscriptions of leagues
+type WebhookController struct {
+	service services.WebhookService
+}
+
+// NewWebhookController creates a new WebhookController
+func NewWebhookController(service services.WebhookService) *WebhookController {
+	return &WebhookController{service: service}
+}
+
+func (ctrl *WebhookController) webhooks(c *gin.Context) services.WebhookService {
+	return ctrl.service.WithScope(scopeOf(c))
+}
+
+// CreateWebhook subscribes a URL to the events of a league
+// @Summary Subscribe to the events of a league
+// @Description Events are POSTed as JSON and signed in the X-LeagueManager-Signature header with "sha256=" and the hex HMAC-SHA256 of the X-LeagueManager-Timestamp header, a dot and the body.
+// @Description The secret is only returned in this response; one is generated when none is given.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param webhook body dto.WebhookRequest true "Webhook to create"
+// @Success 201 {object} dto.CreatedWebhook "Created"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/webhooks [post]
+func (ctrl *WebhookController) CreateWebhook(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request dto.WebhookRequest
+	if err := bindJSON(c, &request); err != nil {
+		respondError(c, err, "Invalid webhook")
+		return
+	}
+
+	webhook, err := ctrl.webhooks(c).CreateWebhook(leagueID, &request)
+	if err != nil {
+		respondError(c, err, "Failed to create webhook")
+		return
+	}
+
+	respondCreated(c, fmt.Sprintf("/api/v2/leagues/%d/webhooks/%d", leagueID, webhook.ID), webhook, webhook)
+}
+
+// ListWebhooks retrieves the webhook subscriptions of a league
+// @Summary List the webhooks of a league
+// @Tags Webhooks
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/webhooks [get]
+func (ctrl *WebhookController) ListWebhooks(c *gin.Context) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := ctrl.webhooks(c).ListWebhooks(leagueID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook removes a webhook subscription
+// @Summary Delete a webhook of a league
+// @Tags Webhooks
+// @Param leagueID path int true "League ID"
+// @Param webhookID path int true "Webhook ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/webhooks/{webhookID} [delete]
+func (ctrl *WebhookController) DeleteWebhook(c *gin.Context) {
+	leagueID, webhookID, ok := webhookIDParams(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.webhooks(c).DeleteWebhook(leagueID, webhookID); err != nil {
+		respondError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	respondDeleted(c, gin.H{"message": "Webhook deleted"})
+}
+
+// ListWebhookDeliveries retrieves one page of the delivery log of a webhook, newest first
+// @Summary List the deliveries of a webhook
+// @Tags Webhooks
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param webhookID path int true "Webhook ID"
+// @Param sort query string false "Sort field (id, created_at), prefix with - for descending order"
+// @Param limit query int false "Page size, 50 by default and at most 500"
+// @Param offset query int false "Number of deliveries to skip"
+// @Success 200 {object} dto.PageResponse[models.WebhookDelivery]
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/webhooks/{webhookID}/deliveries [get]
+func (ctrl *WebhookController) ListWebhookDeliveries(c *gin.Context) {
+	leagueID, webhookID, ok := webhookIDParams(c)
+	if !ok {
+		return
+	}
+
+	params := newQueryParams(c)
+	page := params.page()
+	if err := params.err(); err != nil {
+		respondError(c, err, "Invalid delivery query")
+		return
+	}
+
+	deliveries, total, err := ctrl.webhooks(c).ListDeliveries(leagueID, webhookID, page)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve webhook deliveries")
+		return
+	}
+	respondList(c, deliveries, page, total)
+}
+
+// PingWebhook sends a test event to a webhook right away
+// @Summary Send a test event to a webhook
+// @Description Sends a webhook.ping event and returns the delivery with the outcome of the first attempt. A failed ping is retried like any other delivery.
+// @Tags Webhooks
+// @Produce json
+// @Param leagueID path int true "League ID"
+// @Param webhookID path int true "Webhook ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {object} controllers.Problem
+// @Failure 404 {object} controllers.Problem
+// @Failure 500 {object} controllers.Problem
+// @Router /v2/leagues/{leagueID}/webhooks/{webhookID}/ping [post]
+func (ctrl *WebhookController) PingWebhook(c *gin.Context) {
+	leagueID, webhookID, ok := webhookIDParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := ctrl.webhooks(c).PingWebhook(leagueID, webhookID)
+	if err != nil {
+		respondError(c, err, "Failed to ping webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// leagueIDParam parses the leagueID path parameter, responding with a problem if it is malformed
+func leagueIDParam(c *gin.Context) (uint, bool) {
+	leagueID, err := strconv.ParseUint(c.Param("leagueID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_league_id", "Invalid league ID")
+		return 0, false
+	}
+	return uint(leagueID), true
+}
+
+// webhookIDParams parses the leagueID and webhookID path parameters, responding with a problem if one is malformed
+func webhookIDParams(c *gin.Context) (uint, uint, bool) {
+	leagueID, ok := leagueIDParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+	webhookID, err := strconv.ParseUint(c.Param("webhookID"), 10, 64)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, "invalid_webhook_id", "Invalid webhook ID")
+		return 0, 0, false
+	}
+	return leagueID, uint(webhookID), true
+}
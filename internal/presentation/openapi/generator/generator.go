@@ -0,0 +1,535 @@
+// Package generator builds the OpenAPI 3 document of the API from the swag-style annotations
+// (@Summary, @Param, @Success, @Router, ...) on the handlers and from the Go types they reference.
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HandlerDirs are the directories, relative to the module root, whose handlers are documented
+var HandlerDirs = []string{
+	"internal/presentation/controllers",
+	"internal/presentation/openapi",
+}
+
+// TypesDir is the directory, relative to the module root, searched for the types annotations reference
+const TypesDir = "internal"
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Servers    []Server                         `json:"servers"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+	Security   []SecurityRequirement            `json:"security"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// SecurityRequirement lists the schemes that together authenticate a request
+type SecurityRequirement map[string][]string
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+
+	// Security overrides the document's requirements, an empty list makes the operation public
+	Security *[]SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]*Header   `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// problemType is answered with the problem+json media type instead of the one in @Produce
+const problemType = "controllers.Problem"
+
+var routePattern = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// Generate builds the OpenAPI document of the module rooted at root and returns it as indented JSON.
+//
+// Handlers shared between API versions are documented once. Their responses follow the rules of
+// the controllers' versioning helpers: a 201 or 204 success replaces the 200 success on the v2 routes
+// only, and the 200 array of a paginated list (one with an X-Total-Count header) is wrapped in a
+// dto.PageResponse on the v2 routes.
+func Generate(root string) ([]byte, error) {
+	types, err := loadTypes(filepath.Join(root, TypesDir))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "LeagueManager API",
+			Description: "Manage teams and leagues and simulate their matches week by week.",
+			Version:     "2.0",
+		},
+		Servers: []Server{{URL: "/api"}},
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{SecuritySchemes: map[string]*SecurityScheme{
+			"ApiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "Static API key"},
+			"BearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "HS256 token carrying the caller and its role"},
+		}},
+		Security: []SecurityRequirement{{"ApiKeyAuth": {}}, {"BearerAuth": {}}},
+	}
+
+	operationIDs := map[string]string{}
+	for _, dir := range HandlerDirs {
+		handlers, err := parseHandlers(filepath.Join(root, dir))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, handler := range handlers {
+			for _, route := range handler.routes {
+				op, err := handler.operation(types, route)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", handler.name, err)
+				}
+				if other, taken := operationIDs[op.OperationID]; taken {
+					return nil, fmt.Errorf("%s: operation ID %s is already used by %s", handler.name, op.OperationID, other)
+				}
+				operationIDs[op.OperationID] = route.path
+
+				if doc.Paths[route.path] == nil {
+					doc.Paths[route.path] = map[string]*Operation{}
+				}
+				if _, taken := doc.Paths[route.path][route.method]; taken {
+					return nil, fmt.Errorf("%s: route %s %s is documented twice", handler.name, strings.ToUpper(route.method), route.path)
+				}
+				doc.Paths[route.path][route.method] = op
+			}
+		}
+	}
+	doc.Components.Schemas = types.components
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+type route struct {
+	path   string
+	method string
+}
+
+// isV2 reports whether the route belongs to the v2 API
+func (r route) isV2() bool {
+	return strings.HasPrefix(r.path, "/v2/")
+}
+
+// handler holds the annotations of one handler function
+type handler struct {
+	name        string
+	summary     string
+	description string
+	tags        []string
+	accept      []string
+	produce     []string
+	params      [][]string
+	responses   [][]string
+	headers     [][]string
+	security    *[]SecurityRequirement
+	routes      []route
+}
+
+// parseHandlers collects the annotated functions of the non-test Go files in dir, in source order
+func parseHandlers(dir string) ([]*handler, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var handlers []*handler
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			h, err := parseAnnotations(fn.Name.Name, fn.Doc)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", fset.Position(fn.Pos()), fn.Name.Name, err)
+			}
+			if len(h.routes) > 0 {
+				handlers = append(handlers, h)
+			}
+		}
+	}
+	return handlers, nil
+}
+
+func parseAnnotations(name string, doc *ast.CommentGroup) (*handler, error) {
+	h := &handler{name: name}
+	for _, comment := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		keyword, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		switch keyword {
+		case "@Summary":
+			h.summary = value
+		case "@Description":
+			h.description = strings.TrimSpace(h.description + " " + value)
+		case "@Tags":
+			h.tags = splitList(value)
+		case "@Accept":
+			h.accept = splitList(value)
+		case "@Produce":
+			h.produce = splitList(value)
+		case "@Param":
+			h.params = append(h.params, fields(value))
+		case "@Success", "@Failure":
+			h.responses = append(h.responses, fields(value))
+		case "@Header":
+			h.headers = append(h.headers, fields(value))
+		case "@Security":
+			if h.security == nil {
+				h.security = &[]SecurityRequirement{}
+			}
+			if value != "none" {
+				*h.security = append(*h.security, SecurityRequirement{value: {}})
+			}
+		case "@Router":
+			match := routePattern.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("malformed @Router %q, want \"/path [method]\"", value)
+			}
+			if !strings.HasPrefix(match[1], "/") {
+				return nil, fmt.Errorf("@Router path %q must start with /", match[1])
+			}
+			h.routes = append(h.routes, route{path: match[1], method: strings.ToLower(match[2])})
+		default:
+			return nil, fmt.Errorf("unknown annotation %s", keyword)
+		}
+	}
+	return h, nil
+}
+
+// operation builds the operation documented by the handler for one of its routes
+func (h *handler) operation(types *schemas, r route) (*Operation, error) {
+	op := &Operation{
+		OperationID: h.name,
+		Summary:     h.summary,
+		Description: h.description,
+		Tags:        h.tags,
+		Responses:   map[string]*Response{},
+		Security:    h.security,
+	}
+	if r.isV2() && h.hasV1Route() {
+		op.OperationID += "V2"
+	}
+
+	pathParams := map[string]bool{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(r.path, -1) {
+		pathParams[match[1]] = false
+	}
+
+	for _, param := range h.params {
+		if len(param) < 4 {
+			return nil, fmt.Errorf("malformed @Param %q, want \"name in type required [description]\"", strings.Join(param, " "))
+		}
+		name, in, typeName := param[0], param[1], param[2]
+		required, err := strconv.ParseBool(param[3])
+		if err != nil {
+			return nil, fmt.Errorf("@Param %s: %w", name, err)
+		}
+		schema, err := types.resolve(typeName)
+		if err != nil {
+			return nil, fmt.Errorf("@Param %s: %w", name, err)
+		}
+		description := optional(param, 4)
+
+		switch in {
+		case "body":
+			op.RequestBody = &RequestBody{Description: description, Required: required, Content: content(h.accept, schema)}
+		case "path":
+			if _, ok := pathParams[name]; !ok {
+				return nil, fmt.Errorf("path parameter %s does not appear in %s", name, r.path)
+			}
+			pathParams[name] = true
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: in, Description: description, Required: true, Schema: schema})
+		case "query", "header":
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: in, Description: description, Required: required, Schema: schema})
+		default:
+			return nil, fmt.Errorf("@Param %s: unsupported location %q", name, in)
+		}
+	}
+	for name, documented := range pathParams {
+		if !documented {
+			return nil, fmt.Errorf("path parameter %s of %s has no @Param", name, r.path)
+		}
+	}
+
+	for _, response := range h.responses {
+		code, err := strconv.Atoi(response[0])
+		if err != nil {
+			return nil, fmt.Errorf("malformed response status %q", response[0])
+		}
+		if !h.servesStatus(r, code) {
+			continue
+		}
+
+		resp := &Response{Description: http.StatusText(code)}
+		rest := response[1:]
+		if len(rest) >= 2 && (rest[0] == "{object}" || rest[0] == "{array}") {
+			typeName := rest[1]
+			if rest[0] == "{array}" {
+				typeName = "[]" + typeName
+				if r.isV2() && code == http.StatusOK && h.isPaginated() {
+					typeName = "dto.PageResponse[" + rest[1] + "]"
+				}
+			}
+			schema, err := types.resolve(typeName)
+			if err != nil {
+				return nil, fmt.Errorf("response %d: %w", code, err)
+			}
+			produce := h.produce
+			if rest[1] == problemType {
+				produce = []string{"application/problem+json"}
+			}
+			resp.Content = content(produce, schema)
+			rest = rest[2:]
+		}
+		if len(rest) > 0 {
+			resp.Description = rest[0]
+		}
+
+		for _, header := range h.headers {
+			if len(header) < 3 || header[0] != response[0] {
+				continue
+			}
+			schema, err := types.resolve(strings.Trim(header[1], "{}"))
+			if err != nil {
+				return nil, fmt.Errorf("@Header %s: %w", header[2], err)
+			}
+			if resp.Headers == nil {
+				resp.Headers = map[string]*Header{}
+			}
+			resp.Headers[header[2]] = &Header{Description: optional(header, 3), Schema: schema}
+		}
+		op.Responses[response[0]] = resp
+	}
+	if len(op.Responses) == 0 {
+		return nil, fmt.Errorf("%s %s documents no response", strings.ToUpper(r.method), r.path)
+	}
+
+	// Every operation that is not public may be rejected by the authentication middleware
+	if h.security == nil || len(*h.security) > 0 {
+		problem, err := types.resolve(problemType)
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+			if _, documented := op.Responses[strconv.Itoa(code)]; !documented {
+				op.Responses[strconv.Itoa(code)] = &Response{
+					Description: http.StatusText(code),
+					Content:     content([]string{"application/problem+json"}, problem),
+				}
+			}
+		}
+	}
+	return op, nil
+}
+
+// servesStatus reports whether a documented success status applies to the route: v2 answers 201 and 204
+// where v1 answers 200
+func (h *handler) servesStatus(r route, code int) bool {
+	replaced := false
+	for _, response := range h.responses {
+		if response[0] == "201" || response[0] == "204" {
+			replaced = true
+		}
+	}
+
+	switch {
+	case code == http.StatusOK && replaced && r.isV2():
+		return false
+	case (code == http.StatusCreated || code == http.StatusNoContent) && !r.isV2():
+		// Handlers without a v1 response only document their v2 status
+		return !h.hasStatus(http.StatusOK)
+	default:
+		return true
+	}
+}
+
+func (h *handler) hasStatus(code int) bool {
+	for _, response := range h.responses {
+		if response[0] == strconv.Itoa(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *handler) hasV1Route() bool {
+	for _, r := range h.routes {
+		if !r.isV2() {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *handler) isPaginated() bool {
+	for _, header := range h.headers {
+		if len(header) >= 3 && header[2] == "X-Total-Count" {
+			return true
+		}
+	}
+	return false
+}
+
+// content maps the annotation media types (json, html, or full media types) to the schema
+func content(mediaTypes []string, schema *Schema) map[string]MediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"json"}
+	}
+	result := map[string]MediaType{}
+	for _, mediaType := range mediaTypes {
+		switch mediaType {
+		case "json":
+			mediaType = "application/json"
+		case "html":
+			mediaType = "text/html"
+		case "plain":
+			mediaType = "text/plain"
+		}
+		result[mediaType] = MediaType{Schema: schema}
+	}
+	return result
+}
+
+// fields splits an annotation value on spaces, keeping double-quoted strings together without their quotes
+func fields(value string) []string {
+	var result []string
+	for value = strings.TrimSpace(value); value != ""; value = strings.TrimSpace(value) {
+		if value[0] == '"' {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				result = append(result, value[1:])
+				break
+			}
+			result = append(result, value[1:end+1])
+			value = value[end+2:]
+			continue
+		}
+
+		end := strings.IndexAny(value, " \t")
+		if end < 0 {
+			result = append(result, value)
+			break
+		}
+		result = append(result, value[:end])
+		value = value[end:]
+	}
+	return result
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func optional(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+// WriteFile generates the document of the module rooted at root into path, leaving the file untouched on failure
+func WriteFile(root, path string) error {
+	out, err := Generate(root)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o644)
+}
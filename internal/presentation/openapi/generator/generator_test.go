@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func comments(lines ...string) *ast.CommentGroup {
+	group := &ast.CommentGroup{}
+	for _, line := range lines {
+		group.List = append(group.List, &ast.Comment{Text: "// " + line})
+	}
+	return group
+}
+
+func TestFields(t *testing.T) {
+	assert.Equal(t, []string{"leagueID", "path", "int", "true", "League ID"}, fields(`leagueID path int true "League ID"`))
+	assert.Equal(t, []string{"204", "Deleted"}, fields(`204 "Deleted"`))
+	assert.Equal(t, []string{"200", "{object}", "dto.PageResponse[models.Match]"}, fields(`200  {object}   dto.PageResponse[models.Match]`))
+}
+
+func TestParseAnnotations(t *testing.T) {
+	h, err := parseAnnotations("GetTeamByID", comments(
+		"GetTeamByID retrieves a team by its ID",
+		"@Summary Get a team by ID",
+		"@Router /teams/{teamID} [get]",
+		"@Router /v2/teams/{teamID} [GET]",
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, "Get a team by ID", h.summary)
+	assert.Equal(t, []route{{path: "/teams/{teamID}", method: "get"}, {path: "/v2/teams/{teamID}", method: "get"}}, h.routes)
+
+	_, err = parseAnnotations("CreateLeague", comments("@Router api/leagues/create [post]"))
+	assert.ErrorContains(t, err, "must start with /")
+
+	_, err = parseAnnotations("CreateLeague", comments("@Routes /leagues [post]"))
+	assert.ErrorContains(t, err, "unknown annotation")
+}
+
+func TestOperationChecksPathParameters(t *testing.T) {
+	types := &schemas{types: map[string]typeDecl{}, components: map[string]*Schema{}}
+
+	h, err := parseAnnotations("GetTeamByID", comments(
+		"@Param id path int true \"Team ID\"",
+		"@Success 200 {object} gin.H",
+		"@Router /teams/{teamID} [get]",
+	))
+	assert.NoError(t, err)
+	_, err = h.operation(types, h.routes[0])
+	assert.ErrorContains(t, err, "does not appear in /teams/{teamID}")
+
+	h.params = nil
+	_, err = h.operation(types, h.routes[0])
+	assert.ErrorContains(t, err, "has no @Param")
+
+	h.params = [][]string{{"teamID", "path", "int", "true"}}
+	h.responses = [][]string{{"200", "{object}", "models.Missing"}}
+	_, err = h.operation(types, h.routes[0])
+	assert.ErrorContains(t, err, `unknown type "models.Missing"`)
+}
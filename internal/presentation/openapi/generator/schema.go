@@ -0,0 +1,337 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is an OpenAPI schema object, restricted to the keywords the generator emits
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+}
+
+// typeDecl is a named type declared in one of the parsed packages
+type typeDecl struct {
+	pkg  string
+	spec *ast.TypeSpec
+}
+
+// schemas resolves Go types, named the way annotations name them ("models.Team"), to component schemas
+type schemas struct {
+	types      map[string]typeDecl
+	components map[string]*Schema
+}
+
+// loadTypes indexes every type declared in the non-test Go files below dir by "<package name>.<type name>"
+func loadTypes(dir string) (*schemas, error) {
+	s := &schemas{types: map[string]typeDecl{}, components: map[string]*Schema{}}
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				s.types[file.Name.Name+"."+typeSpec.Name.Name] = typeDecl{pkg: file.Name.Name, spec: typeSpec}
+			}
+		}
+		return nil
+	})
+	return s, err
+}
+
+// resolve returns the schema of a type as written in an annotation: a primitive, "object", "gin.H",
+// "[]T", "pkg.Type" or an instantiated generic "pkg.Type[pkg.Arg]". Named types become component references.
+func (s *schemas) resolve(name string) (*Schema, error) {
+	switch {
+	case strings.HasPrefix(name, "[]"):
+		items, err := s.resolve(strings.TrimPrefix(name, "[]"))
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case name == "object" || name == "gin.H":
+		return &Schema{Type: "object", AdditionalProperties: true}, nil
+	}
+
+	if schema := primitive(name); schema != nil {
+		return schema, nil
+	}
+
+	base, args := name, []string(nil)
+	if open := strings.Index(name, "["); open > 0 && strings.HasSuffix(name, "]") {
+		base = name[:open]
+		args = strings.Split(name[open+1:len(name)-1], ",")
+	}
+
+	decl, ok := s.types[base]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", base)
+	}
+
+	component := base
+	if len(args) > 0 {
+		component += "-" + strings.Join(args, "-")
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + component}
+	if _, done := s.components[component]; done {
+		return ref, nil
+	}
+
+	typeArgs := map[string]string{}
+	if params := decl.spec.TypeParams; params != nil {
+		var names []string
+		for _, field := range params.List {
+			for _, ident := range field.Names {
+				names = append(names, ident.Name)
+			}
+		}
+		if len(names) != len(args) {
+			return nil, fmt.Errorf("type %s takes %d type arguments, got %d", base, len(names), len(args))
+		}
+		for i, param := range names {
+			typeArgs[param] = strings.TrimSpace(args[i])
+		}
+	}
+
+	// Reserve the name first so self-referencing types terminate
+	s.components[component] = &Schema{}
+	schema, err := s.typeSchema(decl.spec.Type, decl.pkg, typeArgs)
+	if err != nil {
+		return nil, fmt.Errorf("type %s: %w", base, err)
+	}
+	if schema.Description == "" {
+		schema.Description = docText(decl.spec.Doc)
+	}
+	s.components[component] = schema
+	return ref, nil
+}
+
+// typeSchema converts a type expression found in package pkg, with type parameters bound to typeArgs
+func (s *schemas) typeSchema(expr ast.Expr, pkg string, typeArgs map[string]string) (*Schema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if arg, ok := typeArgs[t.Name]; ok {
+			return s.resolve(arg)
+		}
+		if schema := primitive(t.Name); schema != nil {
+			return schema, nil
+		}
+		return s.resolve(pkg + "." + t.Name)
+	case *ast.StarExpr:
+		return s.typeSchema(t.X, pkg, typeArgs)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := s.typeSchema(t.Elt, pkg, typeArgs)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := s.typeSchema(t.Value, pkg, typeArgs)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.InterfaceType:
+		return &Schema{}, nil
+	case *ast.SelectorExpr:
+		name := t.X.(*ast.Ident).Name + "." + t.Sel.Name
+		switch name {
+		case "time.Time":
+			return &Schema{Type: "string", Format: "date-time"}, nil
+		case "gorm.DeletedAt":
+			return &Schema{Type: "string", Format: "date-time", Nullable: true}, nil
+		case "json.RawMessage":
+			// Embedded JSON of any shape
+			return &Schema{Nullable: true}, nil
+		}
+		return s.resolve(name)
+	case *ast.StructType:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		if err := s.addFields(schema, t, pkg, typeArgs); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("unsupported type expression %T", expr)
+	}
+}
+
+// addFields adds the JSON properties of a struct to schema, inlining embedded structs the way encoding/json does
+func (s *schemas) addFields(schema *Schema, structType *ast.StructType, pkg string, typeArgs map[string]string) error {
+	for _, field := range structType.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			unquoted, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(unquoted)
+		}
+
+		if len(field.Names) == 0 {
+			if err := s.addEmbedded(schema, field.Type, pkg, typeArgs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			jsonName, options, _ := strings.Cut(tag.Get("json"), ",")
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName == "" {
+				jsonName = ident.Name
+			}
+
+			property, err := s.typeSchema(field.Type, pkg, typeArgs)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", ident.Name, err)
+			}
+			description := docText(field.Doc)
+			if description == "" {
+				description = docText(field.Comment)
+			}
+			// OpenAPI 3.0 ignores the siblings of $ref, so only inline schemas carry the field description
+			if property.Ref == "" {
+				property.Description = description
+			}
+
+			if applyBinding(property, tag.Get("binding")) && !strings.Contains(options, "omitempty") {
+				schema.Required = append(schema.Required, jsonName)
+			}
+			schema.Properties[jsonName] = property
+		}
+	}
+	sort.Strings(schema.Required)
+	return nil
+}
+
+func (s *schemas) addEmbedded(schema *Schema, expr ast.Expr, pkg string, typeArgs map[string]string) error {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.SelectorExpr:
+		if t.X.(*ast.Ident).Name+"."+t.Sel.Name == "gorm.Model" {
+			// gorm.Model carries no JSON tags, so its fields keep their Go names
+			schema.Properties["ID"] = &Schema{Type: "integer"}
+			schema.Properties["CreatedAt"] = &Schema{Type: "string", Format: "date-time"}
+			schema.Properties["UpdatedAt"] = &Schema{Type: "string", Format: "date-time"}
+			schema.Properties["DeletedAt"] = &Schema{Type: "string", Format: "date-time", Nullable: true}
+			return nil
+		}
+		return s.inline(schema, t.X.(*ast.Ident).Name, t.Sel.Name, typeArgs)
+	case *ast.Ident:
+		return s.inline(schema, pkg, t.Name, typeArgs)
+	default:
+		return fmt.Errorf("unsupported embedded field %T", expr)
+	}
+}
+
+func (s *schemas) inline(schema *Schema, pkg, name string, typeArgs map[string]string) error {
+	decl, ok := s.types[pkg+"."+name]
+	if !ok {
+		return fmt.Errorf("unknown embedded type %s.%s", pkg, name)
+	}
+	structType, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		return fmt.Errorf("embedded type %s.%s is not a struct", pkg, name)
+	}
+	return s.addFields(schema, structType, decl.pkg, typeArgs)
+}
+
+// applyBinding copies the validation rules of a binding tag onto the schema and reports whether the field is required
+func applyBinding(schema *Schema, binding string) bool {
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "notblank":
+			one := 1
+			schema.MinLength = &one
+		case "min", "max":
+			value, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case schema.Type == "string" && name == "min":
+				length := int(value)
+				schema.MinLength = &length
+			case schema.Type == "string":
+				length := int(value)
+				schema.MaxLength = &length
+			case name == "min":
+				schema.Minimum = &value
+			default:
+				schema.Maximum = &value
+			}
+		}
+	}
+	return required
+}
+
+// primitive returns the schema of a predeclared Go type or annotation type name, or nil
+func primitive(name string) *Schema {
+	switch name {
+	case "string":
+		return &Schema{Type: "string"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "integer":
+		return &Schema{Type: "integer"}
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32", "float64", "number":
+		return &Schema{Type: "number"}
+	case "bool", "boolean":
+		return &Schema{Type: "boolean"}
+	case "any":
+		return &Schema{}
+	}
+	return nil
+}
+
+func docText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(group.Text(), "\n", " "))
+}
@@ -0,0 +1,44 @@
+// Package openapi serves the OpenAPI document of the API and a page to explore it.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate go run ../../../cmd/openapigen -root ../../.. -out openapi.json
+
+//go:embed openapi.json
+var document []byte
+
+//go:embed explorer.html
+var explorer []byte
+
+// Document returns the embedded OpenAPI document
+func Document() []byte {
+	return document
+}
+
+// Spec serves the OpenAPI document
+// @Summary Get the OpenAPI document of the API
+// @Tags Documentation
+// @Produce json
+// @Success 200 {object} object "OpenAPI 3 document"
+// @Security none
+// @Router /openapi.json [get]
+func Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+// Explorer serves a page that lists the operations of the API and sends requests to them
+// @Summary Explore the API in a browser
+// @Tags Documentation
+// @Produce html
+// @Success 200 "Explorer page"
+// @Security none
+// @Router /docs [get]
+func Explorer(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", explorer)
+}
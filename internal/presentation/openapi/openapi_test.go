@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"LeagueManager/internal/presentation/openapi/generator"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentIsUpToDate(t *testing.T) {
+	generated, err := generator.Generate("../../..")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(Document()), "the OpenAPI document is stale, run go generate ./internal/presentation/openapi")
+}
+
+func TestServeDocumentAndExplorer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/openapi.json", Spec)
+	router.GET("/api/docs", Explorer)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/openapi.json", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "3.0.3", document["openapi"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/docs", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `fetch("openapi.json")`)
+}
@@ -7,6 +7,7 @@
 package internal
 
 import (
+	"LeagueManager/internal/application/events"
 	"LeagueManager/internal/application/services"
 	"LeagueManager/internal/domain/repositories"
 	"LeagueManager/internal/infrastructure/config"
@@ -16,7 +17,11 @@ import (
 // Injectors from injector.go:
 
 func Init() (*config.Initialization, error) {
-	db, err := config.ConnectToDB()
+	databaseSettings, err := config.NewDatabaseSettings()
+	if err != nil {
+		return nil, err
+	}
+	db, err := config.ConnectToDB(databaseSettings)
 	if err != nil {
 		return nil, err
 	}
@@ -24,10 +29,82 @@ func Init() (*config.Initialization, error) {
 	leagueRepository := repositories.NewLeagueRepository(db)
 	standingRepository := repositories.NewStandingRepository(db)
 	matchRepository := repositories.NewMatchRepository(db)
-	teamService := services.NewTeamService(teamRepository, leagueRepository)
+	unitOfWork := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepository, leagueRepository, unitOfWork)
 	teamController := controllers.NewTeamController(teamService)
-	leagueService := services.NewLeagueService(leagueRepository, teamRepository, matchRepository, standingRepository)
+	bus := events.NewBus()
+	leagueService := services.NewLeagueService(leagueRepository, teamRepository, matchRepository, standingRepository, unitOfWork, bus)
 	leagueController := controllers.NewLeagueController(leagueService, teamService)
-	initialization := config.NewInitialization(teamRepository, leagueRepository, standingRepository, matchRepository, teamService, teamController, leagueService, leagueController)
+	organizationRepository := repositories.NewOrganizationRepository(db)
+	organizationService := services.NewOrganizationService(organizationRepository)
+	organizationController := controllers.NewOrganizationController(organizationService)
+	auditRepository := repositories.NewAuditRepository(db)
+	auditService := services.NewAuditService(auditRepository)
+	auditController := controllers.NewAuditController(auditService)
+	webhookRepository := repositories.NewWebhookRepository(db)
+	webhookSettings, err := config.NewWebhookSettings()
+	if err != nil {
+		return nil, err
+	}
+	webhookDispatcher := services.NewWebhookDispatcher(webhookRepository, bus, webhookSettings)
+	webhookService := services.NewWebhookService(webhookRepository, leagueRepository, webhookDispatcher)
+	webhookController := controllers.NewWebhookController(webhookService)
+	broker := events.NewBroker(bus)
+	streamController := controllers.NewStreamController(broker, leagueService)
+	liveMatchService := services.NewLiveMatchService(leagueService, bus)
+	liveController := controllers.NewLiveController(liveMatchService)
+	scheduleRepository := repositories.NewScheduleRepository(db)
+	schedulerSettings, err := config.NewSchedulerSettings()
+	if err != nil {
+		return nil, err
+	}
+	leagueScheduler := services.NewLeagueScheduler(scheduleRepository, leagueService, schedulerSettings)
+	scheduleService := services.NewScheduleService(scheduleRepository, leagueRepository, leagueScheduler)
+	scheduleController := controllers.NewScheduleController(scheduleService)
+	calendarController := controllers.NewCalendarController(leagueService)
+	venueRepository := repositories.NewVenueRepository(db)
+	venueService := services.NewVenueService(venueRepository, unitOfWork)
+	venueController := controllers.NewVenueController(venueService, leagueService)
+	fixtureController := controllers.NewFixtureController(leagueService)
+	importService := services.NewImportService(unitOfWork, bus)
+	importController := controllers.NewImportController(importService)
+	exportController := controllers.NewExportController(leagueService)
+	archiveService := services.NewArchiveService(unitOfWork)
+	archiveController := controllers.NewArchiveController(archiveService)
+	authenticator, err := config.NewAuthenticator(organizationService)
+	if err != nil {
+		return nil, err
+	}
+	initialization := config.NewInitialization(teamRepository, leagueRepository, standingRepository, matchRepository, teamService, teamController, leagueService, leagueController, organizationService, organizationController, auditService, auditController, bus, webhookService, webhookController, webhookDispatcher, streamController, liveMatchService, liveController, scheduleService, scheduleController, leagueScheduler, calendarController, venueService, venueController, fixtureController, importService, importController, exportController, archiveService, archiveController, authenticator)
 	return initialization, nil
 }
+
+// InitCLI builds the services of the command-line tools, which serve no requests and need no credentials
+func InitCLI() (*config.CLIInitialization, error) {
+	databaseSettings, err := config.NewDatabaseSettings()
+	if err != nil {
+		return nil, err
+	}
+	db, err := config.ConnectToDB(databaseSettings)
+	if err != nil {
+		return nil, err
+	}
+	teamRepository := repositories.NewTeamRepository(db)
+	leagueRepository := repositories.NewLeagueRepository(db)
+	unitOfWork := repositories.NewUnitOfWork(db)
+	teamService := services.NewTeamService(teamRepository, leagueRepository, unitOfWork)
+	matchRepository := repositories.NewMatchRepository(db)
+	standingRepository := repositories.NewStandingRepository(db)
+	bus := events.NewBus()
+	leagueService := services.NewLeagueService(leagueRepository, teamRepository, matchRepository, standingRepository, unitOfWork, bus)
+	organizationRepository := repositories.NewOrganizationRepository(db)
+	organizationService := services.NewOrganizationService(organizationRepository)
+	webhookRepository := repositories.NewWebhookRepository(db)
+	webhookSettings, err := config.NewWebhookSettings()
+	if err != nil {
+		return nil, err
+	}
+	webhookDispatcher := services.NewWebhookDispatcher(webhookRepository, bus, webhookSettings)
+	cliInitialization := config.NewCLIInitialization(teamService, leagueService, organizationService, webhookDispatcher)
+	return cliInitialization, nil
+}